@@ -1,14 +1,23 @@
 package config
 
 import (
+	"bufio"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"os"
+	"strings"
 	"time"
 
+	"github.com/armory/armory-cli/cmd/utils"
+	"github.com/armory/armory-cli/cmd/validate"
 	"github.com/armory/armory-cli/pkg/cmdUtils"
 	cliconfig "github.com/armory/armory-cli/pkg/config"
 	"github.com/armory/armory-cli/pkg/configuration"
 	errorUtils "github.com/armory/armory-cli/pkg/errors"
+	"github.com/armory/armory-cli/pkg/findings"
 	"github.com/armory/armory-cli/pkg/model"
 	"github.com/armory/armory-cli/pkg/model/configClient"
 	"github.com/samber/lo"
@@ -20,12 +29,24 @@ import (
 const (
 	configApplyShort = "Sync an RBAC configuration file"
 	configApplyLong  = "Sync an RBAC configuration file\n\n" +
+		"The file is validated first, see armory validate --kind rbac. " +
+		"Roles whose grants are the same as in the file are left as they are. With --dry-run, the tenants and roles " +
+		"that would be created (+), updated (~) or deleted (-) are printed and nothing is changed. With --confirm, " +
+		"they are printed and applied once you answer yes\n\n" +
+		"When the file lists tenants and allowAutoDelete is true, the existing tenants that it does not list are deleted, " +
+		"along with their roles. Files that list only some of the tenants must list all of them, or set allowAutoDelete " +
+		"to false, to keep the others. A role of a tenant that is not listed is reported as an error\n\n" +
+		"If a change fails, the changes already made are rolled back, so that RBAC is not left half-applied. With " +
+		"--report, the changes that were made and rolled back are written to a JSON file\n\n" +
 		"For usage documentation, visit https://docs.armory.io/cd-as-a-service/concepts/iam/rbac"
 	configApplyExample = "armory config apply [options]"
 )
 
 type configApplyOptions struct {
 	configFile string
+	dryRun     bool
+	confirm    bool
+	reportFile string
 }
 
 func NewConfigApplyCmd(configuration *cliconfig.Configuration) *cobra.Command {
@@ -44,6 +65,10 @@ func NewConfigApplyCmd(configuration *cliconfig.Configuration) *cobra.Command {
 		},
 	}
 	cmd.Flags().StringVarP(&options.configFile, "file", "f", "", "path to the configuration file")
+	cmd.Flags().BoolVarP(&options.dryRun, "dry-run", "", false, "print the tenants and roles that would be created, updated or deleted without changing them")
+	cmd.Flags().BoolVarP(&options.confirm, "confirm", "", false, "print the tenants and roles that would be created, updated or deleted and ask for confirmation before changing them")
+	cmd.Flags().StringVarP(&options.reportFile, "report", "", "", "path of the JSON file the changes are reported in")
+	cmd.MarkFlagsMutuallyExclusive("dry-run", "confirm")
 	err := cmd.MarkFlagRequired("file")
 	if err != nil {
 		return nil
@@ -52,6 +77,9 @@ func NewConfigApplyCmd(configuration *cliconfig.Configuration) *cobra.Command {
 }
 
 func apply(cmd *cobra.Command, options *configApplyOptions, cli *cliconfig.Configuration) error {
+	if lo.FromPtr(cli.GetIsTest()) {
+		utils.ConfigureLoggingForTesting(cmd)
+	}
 	payload := model.ConfigurationConfig{}
 	//in case this is running on a GitHub instance
 	gitWorkspace, present := os.LookupEnv("GITHUB_WORKSPACE")
@@ -65,47 +93,166 @@ func apply(cmd *cobra.Command, options *configApplyOptions, cli *cliconfig.Confi
 		return errorUtils.NewWrappedError(ErrReadingYamlFile, err)
 	}
 	cmd.SilenceUsage = true
+	if err := validateConfiguration(cmd, file); err != nil {
+		return err
+	}
 	// unmarshal data into struct
 	if err := yaml.Unmarshal(file, &payload); err != nil {
 		return errorUtils.NewWrappedError(ErrInvalidConfigurationObject, err)
 	}
 	cc := configuration.NewClient(cli)
-	if payload.Environments != nil {
-		if err = processEnvironments(cc, payload.Environments); err != nil {
+	plan, snapshot, err := planConfiguration(cc, payload)
+	if err != nil {
+		return err
+	}
+	if options.dryRun || options.confirm {
+		dataFormat, err := cli.GetOutputFormatter()(FormattableConfigPlan{Plan: plan})
+		if err != nil {
+			return err
+		}
+		log.S().Info(dataFormat)
+	}
+	if options.dryRun || (options.confirm && !plan.hasChanges()) {
+		return nil
+	}
+	if options.confirm {
+		if err := confirmPlan(cmd); err != nil {
 			return err
 		}
 	}
-	return processRoles(cc, payload.Roles, payload.AllowAutoDelete)
+	tx := newConfigTransaction(cc, snapshot)
+	err = applyPlan(tx, plan)
+	var rollbackErrs []error
+	if err != nil {
+		log.S().Info("Could not apply the configuration, rolling back the changes that were made.")
+		rollbackErrs = tx.rollback()
+	}
+	if options.reportFile != "" {
+		if reportErr := writeChangeReport(options.reportFile, tx.report(err, rollbackErrs)); reportErr != nil && err == nil {
+			return reportErr
+		}
+	}
+	switch {
+	case len(rollbackErrs) > 0:
+		return errorUtils.NewWrappedError(ErrRollbackFailed, errors.Join(append([]error{err}, rollbackErrs...)...))
+	case err != nil:
+		return errorUtils.NewWrappedError(ErrApplyRolledBack, err)
+	}
+	return nil
 }
 
-func processEnvironments(configClient *configuration.ConfigClient, environments []string) error {
-	// get existing environments
+// validateConfiguration checks the file against the RBAC schema and the grant catalog, so that typos are reported
+// before anything is changed. Grants outside the catalog are only warned about, on stderr so that the plan output can
+// still be parsed.
+func validateConfiguration(cmd *cobra.Command, file []byte) error {
+	found, err := validate.ValidateRBACFindings(file)
+	if err != nil {
+		return errorUtils.NewWrappedError(ErrInvalidConfigurationObject, err)
+	}
+	if !findings.HasErrors(found) {
+		if len(found) > 0 {
+			_, _ = fmt.Fprintln(cmd.ErrOrStderr(), validate.FormattableValidationResult{Findings: found}.String()+"\n")
+		}
+		return nil
+	}
+	failures := lo.Map(found, func(f findings.Finding, _ int) string { return f.String() })
+	if err := validate.LogValidationErrors(cmd.OutOrStdout(), failures, false); err != nil {
+		return err
+	}
+	return validate.ErrInvalidRBACFile
+}
+
+// applyPlan makes the changes of the plan. Tenants are renamed and created first so that roles can use them, and
+// deleted last, once the roles of the file no longer use them.
+func applyPlan(tx *configTransaction, plan *configPlan) error {
+	if err := processEnvironments(tx, plan); err != nil {
+		return err
+	}
+	if err := processRoles(tx, plan); err != nil {
+		return err
+	}
+	return processDeletedEnvironments(tx, plan)
+}
+
+// planConfiguration compares the configuration file with the existing tenants and roles, which it returns as a
+// snapshot apply can roll back to.
+func planConfiguration(configClient *configuration.ConfigClient, payload model.ConfigurationConfig) (*configPlan, configSnapshot, error) {
 	ctx, cancel := context.WithTimeout(configClient.ArmoryCloudClient.Context, time.Minute)
 	defer cancel()
 	// execute request
 	existingEnvironments, err := configClient.GetEnvironments(ctx)
 	if err != nil {
-		return errorUtils.NewWrappedError(ErrGettingEnvironments, err)
+		return nil, configSnapshot{}, errorUtils.NewWrappedError(ErrGettingEnvironments, err)
+	}
+	existingRoles, _, err := configClient.GetRoles(ctx)
+	if err != nil {
+		return nil, configSnapshot{}, errorUtils.NewWrappedError(ErrGettingRoles, err)
+	}
+	snapshot := configSnapshot{Environments: existingEnvironments, Roles: existingRoles}
+	return newConfigPlan(payload, existingEnvironments, existingRoles), snapshot, nil
+}
+
+func writeChangeReport(path string, report changeReport) error {
+	content, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return errorUtils.NewWrappedError(ErrWritingChangeReport, err)
 	}
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return errorUtils.NewWrappedError(ErrWritingChangeReport, err)
+	}
+	return nil
+}
 
-	// check to see if environments in config file exists already, if not perform a POST to create
-	for _, environment := range environments {
-		if !configEnvironmentMatchesAPIEnvironments(environment, existingEnvironments) {
-			// create new environment
-			ctx, cancel := context.WithTimeout(configClient.ArmoryCloudClient.Context, time.Minute)
-			defer cancel()
+// confirmPlan asks whether to apply the plan, which is only applied when the answer is yes.
+func confirmPlan(cmd *cobra.Command) error {
+	if _, err := fmt.Fprint(cmd.OutOrStdout(), "\nDo you want to apply these changes? Only 'yes' will be accepted: "); err != nil {
+		return err
+	}
+	answer, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	if strings.TrimSpace(answer) != "yes" {
+		return ErrApplyCancelled
+	}
+	return nil
+}
 
-			_, _, err = configClient.CreateEnvironment(ctx, configuration.CreateEnvironmentRequest(environment))
-			if err != nil {
-				return errorUtils.NewWrappedError(ErrCreatingEnvironment, err)
-			}
-			log.S().Infof("Created tenant: %s", environment)
+func processEnvironments(tx *configTransaction, plan *configPlan) error {
+	for _, rename := range plan.TenantsToRename {
+		if err := tx.renameTenant(rename); err != nil {
+			return err
 		}
+		log.S().Infof("Renamed tenant: %s to %s", rename.PreviousName, rename.Name)
+	}
+	for _, environment := range plan.TenantsToCreate {
+		if err := tx.createTenant(environment); err != nil {
+			return err
+		}
+		log.S().Infof("Created tenant: %s", environment)
 	}
 
 	return nil
 }
 
+func processDeletedEnvironments(tx *configTransaction, plan *configPlan) error {
+	if len(plan.TenantsToDelete) > 0 && !plan.AllowAutoDelete {
+		log.S().Info("Detected the following tenants that should be deleted. Doing so may be destructive.")
+		log.S().Info("You can enable deletes by setting 'allowAutoDelete' to 'true' in the configuration file.")
+	}
+	for _, environment := range plan.TenantsToDelete {
+		if !plan.AllowAutoDelete {
+			log.S().Info(environment.Name)
+			continue
+		}
+		if err := tx.deleteTenant(environment); err != nil {
+			return err
+		}
+		log.S().Infof("Deleted tenant: %s", environment.Name)
+	}
+	return nil
+}
+
 func configEnvironmentMatchesAPIEnvironments(environment string, existingEnvironments []configClient.Environment) bool {
 	_, exists := lo.Find(existingEnvironments, func(ee configClient.Environment) bool {
 		return environment == ee.Name
@@ -131,83 +278,38 @@ func findDeletedRoles(rolesInConfigFile, apiRoles []model.RoleConfig, environmen
 	return deletedRoles
 }
 
-func processRoles(configClient *configuration.ConfigClient, rolesFromConfig []model.RoleConfig, allowAutoDelete bool) error {
-	//get existing roles
-	ctx, cancel := context.WithTimeout(configClient.ArmoryCloudClient.Context, time.Minute)
-	defer cancel()
-	// execute request
-	existingRoles, _, err := configClient.GetRoles(ctx)
-	if err != nil {
-		return errorUtils.NewWrappedError(ErrGettingRoles, err)
+func processRoles(tx *configTransaction, plan *configPlan) error {
+	for _, role := range plan.SystemRoles {
+		log.S().Infof("Role %s is a system role. You cannot update it via the CLI.", role)
 	}
-
-	environments, err := configClient.GetEnvironments(ctx)
-	if err != nil {
-		return errorUtils.NewWrappedError(ErrGettingEnvironments, err)
-	}
-
-	//check to see if role in config file exists already, if so perform a PUT, if not perform a POST to create
-	for _, roleInConfig := range rolesFromConfig {
-		exists := false
-		for _, roleInExisting := range existingRoles {
-			if configRoleMatchesAPIRole(roleInConfig, roleInExisting, environments) {
-				exists = true
-				if !roleInExisting.SystemDefined {
-					//update existing role
-					ctx, cancel := context.WithTimeout(configClient.ArmoryCloudClient.Context, time.Minute)
-					defer cancel()
-					req, err := configuration.UpdateRolesRequest(roleInExisting.ID, roleInConfig.Tenant, roleInConfig.Grants)
-					if err != nil {
-						return errorUtils.NewWrappedError(ErrUpdateRole, err)
-					}
-					_, _, err = configClient.UpdateRole(ctx, req)
-					if err != nil {
-						return errorUtils.NewWrappedError(ErrUpdateRole, err)
-					}
-					log.S().Infof("Updated role: %s", roleInConfig.Name)
-				} else {
-					log.S().Infof("Role %s is a system role. You cannot update it via the CLI.", roleInConfig.Name)
-				}
-				break
-			}
+	for _, update := range plan.RolesToUpdate {
+		//update existing role
+		if err := tx.updateRole(update.Role); err != nil {
+			return err
 		}
-		if !exists {
-			//create new role
-			ctx, cancel := context.WithTimeout(configClient.ArmoryCloudClient.Context, time.Minute)
-			defer cancel()
-			req, err := configuration.CreateRoleRequest(&roleInConfig)
-			if err != nil {
-				return errorUtils.NewWrappedError(ErrCreatingRole, err)
-			}
-			_, _, err = configClient.CreateRole(ctx, req)
-			if err != nil {
-				return errorUtils.NewWrappedError(ErrCreatingRole, err)
-			}
-			log.S().Infof("Created role: %s", roleInConfig.Name)
+		log.S().Infof("Updated role: %s", update.Role.Name)
+	}
+	for _, role := range plan.RolesToCreate {
+		//create new role
+		if err := tx.createRole(role); err != nil {
+			return err
 		}
+		log.S().Infof("Created role: %s", role.Name)
 	}
 	//Check to see if any existing roles are no longer in the config file, if so delete them
-	deletedRoles := findDeletedRoles(rolesFromConfig, existingRoles, environments)
-	if len(deletedRoles) > 0 && !allowAutoDelete {
+	if len(plan.RolesToDelete) > 0 && !plan.AllowAutoDelete {
 		log.S().Info("Detected the following roles that should be deleted. Doing so may be destructive.")
 		log.S().Info("You can enable deletes by setting 'allowAutoDelete' to 'true' in the configuration file.")
 	}
-	for _, deletedRole := range deletedRoles {
-		if !allowAutoDelete {
+	for _, deletedRole := range plan.RolesToDelete {
+		if !plan.AllowAutoDelete {
 			log.S().Info(deletedRole.Name)
-		} else {
-			ctx, cancel := context.WithTimeout(configClient.ArmoryCloudClient.Context, time.Minute)
-			defer cancel()
-			req, err := configuration.DeleteRolesRequest(deletedRole.ID)
-			if err != nil {
-				return errorUtils.NewWrappedError(ErrDeletingRole, err)
-			}
-			_, err = configClient.DeleteRole(ctx, req)
-			if err != nil {
-				return errorUtils.NewWrappedError(ErrDeletingRole, err)
-			}
-			log.S().Infof("Deleted role: %s", deletedRole.Name)
+			continue
+		}
+		if err := tx.removeRole(deletedRole); err != nil {
+			return err
 		}
+		log.S().Infof("Deleted role: %s", deletedRole.Name)
 	}
 	return nil
 }
@@ -2,7 +2,10 @@ package config
 
 import (
 	"bytes"
+	"encoding/json"
+	"github.com/armory/armory-cli/cmd/validate"
 	cliconfig "github.com/armory/armory-cli/pkg/config"
+	"github.com/armory/armory-cli/pkg/configuration"
 	"github.com/armory/armory-cli/pkg/model"
 	"github.com/armory/armory-cli/pkg/model/configClient"
 	"github.com/armory/armory-cli/pkg/util"
@@ -13,6 +16,8 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"path"
+	"strings"
 	"testing"
 )
 
@@ -66,11 +71,68 @@ func (suite *ConfigApplyTestSuite) TestConfigApplyCreateTenant() {
 		suite.T().Fatal(err)
 	}
 	callCount := httpmock.GetCallCountInfo()
-	suite.Equal(2, callCount["GET /environments"])
+	suite.Equal(1, callCount["GET /environments"])
 	suite.Equal(1, callCount["POST /environments"])
 	suite.Equal(1, callCount["GET /roles"])
 }
 
+func (suite *ConfigApplyTestSuite) TestConfigApplyRenameAndDeleteTenants() {
+	getEnvironmentsExpected := []configClient.Environment{
+		{Name: "staging", ID: "env-id-1"},
+		{Name: "legacy", ID: "env-id-2"},
+	}
+	getExpected := []model.RoleConfig{{
+		ID:     "role-id-1",
+		Name:   "deployer",
+		EnvID:  "env-id-1",
+		Grants: []model.GrantConfig{{Type: "api", Resource: "deployment", Permission: "full"}},
+	}}
+
+	assert.NoError(suite.T(), registerResponder(getEnvironmentsExpected, http.StatusOK, "/environments", http.MethodGet))
+	assert.NoError(suite.T(), registerResponder(getExpected, http.StatusOK, "/roles", http.MethodGet))
+	assert.NoError(suite.T(), registerResponder(nil, http.StatusNoContent, "/environments/env-id-1", http.MethodPut))
+	assert.NoError(suite.T(), registerResponder(nil, http.StatusNoContent, "/environments/env-id-2", http.MethodDelete))
+
+	tempFile := util.TempAppFile("", "app", testConfigYamlStrForRenameTenants)
+	if tempFile == nil {
+		suite.T().Fatal("TestConfigApplyRenameAndDeleteTenants failed with: Could not create temp app file.")
+	}
+	suite.T().Cleanup(func() { os.Remove(tempFile.Name()) })
+	outWriter := bytes.NewBufferString("")
+	cmd := getConfigApplyCmdWithTmpFile(outWriter, tempFile, "text")
+	suite.NoError(cmd.Execute())
+	suite.Equal("Renamed tenant: staging to pre-production\nDeleted tenant: legacy\n", outWriter.String())
+	callCount := httpmock.GetCallCountInfo()
+	suite.Equal(1, callCount["PUT /environments/env-id-1"])
+	suite.Equal(1, callCount["DELETE /environments/env-id-2"])
+	suite.Equal(0, callCount["POST /environments"])
+	// the role is matched with the renamed tenant, so it is neither created nor deleted
+	suite.Equal(0, callCount["POST /roles"])
+	suite.Equal(0, callCount["DELETE /roles/role-id-1"])
+}
+
+func (suite *ConfigApplyTestSuite) TestConfigApplyKeepsTenantsWithoutAllowAutoDelete() {
+	getEnvironmentsExpected := []configClient.Environment{
+		{Name: "testTenant", ID: "env-id-1"},
+		{Name: "legacy", ID: "env-id-2"},
+	}
+	assert.NoError(suite.T(), registerResponder(getEnvironmentsExpected, http.StatusOK, "/environments", http.MethodGet))
+	assert.NoError(suite.T(), registerResponder([]model.RoleConfig{}, http.StatusOK, "/roles", http.MethodGet))
+
+	tempFile := util.TempAppFile("", "app", "tenants:\n  - testTenant\n")
+	if tempFile == nil {
+		suite.T().Fatal("TestConfigApplyKeepsTenantsWithoutAllowAutoDelete failed with: Could not create temp app file.")
+	}
+	suite.T().Cleanup(func() { os.Remove(tempFile.Name()) })
+	outWriter := bytes.NewBufferString("")
+	cmd := getConfigApplyCmdWithTmpFile(outWriter, tempFile, "text", "--dry-run")
+	suite.NoError(cmd.Execute())
+	suite.Equal("  # tenant legacy is not in the file, it is kept because allowAutoDelete is not set\n\n"+
+		"Plan: 0 tenant(s) to create, 0 to rename, 0 to delete; 0 role(s) to create, 0 to update, 0 to delete, 0 unchanged.\n", outWriter.String())
+	callCount := httpmock.GetCallCountInfo()
+	suite.Equal(0, callCount["DELETE /environments/env-id-2"])
+}
+
 func (suite *ConfigApplyTestSuite) TestConfigApplyCreateRole() {
 	getExpected := []model.RoleConfig{}
 	getEnvironmentsExpected := []configClient.Environment{{
@@ -440,6 +502,316 @@ func (suite *ConfigApplyTestSuite) TestConfigApplyDeleteRoleDontAllowAutoDelete(
 	suite.Equal(1, callCount["PUT /roles/role-id-1"])
 }
 
+func (suite *ConfigApplyTestSuite) TestConfigApplySkipsUnchangedRole() {
+	getExpected := []model.RoleConfig{{
+		ID:     "test-role-id",
+		Name:   "test",
+		EnvID:  "env-id",
+		Grants: []model.GrantConfig{{Type: "api", Resource: "tenant", Permission: "full"}},
+	}}
+	getEnvironmentsExpected := []configClient.Environment{{Name: "testTenant", ID: "env-id"}}
+
+	assert.NoError(suite.T(), registerResponder(getExpected, http.StatusOK, "/roles", http.MethodGet))
+	assert.NoError(suite.T(), registerResponder(getEnvironmentsExpected, http.StatusOK, "/environments", http.MethodGet))
+
+	tempFile := util.TempAppFile("", "app", testConfigYamlStrForUpdate)
+	if tempFile == nil {
+		suite.T().Fatal("TestConfigApplySkipsUnchangedRole failed with: Could not create temp app file.")
+	}
+	suite.T().Cleanup(func() { os.Remove(tempFile.Name()) })
+	cmd := getConfigApplyCmdWithTmpFile(bytes.NewBufferString(""), tempFile, "json")
+	suite.NoError(cmd.Execute())
+	callCount := httpmock.GetCallCountInfo()
+	suite.Equal(0, callCount["PUT /roles/test-role-id"])
+}
+
+func (suite *ConfigApplyTestSuite) TestConfigApplyDryRun() {
+	registerPlanResponders(suite.T())
+	tempFile := util.TempAppFile("", "app", testConfigYamlStrForPlan)
+	if tempFile == nil {
+		suite.T().Fatal("TestConfigApplyDryRun failed with: Could not create temp app file.")
+	}
+	suite.T().Cleanup(func() { os.Remove(tempFile.Name()) })
+	outWriter := bytes.NewBufferString("")
+	cmd := getConfigApplyCmdWithTmpFile(outWriter, tempFile, "text", "--dry-run")
+	suite.NoError(cmd.Execute())
+	suite.Equal(expectedPlan+"\n", outWriter.String())
+	callCount := httpmock.GetCallCountInfo()
+	suite.Equal(0, callCount["POST /environments"])
+	suite.Equal(0, callCount["POST /roles"])
+	suite.Equal(0, callCount["PUT /roles/role-id-1"])
+	suite.Equal(0, callCount["DELETE /roles/role-id-2"])
+}
+
+func (suite *ConfigApplyTestSuite) TestConfigApplyConfirm() {
+	cases := []struct {
+		name        string
+		answer      string
+		expectedErr error
+		calls       int
+	}{
+		{name: "confirmed", answer: "yes\n", calls: 1},
+		{name: "cancelled", answer: "y\n", expectedErr: ErrApplyCancelled},
+		{name: "no answer", answer: "", expectedErr: ErrApplyCancelled},
+	}
+	for _, c := range cases {
+		suite.Run(c.name, func() {
+			httpmock.Reset()
+			registerPlanResponders(suite.T())
+			tempFile := util.TempAppFile("", "app", testConfigYamlStrForPlan)
+			if tempFile == nil {
+				suite.T().Fatal("TestConfigApplyConfirm failed with: Could not create temp app file.")
+			}
+			suite.T().Cleanup(func() { os.Remove(tempFile.Name()) })
+			outWriter := bytes.NewBufferString("")
+			cmd := getConfigApplyCmdWithTmpFile(outWriter, tempFile, "text", "--confirm")
+			cmd.SetIn(bytes.NewBufferString(c.answer))
+			err := cmd.Execute()
+			if c.expectedErr != nil {
+				suite.ErrorIs(err, c.expectedErr)
+			} else {
+				suite.NoError(err)
+			}
+			suite.Contains(outWriter.String(), expectedPlan+"\n\nDo you want to apply these changes?")
+			callCount := httpmock.GetCallCountInfo()
+			suite.Equal(c.calls, callCount["POST /environments"])
+			suite.Equal(c.calls, callCount["POST /roles"])
+			suite.Equal(c.calls, callCount["PUT /roles/role-id-1"])
+			suite.Equal(c.calls, callCount["DELETE /roles/role-id-2"])
+		})
+	}
+}
+
+func (suite *ConfigApplyTestSuite) TestConfigApplyValidatesFileFirst() {
+	registerPlanResponders(suite.T())
+	tempFile := util.TempAppFile("", "app", strings.Replace(testConfigYamlStrForPlan, "  - testTenant2\n", "  - testTenant2\n  - testTenant2\n", 1))
+	if tempFile == nil {
+		suite.T().Fatal("TestConfigApplyValidatesFileFirst failed with: Could not create temp app file.")
+	}
+	suite.T().Cleanup(func() { os.Remove(tempFile.Name()) })
+	outWriter := bytes.NewBufferString("")
+	cmd := getConfigApplyCmdWithTmpFile(outWriter, tempFile, "text")
+	suite.ErrorIs(cmd.Execute(), validate.ErrInvalidRBACFile)
+	suite.Contains(outWriter.String(), `tenants[2]: line 6, column 5: tenant "testTenant2" is listed more than once`)
+	suite.Equal(0, httpmock.GetTotalCallCount())
+}
+
+func (suite *ConfigApplyTestSuite) TestConfigApplyWarnsAboutUnknownGrants() {
+	registerPlanResponders(suite.T())
+	tempFile := util.TempAppFile("", "app", strings.Replace(testConfigYamlStrForPlan, "resource: deployment", "resource: deployments", 1))
+	if tempFile == nil {
+		suite.T().Fatal("TestConfigApplyWarnsAboutUnknownGrants failed with: Could not create temp app file.")
+	}
+	suite.T().Cleanup(func() { os.Remove(tempFile.Name()) })
+	outWriter := bytes.NewBufferString("")
+	errWriter := bytes.NewBufferString("")
+	cmd := getConfigApplyCmdWithTmpFile(outWriter, tempFile, "text")
+	cmd.SetErr(errWriter)
+	suite.NoError(cmd.Execute())
+	suite.Contains(errWriter.String(), `roles[2].grants[0].resource: line 23, column 19: resource "deployments" is not known for grant type api`)
+	suite.Contains(outWriter.String(), "Created role: deployer")
+}
+
+func (suite *ConfigApplyTestSuite) TestConfigApplyRollsBackOnFailure() {
+	registerPlanResponders(suite.T())
+	assert.NoError(suite.T(), registerResponder(configClient.CreateEnvironmentResponse{ID: "env-id-2", Name: "testTenant2"}, http.StatusCreated, "/environments", http.MethodPost))
+	assert.NoError(suite.T(), registerResponder(nil, http.StatusInternalServerError, "/roles", http.MethodPost))
+	assert.NoError(suite.T(), registerResponder(nil, http.StatusNoContent, "/environments/env-id-2", http.MethodDelete))
+	var updatedGrants [][]model.GrantConfig
+	httpmock.RegisterResponder(http.MethodPut, "/roles/role-id-1", func(req *http.Request) (*http.Response, error) {
+		var update configClient.UpdateRoleRequest
+		if err := json.NewDecoder(req.Body).Decode(&update); err != nil {
+			return nil, err
+		}
+		updatedGrants = append(updatedGrants, update.Grants)
+		return httpmock.NewJsonResponse(http.StatusOK, model.RoleConfig{})
+	})
+
+	tempFile := util.TempAppFile("", "app", testConfigYamlStrForPlan)
+	if tempFile == nil {
+		suite.T().Fatal("TestConfigApplyRollsBackOnFailure failed with: Could not create temp app file.")
+	}
+	reportFile := path.Join(suite.T().TempDir(), "report.json")
+	suite.T().Cleanup(func() { os.Remove(tempFile.Name()) })
+	cmd := getConfigApplyCmdWithTmpFile(bytes.NewBufferString(""), tempFile, "text", "--report", reportFile)
+	err := cmd.Execute()
+	suite.ErrorIs(err, ErrApplyRolledBack)
+
+	callCount := httpmock.GetCallCountInfo()
+	suite.Equal(1, callCount["POST /environments"])
+	suite.Equal(1, callCount["DELETE /environments/env-id-2"])
+	suite.Equal(0, callCount["DELETE /roles/role-id-2"])
+	suite.Equal([][]model.GrantConfig{
+		{{Type: "api", Resource: "tenant", Permission: "full"}},
+		{{Type: "api", Resource: "organization", Permission: "full"}},
+	}, updatedGrants)
+
+	content, err := os.ReadFile(reportFile)
+	suite.NoError(err)
+	var report changeReport
+	suite.NoError(json.Unmarshal(content, &report))
+	suite.Equal(reportStatusRolledBack, report.Status)
+	suite.Contains(report.Error, ErrCreatingRole.Error())
+	suite.Equal([]configChange{
+		{Action: "create", Kind: "tenant", Name: "testTenant2", ID: "env-id-2", RolledBack: true},
+		{Action: "update", Kind: "role", Name: "test", Tenant: "testTenant", ID: "role-id-1", RolledBack: true},
+	}, report.Changes)
+	suite.Len(report.Snapshot.Roles, 3)
+}
+
+func (suite *ConfigApplyTestSuite) TestConfigApplyRollbackLooksUpCreatedTenant() {
+	registerPlanResponders(suite.T())
+	assert.NoError(suite.T(), registerResponder(nil, http.StatusInternalServerError, "/roles", http.MethodPost))
+	assert.NoError(suite.T(), registerResponder(nil, http.StatusNoContent, "/environments/env-id-2", http.MethodDelete))
+	// the tenant is created without an ID in the response, it is only listed once it exists
+	environments := []configClient.Environment{{Name: "testTenant", ID: "env-id"}}
+	httpmock.RegisterResponder(http.MethodGet, "/environments", func(req *http.Request) (*http.Response, error) {
+		return httpmock.NewJsonResponse(http.StatusOK, environments)
+	})
+	httpmock.RegisterResponder(http.MethodPost, "/environments", func(req *http.Request) (*http.Response, error) {
+		environments = append(environments, configClient.Environment{Name: "testTenant2", ID: "env-id-2"})
+		return httpmock.NewJsonResponse(http.StatusCreated, configClient.CreateEnvironmentResponse{})
+	})
+
+	tempFile := util.TempAppFile("", "app", testConfigYamlStrForPlan)
+	if tempFile == nil {
+		suite.T().Fatal("TestConfigApplyRollbackLooksUpCreatedTenant failed with: Could not create temp app file.")
+	}
+	suite.T().Cleanup(func() { os.Remove(tempFile.Name()) })
+	cmd := getConfigApplyCmdWithTmpFile(bytes.NewBufferString(""), tempFile, "text")
+	suite.ErrorIs(cmd.Execute(), ErrApplyRolledBack)
+
+	callCount := httpmock.GetCallCountInfo()
+	suite.Equal(1, callCount["DELETE /environments/env-id-2"])
+	suite.Equal(0, callCount["DELETE /environments/"])
+}
+
+func (suite *ConfigApplyTestSuite) TestConfigTransactionRestoresRolesOfDeletedTenant() {
+	assert.NoError(suite.T(), registerResponder(nil, http.StatusNoContent, "/environments/env-id-2", http.MethodDelete))
+	assert.NoError(suite.T(), registerResponder(configClient.CreateEnvironmentResponse{ID: "env-id-3", Name: "testTenant2"}, http.StatusCreated, "/environments", http.MethodPost))
+	var restored []configClient.CreateRoleRequest
+	httpmock.RegisterResponder(http.MethodPost, "/roles", func(req *http.Request) (*http.Response, error) {
+		var role configClient.CreateRoleRequest
+		if err := json.NewDecoder(req.Body).Decode(&role); err != nil {
+			return nil, err
+		}
+		restored = append(restored, role)
+		return httpmock.NewJsonResponse(http.StatusCreated, configClient.CreateRoleResponse{})
+	})
+	grants := []model.GrantConfig{{Type: "api", Resource: "deployment", Permission: "full"}}
+	tx := newConfigTransaction(configuration.NewClient(getTestConfiguration()), configSnapshot{Roles: []model.RoleConfig{
+		{ID: "role-id-4", EnvID: "env-id-2", Name: "deployer", Grants: grants},
+		{ID: "role-id-5", EnvID: "env-id-2", Name: "Tenant Admin", SystemDefined: true},
+		{ID: "role-id-6", EnvID: "env-id", Name: "viewer"},
+	}})
+
+	suite.NoError(tx.deleteTenant(configClient.Environment{ID: "env-id-2", Name: "testTenant2"}))
+	suite.Empty(tx.rollback())
+	suite.Equal([]configClient.CreateRoleRequest{{Name: "deployer", Tenant: "testTenant2", Grants: grants}}, restored)
+}
+
+func (suite *ConfigApplyTestSuite) TestConfigApplyRollbackFailure() {
+	registerPlanResponders(suite.T())
+	assert.NoError(suite.T(), registerResponder(configClient.CreateEnvironmentResponse{ID: "env-id-2", Name: "testTenant2"}, http.StatusCreated, "/environments", http.MethodPost))
+	assert.NoError(suite.T(), registerResponder(nil, http.StatusInternalServerError, "/roles", http.MethodPost))
+	assert.NoError(suite.T(), registerResponder(nil, http.StatusInternalServerError, "/environments/env-id-2", http.MethodDelete))
+
+	tempFile := util.TempAppFile("", "app", testConfigYamlStrForPlan)
+	if tempFile == nil {
+		suite.T().Fatal("TestConfigApplyRollbackFailure failed with: Could not create temp app file.")
+	}
+	reportFile := path.Join(suite.T().TempDir(), "report.json")
+	suite.T().Cleanup(func() { os.Remove(tempFile.Name()) })
+	cmd := getConfigApplyCmdWithTmpFile(bytes.NewBufferString(""), tempFile, "text", "--report", reportFile)
+	suite.ErrorIs(cmd.Execute(), ErrRollbackFailed)
+
+	content, err := os.ReadFile(reportFile)
+	suite.NoError(err)
+	var report changeReport
+	suite.NoError(json.Unmarshal(content, &report))
+	suite.Equal(reportStatusRollbackFailed, report.Status)
+	suite.Len(report.RollbackErrors, 1)
+	suite.False(report.Changes[0].RolledBack)
+	suite.True(report.Changes[1].RolledBack)
+}
+
+func (suite *ConfigApplyTestSuite) TestConfigApplyWritesReport() {
+	registerPlanResponders(suite.T())
+	assert.NoError(suite.T(), registerResponder(configClient.CreateRoleResponse{ID: "role-id-4", Name: "deployer"}, http.StatusCreated, "/roles", http.MethodPost))
+	tempFile := util.TempAppFile("", "app", testConfigYamlStrForPlan)
+	if tempFile == nil {
+		suite.T().Fatal("TestConfigApplyWritesReport failed with: Could not create temp app file.")
+	}
+	reportFile := path.Join(suite.T().TempDir(), "report.json")
+	suite.T().Cleanup(func() { os.Remove(tempFile.Name()) })
+	cmd := getConfigApplyCmdWithTmpFile(bytes.NewBufferString(""), tempFile, "text", "--report", reportFile)
+	suite.NoError(cmd.Execute())
+
+	content, err := os.ReadFile(reportFile)
+	suite.NoError(err)
+	var report changeReport
+	suite.NoError(json.Unmarshal(content, &report))
+	suite.Equal(reportStatusApplied, report.Status)
+	suite.Empty(report.Error)
+	suite.Equal([]configChange{
+		{Action: "create", Kind: "tenant", Name: "testTenant2"},
+		{Action: "update", Kind: "role", Name: "test", Tenant: "testTenant", ID: "role-id-1"},
+		{Action: "create", Kind: "role", Name: "deployer", Tenant: "testTenant2", ID: "role-id-4"},
+		{Action: "delete", Kind: "role", Name: "test2", Tenant: "testTenant", ID: "role-id-2"},
+	}, report.Changes)
+}
+
+func registerPlanResponders(t *testing.T) {
+	getExpected := []model.RoleConfig{
+		{ID: "role-id-1", EnvID: "env-id", Name: "test", Grants: []model.GrantConfig{{Type: "api", Resource: "organization", Permission: "full"}}},
+		{ID: "role-id-2", EnvID: "env-id", Name: "test2", Grants: []model.GrantConfig{{Type: "api", Resource: "organization", Permission: "full"}}},
+		{ID: "role-id-3", EnvID: "env-id", Name: "test3", Grants: []model.GrantConfig{{Type: "api", Resource: "tenant", Permission: "full"}}},
+	}
+	getEnvironmentsExpected := []configClient.Environment{{Name: "testTenant", ID: "env-id"}}
+	assert.NoError(t, registerResponder(getExpected, http.StatusOK, "/roles", http.MethodGet))
+	assert.NoError(t, registerResponder(getEnvironmentsExpected, http.StatusOK, "/environments", http.MethodGet))
+	assert.NoError(t, registerResponder(configClient.CreateEnvironmentResponse{}, http.StatusCreated, "/environments", http.MethodPost))
+	assert.NoError(t, registerResponder(model.RoleConfig{}, http.StatusCreated, "/roles", http.MethodPost))
+	assert.NoError(t, registerResponder(model.RoleConfig{}, http.StatusOK, "/roles/role-id-1", http.MethodPut))
+	assert.NoError(t, registerResponder(model.RoleConfig{}, http.StatusNoContent, "/roles/role-id-2", http.MethodDelete))
+}
+
+const testConfigYamlStrForPlan = `
+allowAutoDelete: true
+tenants:
+  - testTenant
+  - testTenant2
+roles:
+  - name: test
+    tenant: testTenant
+    grants:
+      - type: api
+        resource: tenant
+        permission: full
+  - name: test3
+    tenant: testTenant
+    grants:
+      - type: api
+        resource: tenant
+        permission: full
+  - name: deployer
+    tenant: testTenant2
+    grants:
+      - type: api
+        resource: deployment
+        permission: full
+`
+
+const expectedPlan = `  + tenant testTenant2
+  + role deployer (tenant testTenant2)
+      + grant api deployment full
+  ~ role test (tenant testTenant)
+      + grant api tenant full
+      - grant api organization full
+  - role test2 (tenant testTenant)
+
+Plan: 1 tenant(s) to create, 0 to rename, 0 to delete; 1 role(s) to create, 1 to update, 1 to delete, 1 unchanged.`
+
 func registerResponder(body any, status int, url, method string) error {
 	responder, err := httpmock.NewJsonResponder(status, body)
 	if err != nil {
@@ -449,17 +821,36 @@ func registerResponder(body any, status int, url, method string) error {
 	return nil
 }
 
-func getConfigApplyCmdWithTmpFile(outWriter io.Writer, tmpFile *os.File, output string) *cobra.Command {
+func getTestConfiguration() *cliconfig.Configuration {
+	token := "some-token"
+	addr := "https://localhost"
+	clientId := ""
+	clientSecret := ""
+	output := "text"
+	isTest := true
+	return cliconfig.New(&cliconfig.Input{
+		AccessToken:  &token,
+		ApiAddr:      &addr,
+		ClientId:     &clientId,
+		ClientSecret: &clientSecret,
+		OutFormat:    &output,
+		IsTest:       &isTest,
+	})
+}
+
+func getConfigApplyCmdWithTmpFile(outWriter io.Writer, tmpFile *os.File, output string, flags ...string) *cobra.Command {
 	token := "some-token"
 	addr := "https://localhost"
 	clientId := ""
 	clientSecret := ""
+	isTest := true
 	configuration := cliconfig.New(&cliconfig.Input{
 		AccessToken:  &token,
 		ApiAddr:      &addr,
 		ClientId:     &clientId,
 		ClientSecret: &clientSecret,
 		OutFormat:    &output,
+		IsTest:       &isTest,
 	})
 	configApplyCmd := NewConfigApplyCmd(configuration)
 	configApplyCmd.SetOut(outWriter)
@@ -467,7 +858,7 @@ func getConfigApplyCmdWithTmpFile(outWriter io.Writer, tmpFile *os.File, output
 		"apply",
 		"--file=" + tmpFile.Name(),
 	}
-	configApplyCmd.SetArgs(args)
+	configApplyCmd.SetArgs(append(args, flags...))
 	return configApplyCmd
 }
 
@@ -495,6 +886,20 @@ tenants:
   - testTenant2
 `
 
+const testConfigYamlStrForRenameTenants = `
+allowAutoDelete: true
+tenants:
+  - name: pre-production
+    previousName: staging
+roles:
+  - name: deployer
+    tenant: pre-production
+    grants:
+      - type: api
+        resource: deployment
+        permission: full
+`
+
 const testConfigYamlStrForDeleteAllowAutoDelete = `
 allowAutoDelete: true
 roles:
@@ -28,6 +28,7 @@ func NewConfigCmd(configuration *cliconfig.Configuration) *cobra.Command {
 	// create subcommands
 	command.AddCommand(NewConfigApplyCmd(configuration))
 	command.AddCommand(NewConfigGetCmd(configuration))
+	command.AddCommand(NewConfigDiffCmd(configuration))
 
 	cmdUtils.SetPersistentFlagsFromEnvVariables(command.Commands())
 
@@ -0,0 +1,166 @@
+package config
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/armory/armory-cli/cmd/utils"
+	"github.com/armory/armory-cli/pkg/cmdUtils"
+	cliconfig "github.com/armory/armory-cli/pkg/config"
+	"github.com/armory/armory-cli/pkg/configuration"
+	errorUtils "github.com/armory/armory-cli/pkg/errors"
+	"github.com/armory/armory-cli/pkg/model"
+	"github.com/armory/armory-cli/pkg/model/configClient"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	log "go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	configDiffShort = "Compare an RBAC configuration file with your current RBAC configuration"
+	configDiffLong  = "Compare an RBAC configuration file with your current RBAC configuration\n\n" +
+		"Tenants and roles that are missing (+), whose grants differ (~) or that are not in the file (-) are listed, " +
+		"whether or not allowAutoDelete is set. The command fails when there is a difference, so that a scheduled job " +
+		"can detect roles that were changed outside of the file"
+	configDiffExample = "armory config diff -f rbac.yml -o json"
+)
+
+type (
+	configDiffOptions struct {
+		configFile string
+	}
+
+	// configDiff is the difference between an RBAC configuration file and the current configuration.
+	configDiff struct {
+		Drift            bool                       `json:"drift" yaml:"drift"`
+		MissingTenants   []string                   `json:"missingTenants" yaml:"missingTenants"`
+		RenamedTenants   []tenantRename             `json:"renamedTenants" yaml:"renamedTenants"`
+		TenantsNotInFile []configClient.Environment `json:"tenantsNotInFile" yaml:"tenantsNotInFile"`
+		MissingRoles     []model.RoleConfig         `json:"missingRoles" yaml:"missingRoles"`
+		ChangedRoles     []roleUpdate               `json:"changedRoles" yaml:"changedRoles"`
+		RolesNotInFile   []model.RoleConfig         `json:"rolesNotInFile" yaml:"rolesNotInFile"`
+		UnchangedRoles   int                        `json:"unchangedRoles" yaml:"unchangedRoles"`
+	}
+
+	FormattableConfigDiff struct {
+		Diff         configDiff
+		httpResponse *http.Response
+		err          error
+	}
+)
+
+func NewConfigDiffCmd(configuration *cliconfig.Configuration) *cobra.Command {
+	options := &configDiffOptions{}
+	cmd := &cobra.Command{
+		Use:     "diff --file [<path to file>]",
+		Short:   configDiffShort,
+		Long:    configDiffLong,
+		Example: configDiffExample,
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			cmdUtils.ExecuteParentHooks(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return diff(cmd, options, configuration)
+		},
+	}
+	cmd.Flags().StringVarP(&options.configFile, "file", "f", "", "path to the configuration file")
+	cmd.MarkFlagRequired("file")
+	return cmd
+}
+
+func diff(cmd *cobra.Command, options *configDiffOptions, cli *cliconfig.Configuration) error {
+	if lo.FromPtr(cli.GetIsTest()) {
+		utils.ConfigureLoggingForTesting(cmd)
+	}
+	//in case this is running on a GitHub instance
+	gitWorkspace, present := os.LookupEnv("GITHUB_WORKSPACE")
+	_, isATest := os.LookupEnv("ARMORY_CLI_TEST")
+	if present && !isATest {
+		options.configFile = gitWorkspace + options.configFile
+	}
+	file, err := os.ReadFile(options.configFile)
+	if err != nil {
+		return errorUtils.NewWrappedError(ErrReadingYamlFile, err)
+	}
+	cmd.SilenceUsage = true
+	payload := model.ConfigurationConfig{}
+	if err := yaml.Unmarshal(file, &payload); err != nil {
+		return errorUtils.NewWrappedError(ErrInvalidConfigurationObject, err)
+	}
+	plan, _, err := planConfiguration(configuration.NewClient(cli), payload)
+	if err != nil {
+		return err
+	}
+	configDiff := newConfigDiff(plan)
+	dataFormat, err := cli.GetOutputFormatter()(FormattableConfigDiff{Diff: configDiff})
+	if err != nil {
+		return err
+	}
+	log.S().Info(dataFormat)
+	if configDiff.Drift {
+		return ErrConfigurationDrift
+	}
+	return nil
+}
+
+func newConfigDiff(plan *configPlan) configDiff {
+	return configDiff{
+		Drift: len(plan.TenantsToCreate) > 0 || len(plan.TenantsToRename) > 0 || len(plan.TenantsToDelete) > 0 ||
+			len(plan.RolesToCreate) > 0 || len(plan.RolesToUpdate) > 0 || len(plan.RolesToDelete) > 0,
+		MissingTenants:   plan.TenantsToCreate,
+		RenamedTenants:   plan.TenantsToRename,
+		TenantsNotInFile: plan.TenantsToDelete,
+		MissingRoles:     plan.RolesToCreate,
+		ChangedRoles:     plan.RolesToUpdate,
+		RolesNotInFile:   plan.RolesToDelete,
+		UnchangedRoles:   plan.UnchangedRoles,
+	}
+}
+
+func (d FormattableConfigDiff) Get() interface{} {
+	return d.Diff
+}
+
+func (d FormattableConfigDiff) GetHttpResponse() *http.Response {
+	return d.httpResponse
+}
+
+func (d FormattableConfigDiff) GetFetchError() error {
+	return d.err
+}
+
+func (d FormattableConfigDiff) String() string {
+	configDiff := d.Diff
+	if !configDiff.Drift {
+		return fmt.Sprintf("No differences, the %d role(s) of the file match the current configuration.", configDiff.UnchangedRoles)
+	}
+	var sb strings.Builder
+	for _, tenant := range configDiff.MissingTenants {
+		sb.WriteString(fmt.Sprintf("  + tenant %s\n", tenant))
+	}
+	for _, rename := range configDiff.RenamedTenants {
+		sb.WriteString(fmt.Sprintf("  ~ tenant %s renamed to %s\n", rename.PreviousName, rename.Name))
+	}
+	for _, role := range configDiff.MissingRoles {
+		sb.WriteString(fmt.Sprintf("  + role %s\n", describeRole(role)))
+		writeGrants(&sb, "+", role.Grants)
+	}
+	for _, update := range configDiff.ChangedRoles {
+		sb.WriteString(fmt.Sprintf("  ~ role %s\n", describeRole(update.Role)))
+		writeGrants(&sb, "+", update.AddedGrants)
+		writeGrants(&sb, "-", update.RemovedGrants)
+	}
+	for _, role := range configDiff.RolesNotInFile {
+		sb.WriteString(fmt.Sprintf("  - role %s\n", describeRole(role)))
+		writeGrants(&sb, "-", role.Grants)
+	}
+	for _, tenant := range configDiff.TenantsNotInFile {
+		sb.WriteString(fmt.Sprintf("  - tenant %s\n", tenant.Name))
+	}
+	sb.WriteString(fmt.Sprintf("\n%d tenant(s) missing, %d renamed, %d not in the file; %d role(s) missing, %d changed, %d not in the file, %d unchanged.",
+		len(configDiff.MissingTenants), len(configDiff.RenamedTenants), len(configDiff.TenantsNotInFile), len(configDiff.MissingRoles), len(configDiff.ChangedRoles), len(configDiff.RolesNotInFile), configDiff.UnchangedRoles))
+	return sb.String()
+}
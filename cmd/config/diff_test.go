@@ -0,0 +1,124 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	cliconfig "github.com/armory/armory-cli/pkg/config"
+	"github.com/armory/armory-cli/pkg/model"
+	"github.com/armory/armory-cli/pkg/model/configClient"
+	"github.com/armory/armory-cli/pkg/util"
+	"github.com/jarcoal/httpmock"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"io"
+	"net/http"
+	"os"
+	"testing"
+)
+
+func TestConfigDiffTestSuite(t *testing.T) {
+	suite.Run(t, new(ConfigDiffTestSuite))
+}
+
+type ConfigDiffTestSuite struct {
+	suite.Suite
+}
+
+func (suite *ConfigDiffTestSuite) SetupSuite() {
+	os.Setenv("ARMORY_CLI_TEST", "true")
+	httpmock.Activate()
+}
+
+func (suite *ConfigDiffTestSuite) SetupTest() {
+	httpmock.Reset()
+}
+
+func (suite *ConfigDiffTestSuite) TearDownSuite() {
+	os.Unsetenv("ARMORY_CLI_TEST")
+	httpmock.DeactivateAndReset()
+}
+
+func (suite *ConfigDiffTestSuite) TestConfigDiffDrift() {
+	registerPlanResponders(suite.T())
+	tempFile := util.TempAppFile("", "app", testConfigYamlStrForPlan)
+	if tempFile == nil {
+		suite.T().Fatal("TestConfigDiffDrift failed with: Could not create temp app file.")
+	}
+	suite.T().Cleanup(func() { os.Remove(tempFile.Name()) })
+
+	outWriter := bytes.NewBufferString("")
+	cmd := getConfigDiffCmdWithTmpFile(outWriter, tempFile, "text")
+	suite.ErrorIs(cmd.Execute(), ErrConfigurationDrift)
+	suite.Equal(`  + tenant testTenant2
+  + role deployer (tenant testTenant2)
+      + grant api deployment full
+  ~ role test (tenant testTenant)
+      + grant api tenant full
+      - grant api organization full
+  - role test2 (tenant testTenant)
+      - grant api organization full
+
+1 tenant(s) missing, 0 renamed, 0 not in the file; 1 role(s) missing, 1 changed, 1 not in the file, 1 unchanged.
+`, outWriter.String())
+
+	outWriter = bytes.NewBufferString("")
+	cmd = getConfigDiffCmdWithTmpFile(outWriter, tempFile, "json")
+	suite.ErrorIs(cmd.Execute(), ErrConfigurationDrift)
+	var result configDiff
+	suite.NoError(json.Unmarshal(outWriter.Bytes(), &result))
+	suite.True(result.Drift)
+	suite.Equal([]string{"testTenant2"}, result.MissingTenants)
+	suite.Equal([]string{"test2"}, lo.Map(result.RolesNotInFile, func(role model.RoleConfig, _ int) string { return role.Name }))
+	callCount := httpmock.GetCallCountInfo()
+	suite.Equal(0, callCount["POST /roles"])
+	suite.Equal(0, callCount["PUT /roles/role-id-1"])
+	suite.Equal(0, callCount["DELETE /roles/role-id-2"])
+}
+
+func (suite *ConfigDiffTestSuite) TestConfigDiffNoDrift() {
+	getExpected := []model.RoleConfig{{
+		ID:     "test-role-id",
+		Name:   "test",
+		EnvID:  "env-id",
+		Grants: []model.GrantConfig{{Type: "api", Resource: "tenant", Permission: "full"}},
+	}, {
+		ID:            "system-role-id",
+		Name:          "Organization Admin",
+		SystemDefined: true,
+	}}
+	getEnvironmentsExpected := []configClient.Environment{{Name: "testTenant", ID: "env-id"}}
+	assert.NoError(suite.T(), registerResponder(getExpected, http.StatusOK, "/roles", http.MethodGet))
+	assert.NoError(suite.T(), registerResponder(getEnvironmentsExpected, http.StatusOK, "/environments", http.MethodGet))
+	tempFile := util.TempAppFile("", "app", testConfigYamlStrForUpdate)
+	if tempFile == nil {
+		suite.T().Fatal("TestConfigDiffNoDrift failed with: Could not create temp app file.")
+	}
+	suite.T().Cleanup(func() { os.Remove(tempFile.Name()) })
+
+	outWriter := bytes.NewBufferString("")
+	cmd := getConfigDiffCmdWithTmpFile(outWriter, tempFile, "text")
+	suite.NoError(cmd.Execute())
+	suite.Equal("No differences, the 1 role(s) of the file match the current configuration.\n", outWriter.String())
+}
+
+func getConfigDiffCmdWithTmpFile(outWriter io.Writer, tmpFile *os.File, output string) *cobra.Command {
+	token := "some-token"
+	addr := "https://localhost"
+	clientId := ""
+	clientSecret := ""
+	isTest := true
+	configuration := cliconfig.New(&cliconfig.Input{
+		AccessToken:  &token,
+		ApiAddr:      &addr,
+		ClientId:     &clientId,
+		ClientSecret: &clientSecret,
+		OutFormat:    &output,
+		IsTest:       &isTest,
+	})
+	configDiffCmd := NewConfigDiffCmd(configuration)
+	configDiffCmd.SetOut(outWriter)
+	configDiffCmd.SetArgs([]string{"--file=" + tmpFile.Name()})
+	return configDiffCmd
+}
@@ -13,5 +13,12 @@ var (
 	ErrGettingRoles               = errors.New("error getting existing roles")
 	ErrCreatingEnvironment        = errors.New("error trying to create environment")
 	ErrGettingEnvironments        = errors.New("error getting environments")
+	ErrRenamingEnvironment        = errors.New("error trying to rename environment")
+	ErrDeletingEnvironment        = errors.New("error trying to delete environment")
 	ErrParsingGetConfigResponse   = errors.New("error trying to parse response")
+	ErrApplyRolledBack            = errors.New("the configuration could not be applied, the changes that were made have been rolled back")
+	ErrRollbackFailed             = errors.New("the configuration could not be applied and some changes could not be rolled back, RBAC may be partially applied")
+	ErrWritingChangeReport        = errors.New("error trying to write the change report")
+	ErrApplyCancelled             = errors.New("apply cancelled, no changes were made")
+	ErrConfigurationDrift         = errors.New("the current RBAC configuration differs from the configuration file")
 )
@@ -0,0 +1,191 @@
+package config
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/armory/armory-cli/pkg/model"
+	"github.com/armory/armory-cli/pkg/model/configClient"
+	"github.com/samber/lo"
+)
+
+type (
+	// configPlan is the difference between an RBAC configuration file and the tenants and roles that exist, which
+	// apply acts on.
+	configPlan struct {
+		TenantsToCreate []string       `json:"tenantsToCreate" yaml:"tenantsToCreate"`
+		TenantsToRename []tenantRename `json:"tenantsToRename" yaml:"tenantsToRename"`
+		// TenantsToDelete are the tenants that are not in the file, when it lists tenants, and that none of its roles
+		// belong to. Like roles, they are only deleted when AllowAutoDelete is set.
+		TenantsToDelete []configClient.Environment `json:"tenantsToDelete" yaml:"tenantsToDelete"`
+		RolesToCreate   []model.RoleConfig         `json:"rolesToCreate" yaml:"rolesToCreate"`
+		RolesToUpdate   []roleUpdate               `json:"rolesToUpdate" yaml:"rolesToUpdate"`
+		// RolesToDelete are the roles that are not in the file. They are only deleted when AllowAutoDelete is set.
+		RolesToDelete   []model.RoleConfig `json:"rolesToDelete" yaml:"rolesToDelete"`
+		AllowAutoDelete bool               `json:"allowAutoDelete" yaml:"allowAutoDelete"`
+		// SystemRoles are the roles of the file that are system defined, which cannot be changed.
+		SystemRoles    []string `json:"systemRoles" yaml:"systemRoles"`
+		UnchangedRoles int      `json:"unchangedRoles" yaml:"unchangedRoles"`
+	}
+
+	tenantRename struct {
+		ID           string `json:"id" yaml:"id"`
+		PreviousName string `json:"previousName" yaml:"previousName"`
+		Name         string `json:"name" yaml:"name"`
+	}
+
+	// roleUpdate is a role whose grants differ from the file. Role is the role as it is in the file, with the ID of the
+	// existing role.
+	roleUpdate struct {
+		Role          model.RoleConfig    `json:"role" yaml:"role"`
+		AddedGrants   []model.GrantConfig `json:"addedGrants" yaml:"addedGrants"`
+		RemovedGrants []model.GrantConfig `json:"removedGrants" yaml:"removedGrants"`
+	}
+
+	FormattableConfigPlan struct {
+		Plan         *configPlan
+		httpResponse *http.Response
+		err          error
+	}
+)
+
+func newConfigPlan(payload model.ConfigurationConfig, existingEnvironments []configClient.Environment, existingRoles []model.RoleConfig) *configPlan {
+	plan := &configPlan{
+		TenantsToCreate: []string{},
+		TenantsToRename: []tenantRename{},
+		TenantsToDelete: []configClient.Environment{},
+		RolesToCreate:   []model.RoleConfig{},
+		RolesToUpdate:   []roleUpdate{},
+		RolesToDelete:   []model.RoleConfig{},
+		AllowAutoDelete: payload.AllowAutoDelete,
+		SystemRoles:     []string{},
+	}
+	// roles are matched with the tenants as they are once renamed
+	existingEnvironments = append([]configClient.Environment(nil), existingEnvironments...)
+	for _, tenant := range payload.Environments {
+		if configEnvironmentMatchesAPIEnvironments(tenant.Name, existingEnvironments) {
+			continue
+		}
+		_, previous, renamed := lo.FindIndexOf(existingEnvironments, func(e configClient.Environment) bool {
+			return tenant.PreviousName != "" && e.Name == tenant.PreviousName
+		})
+		if !renamed {
+			plan.TenantsToCreate = append(plan.TenantsToCreate, tenant.Name)
+			continue
+		}
+		plan.TenantsToRename = append(plan.TenantsToRename, tenantRename{ID: existingEnvironments[previous].ID, PreviousName: tenant.PreviousName, Name: tenant.Name})
+		existingEnvironments[previous].Name = tenant.Name
+	}
+	if payload.Environments != nil {
+		plan.TenantsToDelete = lo.Filter(existingEnvironments, func(e configClient.Environment, _ int) bool {
+			return !lo.ContainsBy(payload.Environments, func(tenant model.TenantConfig) bool { return tenant.Name == e.Name }) &&
+				!lo.ContainsBy(payload.Roles, func(role model.RoleConfig) bool { return role.Tenant == e.Name })
+		})
+	}
+
+	for _, roleInConfig := range payload.Roles {
+		roleInExisting, exists := lo.Find(existingRoles, func(apiRole model.RoleConfig) bool {
+			return configRoleMatchesAPIRole(roleInConfig, apiRole, existingEnvironments)
+		})
+		switch {
+		case !exists:
+			plan.RolesToCreate = append(plan.RolesToCreate, roleInConfig)
+		case roleInExisting.SystemDefined:
+			plan.SystemRoles = append(plan.SystemRoles, roleInConfig.Name)
+		default:
+			added, removed := lo.Difference(roleInConfig.Grants, roleInExisting.Grants)
+			if len(added) == 0 && len(removed) == 0 {
+				plan.UnchangedRoles++
+				continue
+			}
+			role := roleInConfig
+			role.ID, role.EnvID = roleInExisting.ID, roleInExisting.EnvID
+			plan.RolesToUpdate = append(plan.RolesToUpdate, roleUpdate{Role: role, AddedGrants: added, RemovedGrants: removed})
+		}
+	}
+	for _, role := range findDeletedRoles(payload.Roles, existingRoles, existingEnvironments) {
+		if environment, ok := lo.Find(existingEnvironments, func(e configClient.Environment) bool { return e.ID == role.EnvID }); ok && role.Tenant == "" {
+			role.Tenant = environment.Name
+		}
+		plan.RolesToDelete = append(plan.RolesToDelete, role)
+	}
+	return plan
+}
+
+// hasChanges tells whether applying the plan changes anything. Roles that are not in the file only count when they
+// are deleted.
+func (p *configPlan) hasChanges() bool {
+	return len(p.TenantsToCreate) > 0 || len(p.TenantsToRename) > 0 || len(p.RolesToCreate) > 0 || len(p.RolesToUpdate) > 0 ||
+		(p.AllowAutoDelete && (len(p.RolesToDelete) > 0 || len(p.TenantsToDelete) > 0))
+}
+
+func (p FormattableConfigPlan) Get() interface{} {
+	return p.Plan
+}
+
+func (p FormattableConfigPlan) GetHttpResponse() *http.Response {
+	return p.httpResponse
+}
+
+func (p FormattableConfigPlan) GetFetchError() error {
+	return p.err
+}
+
+func (p FormattableConfigPlan) String() string {
+	plan := p.Plan
+	var sb strings.Builder
+	for _, tenant := range plan.TenantsToCreate {
+		sb.WriteString(fmt.Sprintf("  + tenant %s\n", tenant))
+	}
+	for _, rename := range plan.TenantsToRename {
+		sb.WriteString(fmt.Sprintf("  ~ tenant %s renamed to %s\n", rename.PreviousName, rename.Name))
+	}
+	for _, role := range plan.RolesToCreate {
+		sb.WriteString(fmt.Sprintf("  + role %s\n", describeRole(role)))
+		writeGrants(&sb, "+", role.Grants)
+	}
+	for _, update := range plan.RolesToUpdate {
+		sb.WriteString(fmt.Sprintf("  ~ role %s\n", describeRole(update.Role)))
+		writeGrants(&sb, "+", update.AddedGrants)
+		writeGrants(&sb, "-", update.RemovedGrants)
+	}
+	for _, role := range plan.RolesToDelete {
+		if plan.AllowAutoDelete {
+			sb.WriteString(fmt.Sprintf("  - role %s\n", describeRole(role)))
+		} else {
+			sb.WriteString(fmt.Sprintf("  # role %s is not in the file, it is kept because allowAutoDelete is not set\n", describeRole(role)))
+		}
+	}
+	for _, tenant := range plan.TenantsToDelete {
+		if plan.AllowAutoDelete {
+			sb.WriteString(fmt.Sprintf("  - tenant %s\n", tenant.Name))
+		} else {
+			sb.WriteString(fmt.Sprintf("  # tenant %s is not in the file, it is kept because allowAutoDelete is not set\n", tenant.Name))
+		}
+	}
+	for _, role := range plan.SystemRoles {
+		sb.WriteString(fmt.Sprintf("  # role %s is a system role, it cannot be changed\n", role))
+	}
+	if sb.Len() > 0 {
+		sb.WriteString("\n")
+	}
+	deletedTenants := lo.Ternary(plan.AllowAutoDelete, len(plan.TenantsToDelete), 0)
+	deletedRoles := lo.Ternary(plan.AllowAutoDelete, len(plan.RolesToDelete), 0)
+	sb.WriteString(fmt.Sprintf("Plan: %d tenant(s) to create, %d to rename, %d to delete; %d role(s) to create, %d to update, %d to delete, %d unchanged.",
+		len(plan.TenantsToCreate), len(plan.TenantsToRename), deletedTenants, len(plan.RolesToCreate), len(plan.RolesToUpdate), deletedRoles, plan.UnchangedRoles))
+	return sb.String()
+}
+
+func describeRole(role model.RoleConfig) string {
+	if role.Tenant == "" {
+		return role.Name
+	}
+	return fmt.Sprintf("%s (tenant %s)", role.Name, role.Tenant)
+}
+
+func writeGrants(sb *strings.Builder, sign string, grants []model.GrantConfig) {
+	for _, grant := range grants {
+		sb.WriteString(fmt.Sprintf("      %s grant %s %s %s\n", sign, grant.Type, grant.Resource, grant.Permission))
+	}
+}
@@ -0,0 +1,278 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/armory/armory-cli/pkg/configuration"
+	errorUtils "github.com/armory/armory-cli/pkg/errors"
+	"github.com/armory/armory-cli/pkg/model"
+	"github.com/armory/armory-cli/pkg/model/configClient"
+	"github.com/samber/lo"
+	log "go.uber.org/zap"
+)
+
+const (
+	reportStatusApplied        = "applied"
+	reportStatusRolledBack     = "rolledBack"
+	reportStatusRollbackFailed = "rollbackFailed"
+)
+
+type (
+	// configSnapshot is the RBAC configuration as it was before apply changed it.
+	configSnapshot struct {
+		Environments []configClient.Environment `json:"tenants"`
+		Roles        []model.RoleConfig         `json:"roles"`
+	}
+
+	// configChange is a change apply made. revert undoes it, it is replayed when a later change fails.
+	configChange struct {
+		Action     string `json:"action"`
+		Kind       string `json:"kind"`
+		Name       string `json:"name"`
+		Tenant     string `json:"tenant,omitempty"`
+		ID         string `json:"id,omitempty"`
+		RolledBack bool   `json:"rolledBack"`
+		revert     func(tx *configTransaction) error
+	}
+
+	// changeReport is what apply writes with --report, whether it succeeded or not.
+	changeReport struct {
+		Status         string         `json:"status"`
+		Error          string         `json:"error,omitempty"`
+		RollbackErrors []string       `json:"rollbackErrors,omitempty"`
+		Changes        []configChange `json:"changes"`
+		Snapshot       configSnapshot `json:"snapshot"`
+	}
+
+	// configTransaction makes the changes of a plan and records them, so that RBAC is never left half-applied: when a
+	// change fails, the ones that were made are reverted in reverse order.
+	configTransaction struct {
+		client   *configuration.ConfigClient
+		snapshot configSnapshot
+		changes  []configChange
+	}
+)
+
+func newConfigTransaction(client *configuration.ConfigClient, snapshot configSnapshot) *configTransaction {
+	return &configTransaction{client: client, snapshot: snapshot, changes: []configChange{}}
+}
+
+func (tx *configTransaction) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(tx.client.ArmoryCloudClient.Context, time.Minute)
+}
+
+func (tx *configTransaction) record(change configChange) {
+	tx.changes = append(tx.changes, change)
+}
+
+func (tx *configTransaction) createTenant(name string) error {
+	ctx, cancel := tx.context()
+	defer cancel()
+	environment, _, err := tx.client.CreateEnvironment(ctx, configuration.CreateEnvironmentRequest(name))
+	if err != nil {
+		return errorUtils.NewWrappedError(ErrCreatingEnvironment, err)
+	}
+	id := environment.ID
+	tx.record(configChange{Action: "create", Kind: "tenant", Name: name, ID: id, revert: func(tx *configTransaction) error {
+		if id == "" {
+			if id, err = tx.findEnvironmentID(name); err != nil {
+				return err
+			}
+		}
+		return tx.deleteEnvironment(id)
+	}})
+	return nil
+}
+
+func (tx *configTransaction) renameTenant(rename tenantRename) error {
+	if err := tx.updateEnvironment(rename.ID, rename.Name); err != nil {
+		return errorUtils.NewWrappedError(ErrRenamingEnvironment, err)
+	}
+	tx.record(configChange{Action: "rename", Kind: "tenant", Name: rename.Name, ID: rename.ID, revert: func(tx *configTransaction) error {
+		return tx.updateEnvironment(rename.ID, rename.PreviousName)
+	}})
+	return nil
+}
+
+func (tx *configTransaction) deleteTenant(environment configClient.Environment) error {
+	if err := tx.deleteEnvironment(environment.ID); err != nil {
+		return errorUtils.NewWrappedError(ErrDeletingEnvironment, err)
+	}
+	// the server deletes the roles of the tenant with it. Those that apply did not delete itself, and so would not
+	// restore, are restored with the tenant.
+	roles := lo.Filter(tx.snapshot.Roles, func(r model.RoleConfig, _ int) bool {
+		return r.EnvID == environment.ID && !r.SystemDefined && !tx.removedRole(r.ID)
+	})
+	// a tenant that is created again gets a new ID, the roles restored after it use its name
+	tx.record(configChange{Action: "delete", Kind: "tenant", Name: environment.Name, ID: environment.ID, revert: func(tx *configTransaction) error {
+		ctx, cancel := tx.context()
+		defer cancel()
+		if _, _, err := tx.client.CreateEnvironment(ctx, configuration.CreateEnvironmentRequest(environment.Name)); err != nil {
+			return err
+		}
+		for _, role := range roles {
+			role.Tenant = environment.Name
+			if _, err := tx.postRole(role); err != nil {
+				return err
+			}
+		}
+		return nil
+	}})
+	return nil
+}
+
+func (tx *configTransaction) createRole(role model.RoleConfig) error {
+	id, err := tx.postRole(role)
+	if err != nil {
+		return errorUtils.NewWrappedError(ErrCreatingRole, err)
+	}
+	tx.record(configChange{Action: "create", Kind: "role", Name: role.Name, Tenant: role.Tenant, ID: id, revert: func(tx *configTransaction) error {
+		if id == "" {
+			if id, err = tx.findRoleID(role); err != nil {
+				return err
+			}
+		}
+		return tx.deleteRole(id)
+	}})
+	return nil
+}
+
+func (tx *configTransaction) updateRole(role model.RoleConfig) error {
+	if err := tx.putRole(role.ID, role.Tenant, role.Grants); err != nil {
+		return errorUtils.NewWrappedError(ErrUpdateRole, err)
+	}
+	previous, _ := lo.Find(tx.snapshot.Roles, func(r model.RoleConfig) bool { return r.ID == role.ID })
+	tx.record(configChange{Action: "update", Kind: "role", Name: role.Name, Tenant: role.Tenant, ID: role.ID, revert: func(tx *configTransaction) error {
+		return tx.putRole(role.ID, role.Tenant, previous.Grants)
+	}})
+	return nil
+}
+
+func (tx *configTransaction) removeRole(role model.RoleConfig) error {
+	if err := tx.deleteRole(role.ID); err != nil {
+		return errorUtils.NewWrappedError(ErrDeletingRole, err)
+	}
+	tx.record(configChange{Action: "delete", Kind: "role", Name: role.Name, Tenant: role.Tenant, ID: role.ID, revert: func(tx *configTransaction) error {
+		_, err := tx.postRole(role)
+		return err
+	}})
+	return nil
+}
+
+// rollback reverts the changes that were made, the last one first. It goes on when a change cannot be reverted, and
+// returns the errors of those that could not.
+func (tx *configTransaction) rollback() []error {
+	var errs []error
+	for i := len(tx.changes) - 1; i >= 0; i-- {
+		change := &tx.changes[i]
+		if err := change.revert(tx); err != nil {
+			errs = append(errs, errorUtils.NewErrorWithDynamicContext(err, fmt.Sprintf(" (reverting the %s of %s %s)", change.Action, change.Kind, change.Name)))
+			continue
+		}
+		change.RolledBack = true
+		log.S().Infof("Rolled back the %s of %s: %s", change.Action, change.Kind, change.Name)
+	}
+	return errs
+}
+
+func (tx *configTransaction) report(err error, rollbackErrs []error) changeReport {
+	report := changeReport{Status: reportStatusApplied, Changes: tx.changes, Snapshot: tx.snapshot}
+	if err == nil {
+		return report
+	}
+	report.Error = err.Error()
+	report.Status = lo.Ternary(len(rollbackErrs) == 0, reportStatusRolledBack, reportStatusRollbackFailed)
+	report.RollbackErrors = lo.Map(rollbackErrs, func(err error, _ int) string { return err.Error() })
+	return report
+}
+
+func (tx *configTransaction) updateEnvironment(id, name string) error {
+	ctx, cancel := tx.context()
+	defer cancel()
+	_, err := tx.client.UpdateEnvironment(ctx, configuration.UpdateEnvironmentRequest(id, name))
+	return err
+}
+
+func (tx *configTransaction) deleteEnvironment(id string) error {
+	ctx, cancel := tx.context()
+	defer cancel()
+	_, err := tx.client.DeleteEnvironment(ctx, configuration.DeleteEnvironmentRequest(id))
+	return err
+}
+
+func (tx *configTransaction) postRole(role model.RoleConfig) (string, error) {
+	ctx, cancel := tx.context()
+	defer cancel()
+	req, err := configuration.CreateRoleRequest(&role)
+	if err != nil {
+		return "", err
+	}
+	created, _, err := tx.client.CreateRole(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	return created.ID, nil
+}
+
+func (tx *configTransaction) putRole(id, tenant string, grants []model.GrantConfig) error {
+	ctx, cancel := tx.context()
+	defer cancel()
+	req, err := configuration.UpdateRolesRequest(id, tenant, grants)
+	if err != nil {
+		return err
+	}
+	_, _, err = tx.client.UpdateRole(ctx, req)
+	return err
+}
+
+func (tx *configTransaction) deleteRole(id string) error {
+	ctx, cancel := tx.context()
+	defer cancel()
+	req, err := configuration.DeleteRolesRequest(id)
+	if err != nil {
+		return err
+	}
+	_, err = tx.client.DeleteRole(ctx, req)
+	return err
+}
+
+// removedRole tells whether the transaction deleted a role.
+func (tx *configTransaction) removedRole(id string) bool {
+	return lo.ContainsBy(tx.changes, func(c configChange) bool { return c.Kind == "role" && c.Action == "delete" && c.ID == id })
+}
+
+// findEnvironmentID looks up the ID of a tenant that was created, for when the API did not return it.
+func (tx *configTransaction) findEnvironmentID(name string) (string, error) {
+	ctx, cancel := tx.context()
+	defer cancel()
+	environments, err := tx.client.GetEnvironments(ctx)
+	if err != nil {
+		return "", err
+	}
+	created, ok := lo.Find(environments, func(e configClient.Environment) bool { return e.Name == name })
+	if !ok {
+		return "", errorUtils.NewErrorWithDynamicContext(ErrGettingEnvironments, ": the created tenant "+name+" was not found")
+	}
+	return created.ID, nil
+}
+
+// findRoleID looks up the ID of a role that was created, for when the API did not return it.
+func (tx *configTransaction) findRoleID(role model.RoleConfig) (string, error) {
+	ctx, cancel := tx.context()
+	defer cancel()
+	environments, err := tx.client.GetEnvironments(ctx)
+	if err != nil {
+		return "", err
+	}
+	roles, _, err := tx.client.GetRoles(ctx)
+	if err != nil {
+		return "", err
+	}
+	created, ok := lo.Find(roles, func(r model.RoleConfig) bool { return configRoleMatchesAPIRole(role, r, environments) })
+	if !ok {
+		return "", errorUtils.NewErrorWithDynamicContext(ErrGettingRoles, ": the created role "+role.Name+" was not found")
+	}
+	return created.ID, nil
+}
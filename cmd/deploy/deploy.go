@@ -33,6 +33,10 @@ func NewDeployCmd(configuration *config.Configuration) *cobra.Command {
 		PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
 
 			deploymentID := fetchCommandResult(cmd, DeployResultDeploymentID)
+			if deploymentID == "" {
+				// commands such as list are not about a single deployment, so there is no status to report
+				return nil
+			}
 			url := buildMonitoringUrl(configuration, deploymentID)
 
 			reportableStatus := []string{DeployResultDeploymentID, deploymentID, DeployResultLink, url}
@@ -66,6 +70,14 @@ func NewDeployCmd(configuration *config.Configuration) *cobra.Command {
 	// create subcommands
 	command.AddCommand(NewDeployStartCmd(configuration))
 	command.AddCommand(NewDeployStatusCmd(configuration))
+	command.AddCommand(NewDeployListCmd(configuration))
+	command.AddCommand(NewDeployWatchCmd(configuration))
+	command.AddCommand(NewDeployApproveCmd(configuration))
+	command.AddCommand(NewDeployRejectCmd(configuration))
+	command.AddCommand(NewDeploySkipPauseCmd(configuration))
+	command.AddCommand(NewDeployCancelCmd(configuration))
+	command.AddCommand(NewDeployRollbackCmd(configuration))
+	command.AddCommand(NewDeploySimulateCmd(configuration))
 
 	cmdUtils.SetPersistentFlagsFromEnvVariables(command.Commands())
 
@@ -0,0 +1,90 @@
+package deploy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	de "github.com/armory-io/deploy-engine/pkg/api"
+	"github.com/armory/armory-cli/pkg/armoryCloud"
+	deployment "github.com/armory/armory-cli/pkg/deploy"
+	"github.com/samber/lo"
+	nethttp "net/http"
+	"sort"
+	"strings"
+)
+
+type (
+	// dryRunDeployClient stands in for the deploy client when --dry-run is set. Instead of starting a pipeline it
+	// records the request that would have been sent. The configuration of a pipeline is only fetched when configClient
+	// is set, since that is the one request of a dry run, and a read-only one.
+	dryRunDeployClient struct {
+		request      *deployment.PipelineRequest
+		configClient ArmoryDeployClient
+	}
+
+	FormattableDryRunResponse struct {
+		Request      *deployment.PipelineRequest
+		httpResponse *nethttp.Response
+		err          error
+	}
+)
+
+func (c *dryRunDeployClient) PipelineStatus(context.Context, string) (*de.PipelineStatusResponse, *nethttp.Response, error) {
+	return nil, nil, ErrDryRunNoRequests
+}
+
+func (c *dryRunDeployClient) DeploymentStatus(context.Context, string) (*de.DeploymentStatusResponse, *nethttp.Response, error) {
+	return nil, nil, ErrDryRunNoRequests
+}
+
+func (c *dryRunDeployClient) StartPipeline(_ context.Context, options deployment.StartPipelineOptions) (*de.StartPipelineResponse, *nethttp.Response, error) {
+	request, err := deployment.NewPipelineRequest(options)
+	if err != nil {
+		return nil, nil, err
+	}
+	c.request = request
+	return &de.StartPipelineResponse{}, nil, nil
+}
+
+func (c *dryRunDeployClient) PipelineConfig(ctx context.Context, pipelineID string) (map[string]any, *nethttp.Response, error) {
+	if c.configClient == nil {
+		return nil, nil, ErrDryRunNoRequests
+	}
+	return c.configClient.PipelineConfig(ctx, pipelineID)
+}
+
+func (c *dryRunDeployClient) GetArmoryCloudClient() *armoryCloud.Client {
+	if c.configClient != nil {
+		return c.configClient.GetArmoryCloudClient()
+	}
+	return &armoryCloud.Client{Context: context.Background()}
+}
+
+func (r FormattableDryRunResponse) Get() interface{} {
+	return r.Request
+}
+
+func (r FormattableDryRunResponse) GetHttpResponse() *nethttp.Response {
+	return r.httpResponse
+}
+
+func (r FormattableDryRunResponse) GetFetchError() error {
+	return r.err
+}
+
+func (r FormattableDryRunResponse) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s %s\n", r.Request.Method, r.Request.Path))
+	headers := lo.Keys(r.Request.Headers)
+	sort.Strings(headers)
+	for _, header := range headers {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", header, r.Request.Headers[header]))
+	}
+	body, err := json.MarshalIndent(r.Request.Body, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("%s\n%v", sb.String(), r.Request.Body)
+	}
+	sb.WriteString("\n")
+	sb.Write(body)
+	return sb.String()
+}
@@ -12,4 +12,16 @@ var (
 	ErrDeploymentStatusResponseParse        = errors.New("error trying to parse response")
 	ErrDeploymentStatusRequest              = errors.New("request returned an error")
 	ErrApplicationNameOverrideNotSupported  = errors.New("application name override not supported when using a URL as your deployment configuration file")
+	ErrNoMatchingStep                       = errors.New("no step found to act on")
+	ErrAmbiguousStep                        = errors.New("more than one step matches, use --target or --step to select one")
+	ErrValuesNotSupported                   = errors.New("values cannot be substituted when using a URL or a pipelineId as your deployment configuration")
+	ErrImagesNotSupported                   = errors.New("images can only be overridden in a local deployment file or when redeploying a pipelineId")
+	ErrPoliciesNotSupported                 = errors.New("policies can only be checked for a local deployment file or when redeploying a pipelineId with --image")
+	ErrDryRunWithWatch                      = errors.New("--watch cannot be used with --dry-run because no deployment is started")
+	ErrDryRunNoRequests                     = errors.New("no requests are sent in a dry run")
+	ErrWatchTimeout                         = errors.New("timed out watching the deployment")
+	ErrInvalidWatchInterval                 = errors.New("--watch-interval must be greater than 0")
+	ErrInvalidListTime                      = errors.New("invalid time, expected an RFC3339 timestamp or a duration such as 24h")
+	ErrInvalidListLimit                     = errors.New("--limit must be at least 1")
+	ErrInvalidListPage                      = errors.New("--page must be at least 1")
 )
@@ -0,0 +1,216 @@
+package deploy
+
+import (
+	"context"
+	"github.com/armory/armory-cli/cmd/utils"
+	"github.com/armory/armory-cli/internal/graphql"
+	"github.com/armory/armory-cli/pkg/cmdUtils"
+	"github.com/armory/armory-cli/pkg/config"
+	errorUtils "github.com/armory/armory-cli/pkg/errors"
+	"github.com/armory/armory-cli/pkg/output"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	log "go.uber.org/zap"
+	nethttp "net/http"
+	"strings"
+	"time"
+)
+
+const (
+	deployListShort = "List recent deployments"
+	deployListLong  = "List recent deployments, most recently started first\n\n" +
+		"--since and --until accept either an RFC3339 timestamp or a duration relative to now, e.g. 24h"
+	deployListExample = "armory deploy list --application my-app --status RUNNING --since 24h -o csv"
+
+	defaultDeployListLimit = 25
+)
+
+type (
+	deployListOptions struct {
+		application string
+		statuses    []string
+		since       string
+		until       string
+		startedBy   string
+		limit       int
+		page        int
+
+		lister pipelineLister
+	}
+
+	pipelineLister interface {
+		ListPipelines(ctx context.Context, filter graphql.ListPipelinesFilter) ([]graphql.Pipeline, error)
+	}
+
+	deployListItem struct {
+		DeploymentID string     `json:"deploymentId" yaml:"deploymentId"`
+		Application  string     `json:"application" yaml:"application"`
+		Status       string     `json:"status" yaml:"status"`
+		StartedAt    *time.Time `json:"startedAt,omitempty" yaml:"startedAt,omitempty"`
+		CompletedAt  *time.Time `json:"completedAt,omitempty" yaml:"completedAt,omitempty"`
+		StartedBy    string     `json:"startedBy,omitempty" yaml:"startedBy,omitempty"`
+	}
+
+	FormattableDeployListResponse struct {
+		Deployments  []deployListItem
+		httpResponse *nethttp.Response
+		err          error
+	}
+)
+
+func newDeployListResponse(pipelines []graphql.Pipeline) FormattableDeployListResponse {
+	return FormattableDeployListResponse{
+		Deployments: lo.Map(pipelines, func(pipeline graphql.Pipeline, _ int) deployListItem {
+			return deployListItem{
+				DeploymentID: pipeline.ID,
+				Application:  pipeline.Application.Name,
+				Status:       pipeline.Status,
+				StartedAt:    pipeline.StartedAt,
+				CompletedAt:  pipeline.CompletedAt,
+				StartedBy:    pipeline.InitiatedBy,
+			}
+		}),
+	}
+}
+
+func (u FormattableDeployListResponse) Get() interface{} {
+	return u.Deployments
+}
+
+func (u FormattableDeployListResponse) GetHttpResponse() *nethttp.Response {
+	return u.httpResponse
+}
+
+func (u FormattableDeployListResponse) GetFetchError() error {
+	return u.err
+}
+
+func (u FormattableDeployListResponse) Header() []string {
+	return []string{"DEPLOYMENT ID", "APPLICATION", "STATUS", "STARTED", "COMPLETED", "STARTED BY"}
+}
+
+func (u FormattableDeployListResponse) Rows() [][]string {
+	return lo.Map(u.Deployments, func(item deployListItem, _ int) []string {
+		return []string{
+			item.DeploymentID,
+			item.Application,
+			item.Status,
+			formatListTime(item.StartedAt),
+			formatListTime(item.CompletedAt),
+			item.StartedBy,
+		}
+	})
+}
+
+func (u FormattableDeployListResponse) String() string {
+	if len(u.Deployments) == 0 {
+		return "No deployments found"
+	}
+	return output.FormatTable(u)
+}
+
+func NewDeployListCmd(configuration *config.Configuration) *cobra.Command {
+	options := &deployListOptions{}
+	cmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   deployListShort,
+		Long:    deployListLong,
+		Example: deployListExample,
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			cmdUtils.ExecuteParentHooks(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if options.lister == nil {
+				options.lister = graphql.NewClient(configuration)
+			}
+			return list(cmd, configuration, options)
+		},
+	}
+	cmd.Flags().StringVarP(&options.application, "application", "n", "", "only list deployments of this application")
+	cmd.Flags().StringArrayVar(&options.statuses, "status", []string{}, "only list deployments with this status, e.g. RUNNING. Can be repeated")
+	cmd.Flags().StringVar(&options.since, "since", "", "only list deployments started at or after this time, as an RFC3339 timestamp or a duration such as 24h")
+	cmd.Flags().StringVar(&options.until, "until", "", "only list deployments started at or before this time, as an RFC3339 timestamp or a duration such as 1h")
+	cmd.Flags().StringVar(&options.startedBy, "startedBy", "", "only list deployments started by this user or client")
+	cmd.Flags().IntVar(&options.limit, "limit", defaultDeployListLimit, "maximum number of deployments to list")
+	cmd.Flags().IntVar(&options.page, "page", 1, "page of results to list, starting at 1")
+	return cmd
+}
+
+func list(cmd *cobra.Command, configuration *config.Configuration, options *deployListOptions) error {
+	if *configuration.GetIsTest() {
+		utils.ConfigureLoggingForTesting(cmd)
+	}
+
+	filter, err := options.filter(configuration.Now())
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	// if we've made it this far, the command is valid. if an error occurs it isn't a usage error
+	cmd.SilenceUsage = true
+	pipelines, err := options.lister.ListPipelines(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	dataFormat, err := configuration.GetOutputFormatter()(newDeployListResponse(pipelines))
+	if err != nil {
+		return err
+	}
+	log.S().Info(dataFormat)
+	return nil
+}
+
+func (o *deployListOptions) filter(now time.Time) (graphql.ListPipelinesFilter, error) {
+	if o.limit < 1 {
+		return graphql.ListPipelinesFilter{}, ErrInvalidListLimit
+	}
+	if o.page < 1 {
+		return graphql.ListPipelinesFilter{}, ErrInvalidListPage
+	}
+
+	since, err := parseListTime(o.since, now)
+	if err != nil {
+		return graphql.ListPipelinesFilter{}, errorUtils.NewWrappedErrorWithDynamicContext(ErrInvalidListTime, err, " for --since")
+	}
+	until, err := parseListTime(o.until, now)
+	if err != nil {
+		return graphql.ListPipelinesFilter{}, errorUtils.NewWrappedErrorWithDynamicContext(ErrInvalidListTime, err, " for --until")
+	}
+
+	return graphql.ListPipelinesFilter{
+		Application:   o.application,
+		Statuses:      lo.Map(o.statuses, func(status string, _ int) string { return strings.ToUpper(status) }),
+		StartedAfter:  since,
+		StartedBefore: until,
+		InitiatedBy:   o.startedBy,
+		Limit:         o.limit,
+		Offset:        (o.page - 1) * o.limit,
+	}, nil
+}
+
+// parseListTime accepts either an RFC3339 timestamp or a duration, which is subtracted from now.
+func parseListTime(value string, now time.Time) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if duration, err := time.ParseDuration(value); err == nil {
+		return lo.ToPtr(now.Add(-duration)), nil
+	}
+	timestamp, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &timestamp, nil
+}
+
+func formatListTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,145 @@
+package deploy
+
+import (
+	"bytes"
+	"context"
+	"github.com/armory/armory-cli/internal/graphql"
+	"github.com/armory/armory-cli/pkg/config"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type fakePipelineLister struct {
+	filter    graphql.ListPipelinesFilter
+	pipelines []graphql.Pipeline
+}
+
+func (f *fakePipelineLister) ListPipelines(_ context.Context, filter graphql.ListPipelinesFilter) ([]graphql.Pipeline, error) {
+	f.filter = filter
+	return f.pipelines, nil
+}
+
+func TestDeployList(t *testing.T) {
+	started := time.Date(2023, 4, 2, 10, 0, 0, 0, time.UTC)
+	completed := started.Add(5 * time.Minute)
+	pipelines := []graphql.Pipeline{
+		{
+			ID:          "pipeline-1",
+			Status:      "SUCCEEDED",
+			Application: graphql.Application{Name: "my-app"},
+			StartedAt:   &started,
+			CompletedAt: &completed,
+			InitiatedBy: "someone@armory.io",
+		},
+		{
+			ID:          "pipeline-2",
+			Status:      "RUNNING",
+			Application: graphql.Application{Name: "my-app"},
+			StartedAt:   &started,
+		},
+	}
+
+	cases := []struct {
+		name              string
+		outFormat         string
+		options           deployListOptions
+		expectedFilter    graphql.ListPipelinesFilter
+		expected          string
+		expectErrContains string
+	}{
+		{
+			name:      "csv with filters",
+			outFormat: "csv",
+			options: deployListOptions{
+				application: "my-app",
+				statuses:    []string{"running", "SUCCEEDED"},
+				since:       "2023-04-01T00:00:00Z",
+				startedBy:   "someone@armory.io",
+				limit:       10,
+				page:        3,
+			},
+			expectedFilter: graphql.ListPipelinesFilter{
+				Application:  "my-app",
+				Statuses:     []string{"RUNNING", "SUCCEEDED"},
+				StartedAfter: lo.ToPtr(time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC)),
+				InitiatedBy:  "someone@armory.io",
+				Limit:        10,
+				Offset:       20,
+			},
+			expected: "DEPLOYMENT ID,APPLICATION,STATUS,STARTED,COMPLETED,STARTED BY\n" +
+				"pipeline-1,my-app,SUCCEEDED,2023-04-02T10:00:00Z,2023-04-02T10:05:00Z,someone@armory.io\n" +
+				"pipeline-2,my-app,RUNNING,2023-04-02T10:00:00Z,,\n",
+		},
+		{
+			name:      "relative time window",
+			outFormat: "json",
+			options:   deployListOptions{since: "24h", until: "1h", limit: 25, page: 1},
+			expectedFilter: graphql.ListPipelinesFilter{
+				StartedAfter:  lo.ToPtr(time.Time{}.Add(-24 * time.Hour)),
+				StartedBefore: lo.ToPtr(time.Time{}.Add(-time.Hour)),
+				Statuses:      []string{},
+				Limit:         25,
+			},
+			expected: `[
+  {
+    "deploymentId": "pipeline-1",
+    "application": "my-app",
+    "status": "SUCCEEDED",
+    "startedAt": "2023-04-02T10:00:00Z",
+    "completedAt": "2023-04-02T10:05:00Z",
+    "startedBy": "someone@armory.io"
+  },
+  {
+    "deploymentId": "pipeline-2",
+    "application": "my-app",
+    "status": "RUNNING",
+    "startedAt": "2023-04-02T10:00:00Z"
+  }
+]
+`,
+		},
+		{
+			name:              "invalid time",
+			outFormat:         "text",
+			options:           deployListOptions{since: "yesterday", limit: 25, page: 1},
+			expectErrContains: "invalid time",
+		},
+		{
+			name:              "invalid page",
+			outFormat:         "text",
+			options:           deployListOptions{limit: 25, page: 0},
+			expectErrContains: "--page must be at least 1",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			lister := &fakePipelineLister{pipelines: pipelines}
+			options := c.options
+			options.lister = lister
+
+			cmd := &cobra.Command{}
+			writer := bytes.NewBufferString("")
+			cmd.SetOut(writer)
+
+			err := list(cmd, config.New(&config.Input{
+				AccessToken:  lo.ToPtr("some-token"),
+				ApiAddr:      lo.ToPtr("https://localhost"),
+				ClientId:     lo.ToPtr(""),
+				ClientSecret: lo.ToPtr(""),
+				OutFormat:    lo.ToPtr(c.outFormat),
+				IsTest:       lo.ToPtr(true),
+			}), &options)
+			if c.expectErrContains != "" {
+				assert.ErrorContains(t, err, c.expectErrContains)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, c.expectedFilter, lister.filter)
+			assert.Equal(t, c.expected, writer.String())
+		})
+	}
+}
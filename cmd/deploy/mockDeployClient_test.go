@@ -44,6 +44,10 @@ func (c *MockDeployClient) StartPipeline(ctx context.Context, options deploy.Sta
 	return nil, nil, nil
 }
 
+func (c *MockDeployClient) PipelineConfig(ctx context.Context, pipelineID string) (map[string]any, *http.Response, error) {
+	return nil, nil, fmt.Errorf("not implemented")
+}
+
 func (c *MockDeployClient) GetArmoryCloudClient() *armoryCloud.Client {
 	return c.ArmoryCloudClient
 }
@@ -0,0 +1,151 @@
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"github.com/armory/armory-cli/cmd/utils"
+	"github.com/armory/armory-cli/pkg/cmdUtils"
+	"github.com/armory/armory-cli/pkg/config"
+	deployment "github.com/armory/armory-cli/pkg/deploy"
+	"github.com/spf13/cobra"
+	log "go.uber.org/zap"
+	nethttp "net/http"
+	"strings"
+	"time"
+)
+
+const (
+	deployCancelShort   = "Cancel a running deployment"
+	deployCancelLong    = "Cancel a running deployment. Targets that are being deployed when the deployment is cancelled are rolled back"
+	deployCancelExample = "armory deploy cancel --deploymentId [deploymentId]"
+
+	deployRollbackShort = "Roll back a deployment"
+	deployRollbackLong  = "Roll back a deployment\n\n" +
+		"By default every target of the deployment is rolled back. Use --target to roll back specific targets only"
+	deployRollbackExample = "armory deploy rollback --deploymentId [deploymentId] [--target <target name>]"
+
+	pipelineActionCancel   = "cancel"
+	pipelineActionRollback = "rollback"
+)
+
+type FormattablePipelineActionResponse struct {
+	DeploymentId string   `json:"deploymentId,omitempty" yaml:"deploymentId,omitempty"`
+	Action       string   `json:"action" yaml:"action"`
+	Targets      []string `json:"targets,omitempty" yaml:"targets,omitempty"`
+	requestedAt  time.Time
+	httpResponse *nethttp.Response
+	err          error
+}
+
+func (u FormattablePipelineActionResponse) Get() interface{} {
+	return u
+}
+
+func (u FormattablePipelineActionResponse) GetHttpResponse() *nethttp.Response {
+	return u.httpResponse
+}
+
+func (u FormattablePipelineActionResponse) GetFetchError() error {
+	return u.err
+}
+
+func (u FormattablePipelineActionResponse) String() string {
+	switch {
+	case u.Action == pipelineActionCancel:
+		return fmt.Sprintf("[%v] Requested cancellation of deployment %s", u.requestedAt.Format(time.RFC3339), u.DeploymentId)
+	case len(u.Targets) > 0:
+		return fmt.Sprintf("[%v] Requested rollback of targets %s in deployment %s", u.requestedAt.Format(time.RFC3339), strings.Join(u.Targets, ", "), u.DeploymentId)
+	default:
+		return fmt.Sprintf("[%v] Requested rollback of all targets in deployment %s", u.requestedAt.Format(time.RFC3339), u.DeploymentId)
+	}
+}
+
+func NewDeployCancelCmd(configuration *config.Configuration) *cobra.Command {
+	deploymentID := ""
+	cmd := &cobra.Command{
+		Use:     "cancel --deploymentId [deploymentId]",
+		Short:   deployCancelShort,
+		Long:    deployCancelLong,
+		Example: deployCancelExample,
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			cmdUtils.ExecuteParentHooks(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return performPipelineAction(cmd, configuration, deploymentID, pipelineActionCancel, nil,
+				func(ctx context.Context, client *deployment.Client) (*nethttp.Response, error) {
+					return client.CancelPipeline(ctx, deploymentID)
+				})
+		},
+	}
+	addDeploymentIDFlag(cmd, &deploymentID)
+	return cmd
+}
+
+func NewDeployRollbackCmd(configuration *config.Configuration) *cobra.Command {
+	deploymentID := ""
+	var targets []string
+	cmd := &cobra.Command{
+		Use:     "rollback --deploymentId [deploymentId]",
+		Short:   deployRollbackShort,
+		Long:    deployRollbackLong,
+		Example: deployRollbackExample,
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			cmdUtils.ExecuteParentHooks(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return performPipelineAction(cmd, configuration, deploymentID, pipelineActionRollback, targets,
+				func(ctx context.Context, client *deployment.Client) (*nethttp.Response, error) {
+					return client.RollbackPipeline(ctx, deploymentID, targets)
+				})
+		},
+	}
+	addDeploymentIDFlag(cmd, &deploymentID)
+	cmd.Flags().StringArrayVarP(&targets, "target", "t", []string{}, "target to roll back, can be repeated. All targets are rolled back if not specified")
+	return cmd
+}
+
+func addDeploymentIDFlag(cmd *cobra.Command, deploymentID *string) {
+	cmd.Flags().StringVarP(deploymentID, "deploymentId", "i", "", "(Required) The ID of an existing deployment.\n"+
+		"You can find the deploymentId by navigating to the deployment status page and looking in the URL: \n"+
+		"https://console.cloud.armory.io/deployments/pipeline/<deploymentId>")
+	cmd.MarkFlagRequired("deploymentId")
+}
+
+func performPipelineAction(
+	cmd *cobra.Command,
+	configuration *config.Configuration,
+	deploymentID string,
+	action string,
+	targets []string,
+	perform func(ctx context.Context, client *deployment.Client) (*nethttp.Response, error),
+) error {
+	if *configuration.GetIsTest() {
+		utils.ConfigureLoggingForTesting(cmd)
+	}
+
+	storeCommandResult(cmd, DeployResultDeploymentID, deploymentID)
+
+	deployClient := deployment.NewClient(configuration)
+	ctx, cancel := context.WithTimeout(deployClient.ArmoryCloudClient.Context, time.Minute)
+	defer cancel()
+
+	// if we've made it this far, the command is valid. if an error occurs it isn't a usage error
+	cmd.SilenceUsage = true
+	response, err := perform(ctx, deployClient)
+	if err != nil {
+		return err
+	}
+
+	dataFormat, err := configuration.GetOutputFormatter()(FormattablePipelineActionResponse{
+		DeploymentId: deploymentID,
+		Action:       action,
+		Targets:      targets,
+		requestedAt:  configuration.Now(),
+		httpResponse: response,
+	})
+	if err != nil {
+		return err
+	}
+	log.S().Info(dataFormat)
+	return nil
+}
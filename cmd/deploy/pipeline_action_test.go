@@ -0,0 +1,136 @@
+package deploy
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/armory/armory-cli/internal/clierr"
+	"github.com/armory/armory-cli/internal/clierr/exitcodes"
+	"github.com/armory/armory-cli/pkg/config"
+	"github.com/jarcoal/httpmock"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestDeployPipelineActions(t *testing.T) {
+	cases := []struct {
+		name              string
+		args              []string
+		path              string
+		status            int
+		expectedBody      string
+		expected          FormattablePipelineActionResponse
+		expectErrContains string
+		expectExitCode    exitcodes.ExitCode
+	}{
+		{
+			name:     "cancel",
+			args:     []string{"cancel", "--deploymentId=12345"},
+			path:     "/pipelines/12345/cancel",
+			status:   http.StatusAccepted,
+			expected: FormattablePipelineActionResponse{DeploymentId: "12345", Action: "cancel"},
+		},
+		{
+			name:         "rollback every target",
+			args:         []string{"rollback", "--deploymentId=12345"},
+			path:         "/pipelines/12345/rollback",
+			status:       http.StatusAccepted,
+			expectedBody: `{}`,
+			expected:     FormattablePipelineActionResponse{DeploymentId: "12345", Action: "rollback"},
+		},
+		{
+			name:         "rollback selected targets",
+			args:         []string{"rollback", "--deploymentId=12345", "-t", "prod-east", "-t", "prod-west"},
+			path:         "/pipelines/12345/rollback",
+			status:       http.StatusAccepted,
+			expectedBody: `{"targets":["prod-east","prod-west"]}`,
+			expected:     FormattablePipelineActionResponse{DeploymentId: "12345", Action: "rollback", Targets: []string{"prod-east", "prod-west"}},
+		},
+		{
+			name:              "cancel a deployment that already finished",
+			args:              []string{"cancel", "--deploymentId=12345"},
+			path:              "/pipelines/12345/cancel",
+			status:            http.StatusConflict,
+			expectErrContains: "Failed to cancel deployment, status code: 409",
+			expectExitCode:    exitcodes.Conflict,
+		},
+		{
+			name:              "deployment id is required",
+			args:              []string{"rollback"},
+			expectErrContains: "required flag(s) \"deploymentId\" not set",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			httpmock.Activate()
+			defer httpmock.DeactivateAndReset()
+
+			var receivedBody []byte
+			if c.path != "" {
+				httpmock.RegisterResponder("POST", "https://localhost"+c.path, func(request *http.Request) (*http.Response, error) {
+					if request.Body != nil {
+						receivedBody, _ = io.ReadAll(request.Body)
+					}
+					return httpmock.NewJsonResponse(c.status, clierr.ApiErrorResponse{
+						ErrorID: "249fdf16-e97b-4507-bf85-df9ec66f2b87",
+						Errors:  []clierr.ApiErrorDTO{{Message: "deployment is already complete"}},
+					})
+				})
+			}
+
+			cmd := NewDeployCmd(config.New(&config.Input{
+				AccessToken:  lo.ToPtr("some-token"),
+				ApiAddr:      lo.ToPtr("https://localhost"),
+				ClientId:     lo.ToPtr(""),
+				ClientSecret: lo.ToPtr(""),
+				OutFormat:    lo.ToPtr("json"),
+				IsTest:       lo.ToPtr(true),
+			}))
+			writer := bytes.NewBufferString("")
+			cmd.SetOut(writer)
+			cmd.SetArgs(c.args)
+
+			err := cmd.Execute()
+			if c.expectErrContains != "" {
+				assert.ErrorContains(t, err, c.expectErrContains)
+				if c.expectExitCode != 0 {
+					var apiError *clierr.APIError
+					assert.ErrorAs(t, err, &apiError)
+					assert.Equal(t, int(c.expectExitCode), apiError.ExitCode())
+				}
+				return
+			}
+			assert.NoError(t, err)
+			if c.expectedBody != "" {
+				assert.JSONEq(t, c.expectedBody, string(receivedBody))
+			}
+			var received FormattablePipelineActionResponse
+			assert.NoError(t, json.Unmarshal(writer.Bytes(), &received))
+			assert.Equal(t, c.expected, received)
+		})
+	}
+}
+
+func TestDeployPipelineActionTextOutputUsesConfigurationClock(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("POST", "https://localhost/pipelines/12345/cancel", httpmock.NewStringResponder(http.StatusAccepted, ""))
+
+	cmd := NewDeployCmd(config.New(&config.Input{
+		AccessToken:  lo.ToPtr("some-token"),
+		ApiAddr:      lo.ToPtr("https://localhost"),
+		ClientId:     lo.ToPtr(""),
+		ClientSecret: lo.ToPtr(""),
+		OutFormat:    lo.ToPtr("text"),
+		IsTest:       lo.ToPtr(true),
+	}))
+	writer := bytes.NewBufferString("")
+	cmd.SetOut(writer)
+	cmd.SetArgs([]string{"cancel", "--deploymentId=12345"})
+	assert.NoError(t, cmd.Execute())
+	assert.True(t, strings.HasPrefix(writer.String(), "[0001-01-01T00:00:00Z] Requested cancellation of deployment 12345\n"), writer.String())
+}
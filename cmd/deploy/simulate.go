@@ -0,0 +1,159 @@
+package deploy
+
+import (
+	"fmt"
+	"github.com/armory/armory-cli/cmd/utils"
+	"github.com/armory/armory-cli/cmd/validate"
+	"github.com/armory/armory-cli/pkg/cmdUtils"
+	"github.com/armory/armory-cli/pkg/config"
+	deployment "github.com/armory/armory-cli/pkg/deploy"
+	errorUtils "github.com/armory/armory-cli/pkg/errors"
+	"github.com/armory/armory-cli/pkg/output"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	log "go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+	nethttp "net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	deploySimulateShort = "Show how the strategies of a deployment file would play out"
+	deploySimulateLong  = "Show how the strategies of a deployment file would play out, without starting a deployment\n\n" +
+		"The targets are walked in dependency order and their steps are laid out on a timeline, with the share of " +
+		"traffic sent to the new version after each step of the strategy. Pauses last their duration and analysis " +
+		"lasts its interval times its number of judgment runs, while webhooks and manual gates are assumed to take no " +
+		"time, so the total is the minimum duration of the deployment. Manual gates are listed at the end"
+	deploySimulateExample = "armory deploy simulate -f deploy.yml"
+)
+
+type (
+	deploySimulateOptions struct {
+		deploymentFile string
+		valuesFiles    []string
+		setValues      []string
+		strictValues   bool
+	}
+
+	FormattableSimulation struct {
+		Simulation   *deployment.Simulation
+		httpResponse *nethttp.Response
+		err          error
+	}
+)
+
+func NewDeploySimulateCmd(configuration *config.Configuration) *cobra.Command {
+	options := &deploySimulateOptions{}
+	cmd := &cobra.Command{
+		Use:     "simulate --file [<path to file>]",
+		Short:   deploySimulateShort,
+		Long:    deploySimulateLong,
+		Example: deploySimulateExample,
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			cmdUtils.ExecuteParentHooks(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return simulate(cmd, configuration, options)
+		},
+	}
+	cmd.Flags().StringVarP(&options.deploymentFile, "file", "f", "", "path to the deployment file")
+	cmd.Flags().StringArrayVar(&options.valuesFiles, "values", []string{}, "YAML file of values to substitute for ${key} and {{ .key }} placeholders in the deployment file and manifests. Can be repeated, later files take precedence")
+	cmd.Flags().StringArrayVar(&options.setValues, "set", []string{}, "set a value to substitute, as key=value. Nested keys are separated by dots. Takes precedence over --values")
+	cmd.Flags().BoolVar(&options.strictValues, "strict", false, "fail if a placeholder in the deployment file or manifests has no value")
+	cmd.MarkFlagRequired("file")
+	return cmd
+}
+
+func simulate(cmd *cobra.Command, configuration *config.Configuration, options *deploySimulateOptions) error {
+	if *configuration.GetIsTest() {
+		utils.ConfigureLoggingForTesting(cmd)
+	}
+	var values *deployment.Values
+	if len(options.valuesFiles) > 0 || len(options.setValues) > 0 || options.strictValues {
+		var err error
+		if values, err = deployment.NewValues(options.valuesFiles, options.setValues, options.strictValues); err != nil {
+			return err
+		}
+	}
+	file, err := os.ReadFile(options.deploymentFile)
+	if err != nil {
+		return errorUtils.NewWrappedError(ErrYAMLFileRead, err)
+	}
+	// the file is simulated as deploy start would send it, once the values are substituted
+	if file, err = values.Render(options.deploymentFile, file); err != nil {
+		return err
+	}
+	validationFailures, err := validate.Validate(file, values)
+	if err != nil {
+		return errorUtils.NewWrappedError(ErrInvalidDeploymentObject, err)
+	}
+	validate.LogValidationErrors(cmd.OutOrStdout(), validationFailures, false)
+
+	// if we've made it this far, the command is valid. if an error occurs it isn't a usage error
+	cmd.SilenceUsage = true
+	var payload map[string]any
+	if err = yaml.Unmarshal(file, &payload); err != nil {
+		return errorUtils.NewWrappedError(ErrInvalidDeploymentObject, err)
+	}
+	simulation, err := deployment.Simulate(payload)
+	if err != nil {
+		return err
+	}
+	dataFormat, err := configuration.GetOutputFormatter()(FormattableSimulation{Simulation: simulation})
+	if err != nil {
+		return err
+	}
+	log.S().Info(dataFormat)
+	return nil
+}
+
+func (r FormattableSimulation) Get() interface{} {
+	return r.Simulation
+}
+
+func (r FormattableSimulation) GetHttpResponse() *nethttp.Response {
+	return r.httpResponse
+}
+
+func (r FormattableSimulation) GetFetchError() error {
+	return r.err
+}
+
+func (r FormattableSimulation) Header() []string {
+	return []string{"TARGET", "AT", "PHASE", "STEP", "DURATION", "WEIGHT"}
+}
+
+func (r FormattableSimulation) Rows() [][]string {
+	return lo.FlatMap(r.Simulation.Targets, func(target deployment.TargetTimeline, _ int) [][]string {
+		return lo.Map(target.Events, func(event deployment.TimelineEvent, _ int) []string {
+			return []string{
+				target.Target,
+				event.Offset.String(),
+				event.Phase,
+				event.Description + lo.Ternary(event.Manual, " (manual)", ""),
+				lo.Ternary(event.Duration > 0, event.Duration.String(), ""),
+				lo.TernaryF(event.Weight != nil, func() string { return strconv.Itoa(*event.Weight) + "%" }, func() string { return "" }),
+			}
+		})
+	})
+}
+
+func (r FormattableSimulation) String() string {
+	if len(r.Simulation.Targets) == 0 {
+		return "No targets to deploy"
+	}
+	var sb strings.Builder
+	sb.WriteString(output.FormatTable(r))
+	sb.WriteString(fmt.Sprintf("\n\nMinimum duration: %s\n", r.Simulation.MinimumDuration))
+	if len(r.Simulation.ManualGates) == 0 {
+		sb.WriteString("No manual gates")
+		return sb.String()
+	}
+	sb.WriteString("Manual gates:")
+	for _, gate := range r.Simulation.ManualGates {
+		sb.WriteString(fmt.Sprintf("\n  %s, %s: %s", gate.Target, gate.Phase, gate.Description))
+	}
+	return sb.String()
+}
@@ -0,0 +1,113 @@
+package deploy
+
+import (
+	"bytes"
+	"github.com/armory/armory-cli/pkg/config"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDeploySimulate(t *testing.T) {
+	deploymentFile := filepath.Join(t.TempDir(), "deploy.yml")
+	assert.NoError(t, os.WriteFile(deploymentFile, []byte(`version: v1
+kind: kubernetes
+application: potato-facts
+targets:
+  staging:
+    account: staging
+    strategy: canary
+  prod:
+    account: prod
+    strategy: canary
+    constraints:
+      dependsOn: [staging]
+      beforeDeployment:
+        - pause:
+            untilApproved: true
+manifests:
+  - inline: |
+      apiVersion: apps/v1
+      kind: Deployment
+      metadata:
+        name: potato-facts
+strategies:
+  canary:
+    canary:
+      steps:
+        - setWeight:
+            weight: 50
+        - pause:
+            duration: 1
+            unit: minutes
+        - setWeight:
+            weight: 100
+`), 0o644))
+
+	cmd := &cobra.Command{}
+	writer := bytes.NewBufferString("")
+	cmd.SetOut(writer)
+	err := simulate(cmd, config.New(&config.Input{
+		AccessToken:  lo.ToPtr("some-token"),
+		ApiAddr:      lo.ToPtr("https://localhost"),
+		ClientId:     lo.ToPtr(""),
+		ClientSecret: lo.ToPtr(""),
+		OutFormat:    lo.ToPtr("text"),
+		IsTest:       lo.ToPtr(true),
+	}), &deploySimulateOptions{deploymentFile: deploymentFile})
+	assert.NoError(t, err)
+	assert.Equal(t, `TARGET    AT     PHASE               STEP                         DURATION   WEIGHT
+staging   0s     strategy            set weight 50%                          50%
+staging   0s     strategy            pause 1 minutes              1m0s       50%
+staging   1m0s   strategy            set weight 100%                         100%
+prod      1m0s   before deployment   wait for approval (manual)              
+prod      1m0s   strategy            set weight 50%                          50%
+prod      1m0s   strategy            pause 1 minutes              1m0s       50%
+prod      2m0s   strategy            set weight 100%                         100%
+
+Minimum duration: 2m0s
+Manual gates:
+  prod, before deployment: wait for approval
+`, writer.String())
+}
+
+func TestDeploySimulateWithValues(t *testing.T) {
+	deploymentFile := filepath.Join(t.TempDir(), "deploy.yml")
+	assert.NoError(t, os.WriteFile(deploymentFile, []byte(`version: v1
+kind: kubernetes
+application: potato-facts
+targets:
+  staging:
+    account: staging
+    strategy: canary
+manifests:
+  - inline: |
+      apiVersion: apps/v1
+      kind: Deployment
+      metadata:
+        name: potato-facts
+strategies:
+  canary:
+    canary:
+      steps:
+        - setWeight:
+            weight: ${weight}
+`), 0o644))
+
+	cmd := &cobra.Command{}
+	writer := bytes.NewBufferString("")
+	cmd.SetOut(writer)
+	err := simulate(cmd, config.New(&config.Input{
+		AccessToken:  lo.ToPtr("some-token"),
+		ApiAddr:      lo.ToPtr("https://localhost"),
+		ClientId:     lo.ToPtr(""),
+		ClientSecret: lo.ToPtr(""),
+		OutFormat:    lo.ToPtr("text"),
+		IsTest:       lo.ToPtr(true),
+	}), &deploySimulateOptions{deploymentFile: deploymentFile, setValues: []string{"weight=25"}})
+	assert.NoError(t, err)
+	assert.Contains(t, writer.String(), "staging   0s   strategy   set weight 25%")
+}
@@ -7,9 +7,9 @@ import (
 	"github.com/armory/armory-cli/internal/graphql"
 	"github.com/armory/armory-cli/pkg/console"
 	"gopkg.in/yaml.v3"
-	"io"
 	nethttp "net/http"
 	"os"
+	"strings"
 	"time"
 
 	de "github.com/armory-io/deploy-engine/pkg/api"
@@ -20,7 +20,8 @@ import (
 	"github.com/armory/armory-cli/pkg/config"
 	deployment "github.com/armory/armory-cli/pkg/deploy"
 	errorUtils "github.com/armory/armory-cli/pkg/errors"
-	"github.com/armory/armory-cli/pkg/output"
+	"github.com/armory/armory-cli/pkg/findings"
+	"github.com/armory/armory-cli/pkg/policy"
 	"github.com/samber/lo"
 	"github.com/spf13/cobra"
 	log "go.uber.org/zap"
@@ -48,6 +49,17 @@ type deployStartOptions struct {
 	withSCM           bool
 	withSCMFile       string
 	waitForCompletion bool
+	dryRun            bool
+	valuesFiles       []string
+	setValues         []string
+	strictValues      bool
+	values            *deployment.Values
+	images            []string
+	imageOverrides    *deployment.ImageOverrides
+	policyDir         string
+	policies          []policy.Policy
+	watchTimeout      time.Duration
+	watchInterval     time.Duration
 
 	waiter        waiter
 	waiterTimeout time.Duration
@@ -71,6 +83,7 @@ type ArmoryDeployClient interface {
 	PipelineStatus(ctx context.Context, pipelineID string) (*de.PipelineStatusResponse, *nethttp.Response, error)
 	DeploymentStatus(ctx context.Context, deploymentID string) (*de.DeploymentStatusResponse, *nethttp.Response, error)
 	StartPipeline(ctx context.Context, options deployment.StartPipelineOptions) (*de.StartPipelineResponse, *nethttp.Response, error)
+	PipelineConfig(ctx context.Context, pipelineID string) (map[string]any, *nethttp.Response, error)
 	GetArmoryCloudClient() *armoryCloud.Client
 }
 
@@ -132,7 +145,7 @@ func NewDeployStartCmd(configuration *config.Configuration) *cobra.Command {
 			cmdUtils.ExecuteParentHooks(cmd, args)
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if !*configuration.GetIsTest() {
+			if !*configuration.GetIsTest() && !options.dryRun {
 				options.waiter = NewWaiter(
 					graphql.NewClient(configuration),
 					configuration.GetArmoryCloudEnvironmentConfiguration().CloudConsoleBaseUrl,
@@ -142,7 +155,7 @@ func NewDeployStartCmd(configuration *config.Configuration) *cobra.Command {
 			return start(cmd, configuration, options)
 		},
 	}
-	cmd.Flags().StringVarP(&options.account, "account", "", "", "override the deployment YAML account field for each target when --file is a URL")
+	cmd.Flags().StringVarP(&options.account, "account", "", "", "override the deployment YAML account field for each target when --file is a URL or with --pipelineId")
 	cmd.Flags().StringVarP(&options.deploymentFile, "file", "f", "", "path to the deployment file")
 	cmd.Flags().StringVarP(&options.pipelineID, "pipelineId", "i", "", "the ID of a previously deployed pipeline. Request will automatically use the original deployment configuration for that pipeline including its manifests")
 	cmd.Flags().StringVarP(&options.application, "application", "n", "", "application name for deployment")
@@ -150,7 +163,14 @@ func NewDeployStartCmd(configuration *config.Configuration) *cobra.Command {
 	cmd.Flags().StringToStringVar(&options.context, "add-context", map[string]string{}, "add context values to be used in strategy steps")
 	cmd.Flags().BoolVar(&options.withSCM, "with-scm", false, "add source control context to be shown in ui")
 	cmd.Flags().StringVar(&options.withSCMFile, "with-scm-file", "", "add source control through a file path")
-	cmd.Flags().BoolVarP(&options.waitForCompletion, "watch", "w", false, "wait for deployment to complete")
+	cmd.Flags().BoolVarP(&options.waitForCompletion, "watch", "w", false, "wait for deployment to complete, reporting the progress of each target and step")
+	addWatchFlags(cmd, &options.watchInterval, &options.watchTimeout)
+	cmd.Flags().StringArrayVar(&options.valuesFiles, "values", []string{}, "YAML file of values to substitute for ${key} and {{ .key }} placeholders in the deployment file and manifests. Can be repeated, later files take precedence")
+	cmd.Flags().StringArrayVar(&options.setValues, "set", []string{}, "set a value to substitute, as key=value. Nested keys are separated by dots. Takes precedence over --values")
+	cmd.Flags().BoolVar(&options.strictValues, "strict", false, "fail if a placeholder in the deployment file or manifests has no value")
+	cmd.Flags().StringArrayVar(&options.images, "image", []string{}, "set the image of every container with this name in the deployed manifests, as container=image. Can be repeated")
+	cmd.Flags().StringVar(&options.policyDir, "policy", "", "directory of CUE policies the deployment must satisfy before it is started. A violation exits with code 4")
+	cmd.Flags().BoolVar(&options.dryRun, "dry-run", false, "validate the deployment and print the request that would start it, without sending it. No credentials are required, except with --pipelineId and --image, which fetch the configuration of the pipeline")
 
 	return cmd
 }
@@ -159,11 +179,30 @@ func start(cmd *cobra.Command, configuration *config.Configuration, options *dep
 	if options.deploymentFile == "" && options.pipelineID == "" {
 		return ErrConfigurationRequired
 	}
+	if options.waitForCompletion {
+		if err := validateWatchInterval(options.watchInterval); err != nil {
+			return err
+		}
+	}
 
 	if *configuration.GetIsTest() {
 		utils.ConfigureLoggingForTesting(cmd)
 	}
-	deployClient := deployment.NewClient(configuration)
+	var deployClient ArmoryDeployClient
+	var dryRunClient *dryRunDeployClient
+	if options.dryRun {
+		if options.waitForCompletion {
+			return ErrDryRunWithWatch
+		}
+		dryRunClient = &dryRunDeployClient{}
+		if options.pipelineID != "" && len(options.images) > 0 {
+			// the images are overridden in the configuration of the pipeline, which has to be fetched
+			dryRunClient.configClient = deployment.NewClient(configuration)
+		}
+		deployClient = dryRunClient
+	} else {
+		deployClient = deployment.NewClient(configuration)
+	}
 	var startResp *de.StartPipelineResponse
 	var rawResp *nethttp.Response
 	var err error
@@ -173,8 +212,29 @@ func start(cmd *cobra.Command, configuration *config.Configuration, options *dep
 		return ErrTwoDeploymentConfigurationsSpecified
 	}
 
+	if len(options.valuesFiles) > 0 || len(options.setValues) > 0 || options.strictValues {
+		if options.values, err = deployment.NewValues(options.valuesFiles, options.setValues, options.strictValues); err != nil {
+			return err
+		}
+	}
+
+	if len(options.images) > 0 {
+		if options.imageOverrides, err = deployment.NewImageOverrides(options.images); err != nil {
+			return err
+		}
+	}
+
+	if options.policyDir != "" {
+		if options.policies, err = policy.Load(options.policyDir); err != nil {
+			return err
+		}
+	}
+
 	var withConfiguration WithDeployConfiguration
-	if options.pipelineID != "" {
+	if options.pipelineID != "" && options.imageOverrides != nil {
+		// the original manifests have to be fetched and rewritten here, the server only redeploys them as they were
+		withConfiguration = WithPipelineConfig
+	} else if options.pipelineID != "" {
 		options.deploymentFile =
 			fmt.Sprintf("armory::%s/pipelines/%s/config", configuration.GetArmoryCloudAddr().String(), options.pipelineID)
 		withConfiguration = WithURL
@@ -197,6 +257,10 @@ func start(cmd *cobra.Command, configuration *config.Configuration, options *dep
 	if err != nil {
 		return err
 	}
+	reportImageChanges(options.imageOverrides)
+	if dryRunClient != nil {
+		return outputDryRunResult(dryRunClient.request, configuration)
+	}
 	// create response object
 	deploy := newDeployStartResponse(startResp, rawResp, err)
 	storeCommandResult(cmd, DeployResultDeploymentID, deploy.DeploymentId)
@@ -215,7 +279,7 @@ func start(cmd *cobra.Command, configuration *config.Configuration, options *dep
 	}
 
 	if options.waitForCompletion && err == nil {
-		beginTrackingDeployment(cmd, configuration, &deploy, deployClient)
+		return trackDeployment(cmd, configuration, deploy, deployClient, options.watchInterval, options.watchTimeout)
 	}
 	// format response
 	return outputCommandResult(deploy, configuration)
@@ -225,6 +289,15 @@ func WithURL(cmd *cobra.Command, options *deployStartOptions, scmc de.SCM, deplo
 	if options.application != "" {
 		return nil, nil, ErrApplicationNameOverrideNotSupported
 	}
+	if options.values != nil {
+		return nil, nil, ErrValuesNotSupported
+	}
+	if options.imageOverrides != nil {
+		return nil, nil, ErrImagesNotSupported
+	}
+	if options.policies != nil {
+		return nil, nil, ErrPoliciesNotSupported
+	}
 	cmd.SilenceUsage = true
 	ctx, cancel := context.WithTimeout(deployClient.GetArmoryCloudClient().Context, time.Minute)
 	defer cancel()
@@ -247,6 +320,63 @@ func WithURL(cmd *cobra.Command, options *deployStartOptions, scmc de.SCM, deplo
 	return raw, response, err
 }
 
+// WithPipelineConfig redeploys a previous pipeline from its fetched configuration, so that the image overrides can be
+// applied to its manifests before the configuration is sent back. The target filters, account, context and source
+// control information of the flags are set in the configuration too.
+func WithPipelineConfig(cmd *cobra.Command, options *deployStartOptions, scmc de.SCM, deployClient ArmoryDeployClient) (*de.StartPipelineResponse, *nethttp.Response, error) {
+	if options.application != "" {
+		return nil, nil, ErrApplicationNameOverrideNotSupported
+	}
+	if options.values != nil {
+		return nil, nil, ErrValuesNotSupported
+	}
+	cmd.SilenceUsage = true
+	ctx, cancel := context.WithTimeout(deployClient.GetArmoryCloudClient().Context, time.Minute)
+	defer cancel()
+	pipelineConfig, response, err := deployClient.PipelineConfig(ctx, options.pipelineID)
+	if err != nil {
+		return nil, response, err
+	}
+	if len(options.targetFilters) > 0 {
+		pipelineConfig["targetFilters"] = prepareTargetFilters(options)
+	}
+	if options.account != "" {
+		overrideTargetAccounts(pipelineConfig, options.account)
+	}
+	// the configuration is sent as it is, so the context and source control information are merged into it here
+	if len(options.context) > 0 {
+		pipelineContext, _ := pipelineConfig["context"].(map[string]any)
+		pipelineConfig["context"] = lo.Assign(pipelineContext, lo.MapValues(options.context, func(value string, _ string) any { return value }))
+	}
+	if options.withSCM || options.withSCMFile != "" {
+		pipelineConfig["sourceControl"] = scmc
+	}
+	startOptions := deployment.StartPipelineOptions{
+		Context:                options.context,
+		SCMC:                   scmc,
+		UnstructuredDeployment: pipelineConfig,
+		ImageOverrides:         options.imageOverrides,
+		IsURL:                  true,
+	}
+	if err := checkPolicies(cmd, options, &startOptions); err != nil {
+		return nil, nil, err
+	}
+	// execute request
+	raw, response, err := deployClient.StartPipeline(ctx, startOptions)
+	return raw, response, err
+}
+
+// overrideTargetAccounts sets the account of every target of a fetched pipeline configuration. The server only
+// overrides the account of a configuration it fetches itself, and this one is sent as it is.
+func overrideTargetAccounts(pipelineConfig map[string]any, account string) {
+	targets, _ := pipelineConfig["targets"].(map[string]any)
+	for _, target := range targets {
+		if target, ok := target.(map[string]any); ok {
+			target["account"] = account
+		}
+	}
+}
+
 func prepareTargetFilters(options *deployStartOptions) []map[string]any {
 	var targetFilters []map[string]any
 	for _, filter := range options.targetFilters {
@@ -267,7 +397,10 @@ func WithLocalFile(cmd *cobra.Command, options *deployStartOptions, scmc de.SCM,
 	if err != nil {
 		return nil, nil, errorUtils.NewWrappedError(ErrYAMLFileRead, err)
 	}
-	validationFailures, err := validate.Validate(file)
+	if file, err = options.values.Render(options.deploymentFile, file); err != nil {
+		return nil, nil, err
+	}
+	validationFailures, err := validate.Validate(file, options.values)
 	if err != nil {
 		return nil, nil, errorUtils.NewWrappedError(ErrInvalidDeploymentObject, err)
 	}
@@ -280,83 +413,58 @@ func WithLocalFile(cmd *cobra.Command, options *deployStartOptions, scmc de.SCM,
 		return nil, nil, errorUtils.NewWrappedError(ErrInvalidDeploymentObject, err)
 	}
 	payload["targetFilters"] = prepareTargetFilters(options)
-	ctx, cancel := context.WithTimeout(deployClient.GetArmoryCloudClient().Context, time.Minute)
-	defer cancel()
-	// execute request
-	raw, response, err := deployClient.StartPipeline(ctx, deployment.StartPipelineOptions{
+	startOptions := deployment.StartPipelineOptions{
 		UnstructuredDeployment:  payload,
 		ApplicationNameOverride: options.application,
 		Context:                 options.context,
 		SCMC:                    scmc,
-	})
+		Values:                  options.values,
+		ImageOverrides:          options.imageOverrides,
+	}
+	if err := checkPolicies(cmd, options, &startOptions); err != nil {
+		return nil, nil, err
+	}
+	ctx, cancel := context.WithTimeout(deployClient.GetArmoryCloudClient().Context, time.Minute)
+	defer cancel()
+	// execute request
+	raw, response, err := deployClient.StartPipeline(ctx, startOptions)
 	return raw, response, err
 }
 
-func beginTrackingDeployment(cmd *cobra.Command, configuration *config.Configuration, deploy *FormattableDeployStartResponse, deployClient *deployment.Client) {
-	canWriteProgress := configuration.GetOutputType() == output.Text
-	if canWriteProgress {
-		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "[%v] Waiting for deployment to complete. Status UI: %s\n", time.Now().Format(time.RFC3339), buildMonitoringUrl(configuration, deploy.DeploymentId))
+// checkPolicies evaluates the body of the request that would start the deployment against the policies, if any,
+// reporting each violation. The request is built as it is sent, with the application name, context and images
+// overridden by the flags, and kept in the options so that StartPipeline sends the request that was checked.
+func checkPolicies(cmd *cobra.Command, options *deployStartOptions, startOptions *deployment.StartPipelineOptions) error {
+	if options.policies == nil {
+		return nil
 	}
-	var (
-		status         de.WorkflowStatus
-		reportedStatus string
-		err            error
-	)
-
-	if status, err = waitForCompletion(deployClient, deploy.DeploymentId, canWriteProgress, cmd.OutOrStdout()); err != nil {
-		reportedStatus = de.WorkflowStatusUnknown + " (error)"
-	} else {
-		reportedStatus = string(status)
+	request, err := deployment.NewPipelineRequest(*startOptions)
+	if err != nil {
+		return err
 	}
-	if canWriteProgress {
-		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "[%v] Deployment %s completed with status: %s\n", time.Now().Format(time.RFC3339), deploy.DeploymentId, reportedStatus)
+	startOptions.Request = request
+	violations, err := policy.Evaluate(options.policies, request.Body)
+	if err != nil {
+		return err
 	}
-
-	deploy.ExecutionStatus = reportedStatus
-	storeCommandResult(cmd, DeployResultSyncStatus, reportedStatus)
-	storeCommandResult(cmd, DeployResultStatusCode, lo.Ternary(status == de.WorkflowStatusSucceeded, "0", "1"))
-}
-
-func waitForCompletion(deployClient *deployment.Client, pipelineID string, canWriteProgress bool, out io.Writer) (de.WorkflowStatus, error) {
-	var lastStatus de.WorkflowStatus
-	for range time.Tick(statusCheckTick) {
-		if canWriteProgress {
-			_, _ = fmt.Fprintf(out, ".")
-		}
-
-		status, err := queryStatus(deployClient, pipelineID)
-		if err != nil {
-			return de.WorkflowStatusUnknown, err
-		}
-
-		if lastStatus != status && canWriteProgress {
-			_, _ = fmt.Fprintf(out, "\n[%v] Deployment status changed: %s\n", time.Now().Format(time.RFC3339), status)
-		}
-
-		lastStatus = status
-		if isDeploymentInFinalState(status) {
-			break
-		}
+	if len(violations) == 0 {
+		return nil
 	}
-	return lastStatus, nil
-}
-
-func queryStatus(deployClient *deployment.Client, pipelineID string) (de.WorkflowStatus, error) {
-	ctx, cancel := context.WithTimeout(deployClient.ArmoryCloudClient.Context, time.Minute)
-	defer cancel()
-	status, _, err := deployClient.PipelineStatus(ctx, pipelineID)
+	descriptions := lo.Map(violations, func(f findings.Finding, _ int) string { return f.String() })
+	_, err = fmt.Fprintf(cmd.OutOrStdout(), "Deployment violates the policies in %s:\n\n%s\n\n", options.policyDir, strings.Join(descriptions, "\n"))
 	if err != nil {
-		return de.WorkflowStatusUnknown, err
+		return err
 	}
-	return status.Status, nil
+	return policy.NewViolationError(violations)
 }
 
-func isDeploymentInFinalState(status de.WorkflowStatus) bool {
-	switch status {
-	case de.WorkflowStatusFailed, de.WorkflowStatusSucceeded, de.WorkflowStatusCancelled:
-		return true
+func reportImageChanges(overrides *deployment.ImageOverrides) {
+	if overrides == nil {
+		return
+	}
+	for _, change := range overrides.Changes() {
+		console.Stderrf("Set image of container %s in %s %s (%s): %s -> %s\n", change.Container, change.Kind, change.Name, change.File, change.From, change.To)
 	}
-	return false
 }
 
 func outputCommandResult(deploy FormattableDeployStartResponse, configuration *config.Configuration) error {
@@ -367,3 +475,12 @@ func outputCommandResult(deploy FormattableDeployStartResponse, configuration *c
 		return err
 	}
 }
+
+func outputDryRunResult(request *deployment.PipelineRequest, configuration *config.Configuration) error {
+	dataFormat, err := configuration.GetOutputFormatter()(FormattableDryRunResponse{Request: request})
+	if err != nil {
+		return err
+	}
+	log.S().Info(dataFormat)
+	return nil
+}
@@ -10,6 +10,7 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"path"
 	"strings"
 	"testing"
 	"time"
@@ -17,6 +18,8 @@ import (
 	de "github.com/armory-io/deploy-engine/pkg/api"
 
 	"github.com/armory/armory-cli/pkg/config"
+	deployment "github.com/armory/armory-cli/pkg/deploy"
+	"github.com/armory/armory-cli/pkg/policy"
 	"github.com/armory/armory-cli/pkg/util"
 	"github.com/jarcoal/httpmock"
 	"github.com/samber/lo"
@@ -229,6 +232,99 @@ func (suite *DeployStartTestSuite) TestDeployWithFileUsesExpectedOptions() {
 	suite.Equal("", deployClient.RecordedStartPipelineOptions.Headers[armoryConfigLocationHeader], "they should be equal")
 }
 
+func (suite *DeployStartTestSuite) TestDeployWithFileViolatingPolicies() {
+	deployClient := GetMockDeployClient(getDefaultConfiguration("json"))
+	deployClient.MockStartPipelineResponse(func() (*de.StartPipelineResponse, *http.Response, error) {
+		suite.T().Fatal("a deployment that violates policies should not be started")
+		return nil, nil, nil
+	})
+	tempFile := util.TempAppFile("", "app", testAppYamlStr)
+	if tempFile == nil {
+		suite.T().Fatal("TestDeployWithFileViolatingPolicies failed with: Could not create temp app file.")
+	}
+	suite.T().Cleanup(func() { os.Remove(tempFile.Name()) })
+	policyDir := suite.T().TempDir()
+	suite.NoError(os.WriteFile(path.Join(policyDir, "accounts.cue"), []byte(`targets: [string]: account: "prod"`), 0o644))
+	policies, err := policy.Load(policyDir)
+	suite.NoError(err)
+
+	cmd := &cobra.Command{}
+	outWriter := bytes.NewBufferString("")
+	cmd.SetOut(outWriter)
+	_, _, err = WithLocalFile(cmd, &deployStartOptions{
+		deploymentFile: tempFile.Name(),
+		policyDir:      policyDir,
+		policies:       policies,
+	},
+		de.SCM{},
+		deployClient,
+	)
+	suite.ErrorIs(err, policy.ErrPolicyViolation)
+	var cliError *clierr.Error
+	suite.True(errors.As(err, &cliError))
+	suite.Equal(int(exitcodes.PolicyViolation), cliError.ExitCode(), "a policy violation should exit 4")
+	suite.Contains(outWriter.String(), "Deployment violates the policies in "+policyDir)
+	suite.Contains(outWriter.String(), "targets.dev-west.account")
+}
+
+func (suite *DeployStartTestSuite) TestDeployPoliciesCheckOverriddenRequest() {
+	policyDir := suite.T().TempDir()
+	suite.NoError(os.WriteFile(path.Join(policyDir, "application.cue"), []byte(`application: "deployment-test"
+context: team: "potatoes"`), 0o644))
+	policies, err := policy.Load(policyDir)
+	suite.NoError(err)
+	tempFile := util.TempAppFile("", "app", testAppYamlStr)
+	if tempFile == nil {
+		suite.T().Fatal("TestDeployPoliciesCheckOverriddenRequest failed with: Could not create temp app file.")
+	}
+	suite.T().Cleanup(func() { os.Remove(tempFile.Name()) })
+
+	cases := []struct {
+		name        string
+		application string
+		violation   string
+	}{
+		{name: "application override", application: "other-app", violation: "application"},
+		{name: "context", violation: ""},
+	}
+	for _, c := range cases {
+		suite.Run(c.name, func() {
+			deployClient := GetMockDeployClient(getDefaultConfiguration("json"))
+			started := false
+			deployClient.MockStartPipelineResponse(func() (*de.StartPipelineResponse, *http.Response, error) {
+				started = true
+				return &de.StartPipelineResponse{PipelineID: "12345"}, &http.Response{StatusCode: http.StatusAccepted}, nil
+			})
+			cmd := &cobra.Command{}
+			outWriter := bytes.NewBufferString("")
+			cmd.SetOut(outWriter)
+			_, _, err := WithLocalFile(cmd, &deployStartOptions{
+				deploymentFile: tempFile.Name(),
+				application:    c.application,
+				context:        map[string]string{"team": "potatoes"},
+				policyDir:      policyDir,
+				policies:       policies,
+			},
+				de.SCM{},
+				deployClient,
+			)
+			if c.violation == "" {
+				// the context given with --add-context satisfies the policy, although it is not in the file
+				suite.NoError(err)
+				suite.True(started)
+				// the request that was checked is sent, rather than built again
+				suite.NotNil(deployClient.RecordedStartPipelineOptions.Request)
+				suite.Equal("potatoes", deployClient.RecordedStartPipelineOptions.Request.Body["context"].(map[string]any)["team"])
+				return
+			}
+			suite.ErrorIs(err, policy.ErrPolicyViolation)
+			suite.False(started, "a deployment that violates policies should not be started")
+			suite.Contains(outWriter.String(), c.violation)
+			suite.Contains(outWriter.String(), "other-app")
+		})
+	}
+}
+
 func (suite *DeployStartTestSuite) TestDeployWithPipelineValidation() {
 	cmd := &cobra.Command{}
 	deployClient := GetMockDeployClient(getDefaultConfiguration("json"))
@@ -491,12 +587,17 @@ func (suite *DeployStartTestSuite) TestDeployStartJsonAndWaitForCompletionSucces
 	if err != nil {
 		suite.T().Fatalf("TestDeployStartJsonSuccess failed with: %s", err)
 	}
-	output, err := io.ReadAll(outWriter)
-	if err != nil {
-		suite.T().Fatalf("TestDeployStartJsonSuccess failed with: %s", err)
+	lines := strings.Split(strings.TrimSpace(outWriter.String()), "\n")
+	suite.Len(lines, 4, "expected three watch events followed by the result")
+	var events []DeploymentEvent
+	for _, line := range lines[:len(lines)-1] {
+		var event DeploymentEvent
+		suite.NoError(json.Unmarshal([]byte(line), &event))
+		events = append(events, event)
 	}
+	suite.Equal([]string{"UNKNOWN", "RUNNING", "SUCCEEDED"}, lo.Map(events, func(event DeploymentEvent, _ int) string { return event.Status }))
 	var received = FormattableDeployStartResponse{}
-	json.Unmarshal(output, &received)
+	suite.NoError(json.Unmarshal([]byte(lines[len(lines)-1]), &received))
 	suite.Equal(expected.PipelineID, received.DeploymentId, "they should be equal")
 	suite.Equal(string(de.WorkflowStatusSucceeded), received.ExecutionStatus, "status should be SUCCESS")
 }
@@ -533,6 +634,202 @@ func (suite *DeployStartTestSuite) TestDeployStartYAMLAndWaitForCompletionSucces
 	suite.Equal(string(de.WorkflowStatusCancelled), received.ExecutionStatus, "pipeline status should be cancelled")
 }
 
+func (suite *DeployStartTestSuite) TestDeployStartDryRun() {
+	tempFile := util.TempAppFile("", "app", testAppYamlStr)
+	suite.Require().NotNil(tempFile, "could not create temp app file")
+	suite.T().Cleanup(func() { os.Remove(tempFile.Name()) })
+
+	outWriter := bytes.NewBufferString("")
+	cmd := getDeployCmdWithFileName(outWriter, tempFile.Name(), "json", "--dry-run", "--add-context=env=dev", "-t", "dev-west")
+	suite.NoError(cmd.Execute())
+	suite.Equal(0, httpmock.GetTotalCallCount(), "a dry run must not send any request")
+
+	var received struct {
+		Method  string            `json:"method"`
+		Path    string            `json:"path"`
+		Headers map[string]string `json:"headers"`
+		Body    map[string]any    `json:"body"`
+	}
+	suite.NoError(json.Unmarshal(outWriter.Bytes(), &received))
+	suite.Equal(http.MethodPost, received.Method)
+	suite.Equal("/pipelines/kubernetes", received.Path)
+	suite.Equal("application/vnd.start.kubernetes.pipeline.v2+json", received.Headers["Content-Type"])
+	suite.Equal("deployment-test", received.Body["application"])
+	suite.Equal(map[string]any{"env": "dev"}, received.Body["context"])
+	suite.Equal([]any{map[string]any{"includeTarget": "dev-west"}}, received.Body["targetFilters"])
+}
+
+func (suite *DeployStartTestSuite) TestDeployStartWithValues() {
+	tempFile := util.TempAppFile("", "app", strings.Replace(testAppYamlStr, "application: deployment-test", "application: ${application}", 1))
+	suite.Require().NotNil(tempFile, "could not create temp app file")
+	suite.T().Cleanup(func() { os.Remove(tempFile.Name()) })
+	valuesFile := util.TempAppFile("", "values", "application: from-values\n")
+	suite.Require().NotNil(valuesFile, "could not create temp values file")
+	suite.T().Cleanup(func() { os.Remove(valuesFile.Name()) })
+
+	outWriter := bytes.NewBufferString("")
+	cmd := getDeployCmdWithFileName(outWriter, tempFile.Name(), "json", "--dry-run", "--values="+valuesFile.Name(), "--set", "application=from-set")
+	suite.NoError(cmd.Execute())
+	var received deployment.PipelineRequest
+	suite.NoError(json.Unmarshal(outWriter.Bytes(), &received))
+	suite.Equal("from-set", received.Body["application"])
+
+	cmd = getDeployCmdWithFileName(bytes.NewBufferString(""), tempFile.Name(), "json", "--dry-run", "--strict")
+	suite.ErrorContains(cmd.Execute(), "unresolved values")
+}
+
+func (suite *DeployStartTestSuite) TestDeployStartWithImages() {
+	manifest := "kind: Deployment\nmetadata:\n  name: potato-facts\nspec:\n  template:\n    spec:\n      containers:\n        - name: potato-facts\n          image: armory/potato-facts:1.0.0\n"
+	inline, err := yaml.Marshal(map[string]any{"manifests": []map[string]any{{"inline": manifest}}})
+	suite.Require().NoError(err)
+	tempFile := util.TempAppFile("", "app", strings.Replace(testAppYamlStr, "manifests:\n  - inline: \"\"\n", string(inline), 1))
+	suite.Require().NotNil(tempFile, "could not create temp app file")
+	suite.T().Cleanup(func() { os.Remove(tempFile.Name()) })
+
+	outWriter := bytes.NewBufferString("")
+	cmd := getDeployCmdWithFileName(outWriter, tempFile.Name(), "json", "--dry-run", "--image", "potato-facts=armory/potato-facts:2.0.0")
+	suite.NoError(cmd.Execute())
+	var received deployment.PipelineRequest
+	suite.NoError(json.Unmarshal(outWriter.Bytes(), &received))
+	suite.Equal(strings.Replace(manifest, "1.0.0", "2.0.0", 1), received.Body["manifests"].([]any)[0].(map[string]any)["inline"])
+
+	cmd = getDeployCmdWithFileName(bytes.NewBufferString(""), tempFile.Name(), "json", "--dry-run", "--image", "potatoes=armory/potatoes:2.0.0")
+	suite.ErrorIs(cmd.Execute(), deployment.ErrUnusedImageOverride)
+}
+
+func (suite *DeployStartTestSuite) TestDeployStartWithImagesAndPipelineID() {
+	configResponder, err := httpmock.NewJsonResponder(http.StatusOK, map[string]any{
+		"application": "potato-facts",
+		"files": map[string]any{
+			"potato.yaml": []string{"kind: Deployment\nmetadata:\n  name: potato-facts\nspec:\n  template:\n    spec:\n      containers:\n        - name: potato-facts\n          image: armory/potato-facts:1.0.0\n"},
+		},
+	})
+	suite.Require().NoError(err)
+	httpmock.RegisterResponder(http.MethodGet, "https://localhost/pipelines/12345/config", configResponder)
+	var received map[string]any
+	httpmock.RegisterResponder(http.MethodPost, "https://localhost/pipelines/kubernetes", func(request *http.Request) (*http.Response, error) {
+		suite.Equal(mediaTypePipelineV2, request.Header.Get("Content-Type"))
+		suite.NoError(json.NewDecoder(request.Body).Decode(&received))
+		return httpmock.NewJsonResponse(http.StatusAccepted, de.StartPipelineResponse{PipelineID: "67890"})
+	})
+
+	cmd := NewDeployCmd(getDefaultConfiguration("json"))
+	cmd.SetOut(bytes.NewBufferString(""))
+	cmd.SetArgs([]string{"start", "--pipelineId=12345", "--image", "potato-facts=armory/potato-facts:2.0.0", "-t", "prod"})
+	suite.NoError(cmd.Execute())
+	suite.Equal("potato-facts", received["application"])
+	suite.Equal([]any{map[string]any{"includeTarget": "prod"}}, received["targetFilters"])
+	suite.Contains(received["files"].(map[string]any)["potato.yaml"].([]any)[0], "image: armory/potato-facts:2.0.0")
+}
+
+func (suite *DeployStartTestSuite) TestDeployStartWithImagesAndPipelineIDOverridesAccount() {
+	configResponder, err := httpmock.NewJsonResponder(http.StatusOK, map[string]any{
+		"application": "potato-facts",
+		"targets": map[string]any{
+			"staging": map[string]any{"account": "staging-cluster", "namespace": "potato-facts"},
+			"prod":    map[string]any{"account": "prod-cluster", "namespace": "potato-facts"},
+		},
+		"files": map[string]any{
+			"potato.yaml": []string{"kind: Deployment\nmetadata:\n  name: potato-facts\nspec:\n  template:\n    spec:\n      containers:\n        - name: potato-facts\n          image: armory/potato-facts:1.0.0\n"},
+		},
+	})
+	suite.Require().NoError(err)
+	httpmock.RegisterResponder(http.MethodGet, "https://localhost/pipelines/12345/config", configResponder)
+	var received map[string]any
+	httpmock.RegisterResponder(http.MethodPost, "https://localhost/pipelines/kubernetes", func(request *http.Request) (*http.Response, error) {
+		suite.NoError(json.NewDecoder(request.Body).Decode(&received))
+		return httpmock.NewJsonResponse(http.StatusAccepted, de.StartPipelineResponse{PipelineID: "67890"})
+	})
+
+	cmd := NewDeployCmd(getDefaultConfiguration("json"))
+	cmd.SetOut(bytes.NewBufferString(""))
+	cmd.SetArgs([]string{"start", "--pipelineId=12345", "--image", "potato-facts=armory/potato-facts:2.0.0", "--account", "dr-cluster"})
+	suite.NoError(cmd.Execute())
+	suite.Equal(map[string]any{
+		"staging": map[string]any{"account": "dr-cluster", "namespace": "potato-facts"},
+		"prod":    map[string]any{"account": "dr-cluster", "namespace": "potato-facts"},
+	}, received["targets"])
+	suite.NotContains(received, "account")
+}
+
+func (suite *DeployStartTestSuite) TestDeployStartWithImagesAndPipelineIDAddsContext() {
+	configResponder, err := httpmock.NewJsonResponder(http.StatusOK, map[string]any{
+		"application": "potato-facts",
+		"context":     map[string]any{"team": "potatoes"},
+		"files": map[string]any{
+			"potato.yaml": []string{"kind: Deployment\nmetadata:\n  name: potato-facts\nspec:\n  template:\n    spec:\n      containers:\n        - name: potato-facts\n          image: armory/potato-facts:1.0.0\n"},
+		},
+	})
+	suite.Require().NoError(err)
+	httpmock.RegisterResponder(http.MethodGet, "https://localhost/pipelines/12345/config", configResponder)
+	var received map[string]any
+	httpmock.RegisterResponder(http.MethodPost, "https://localhost/pipelines/kubernetes", func(request *http.Request) (*http.Response, error) {
+		suite.NoError(json.NewDecoder(request.Body).Decode(&received))
+		return httpmock.NewJsonResponse(http.StatusAccepted, de.StartPipelineResponse{PipelineID: "67890"})
+	})
+
+	cmd := NewDeployCmd(getDefaultConfiguration("json"))
+	cmd.SetOut(bytes.NewBufferString(""))
+	cmd.SetArgs([]string{"start", "--pipelineId=12345", "--image", "potato-facts=armory/potato-facts:2.0.0", "--add-context", "release=2.0.0"})
+	suite.NoError(cmd.Execute())
+	suite.Equal(map[string]any{"team": "potatoes", "release": "2.0.0"}, received["context"])
+	suite.NotContains(received, "sourceControl")
+}
+
+func (suite *DeployStartTestSuite) TestDeployStartDryRunWithImagesAndPipelineID() {
+	configResponder, err := httpmock.NewJsonResponder(http.StatusOK, map[string]any{
+		"application": "potato-facts",
+		"files": map[string]any{
+			"potato.yaml": []string{"kind: Deployment\nmetadata:\n  name: potato-facts\nspec:\n  template:\n    spec:\n      containers:\n        - name: potato-facts\n          image: armory/potato-facts:1.0.0\n"},
+		},
+	})
+	suite.Require().NoError(err)
+	httpmock.RegisterResponder(http.MethodGet, "https://localhost/pipelines/12345/config", configResponder)
+
+	outWriter := bytes.NewBufferString("")
+	cmd := NewDeployCmd(getDefaultConfiguration("yaml"))
+	cmd.SetOut(outWriter)
+	cmd.SetArgs([]string{"start", "--pipelineId=12345", "--image", "potato-facts=armory/potato-facts:2.0.0", "--dry-run"})
+	suite.NoError(cmd.Execute())
+	suite.Equal(1, httpmock.GetTotalCallCount(), "only the configuration of the pipeline should be fetched")
+
+	var received deployment.PipelineRequest
+	suite.NoError(yaml.Unmarshal(outWriter.Bytes(), &received))
+	suite.Contains(received.Body["files"].(map[string]any)["potato.yaml"].([]any)[0], "image: armory/potato-facts:2.0.0")
+}
+
+func (suite *DeployStartTestSuite) TestDeployStartDryRunWithPipelineID() {
+	outWriter := bytes.NewBufferString("")
+	cmd := NewDeployCmd(getDefaultConfiguration("yaml"))
+	cmd.SetOut(outWriter)
+	cmd.SetArgs([]string{"start", "--pipelineId=12345", "--dry-run"})
+	suite.NoError(cmd.Execute())
+	suite.Equal(0, httpmock.GetTotalCallCount(), "a dry run must not send any request")
+
+	var received deployment.PipelineRequest
+	suite.NoError(yaml.Unmarshal(outWriter.Bytes(), &received))
+	suite.Equal("/pipelines/kubernetes", received.Path)
+	suite.Equal(mediaTypePipelineV2Link, received.Headers["Content-Type"])
+	suite.Equal("armory::https://localhost/pipelines/12345/config", received.Headers[armoryConfigLocationHeader])
+}
+
+func (suite *DeployStartTestSuite) TestDeployStartDryRunCannotWatch() {
+	cmd := NewDeployCmd(getDefaultConfiguration("json"))
+	cmd.SetOut(bytes.NewBufferString(""))
+	cmd.SetArgs([]string{"start", "--pipelineId=12345", "--dry-run", "--watch"})
+	suite.ErrorIs(cmd.Execute(), ErrDryRunWithWatch)
+}
+
+func (suite *DeployStartTestSuite) TestDeployStartRejectsInvalidWatchInterval() {
+	for _, interval := range []string{"0s", "-5s"} {
+		cmd := NewDeployCmd(getDefaultConfiguration("json"))
+		cmd.SetOut(bytes.NewBufferString(""))
+		cmd.SetArgs([]string{"start", "--pipelineId=12345", "--watch", "--watch-interval=" + interval})
+		suite.ErrorIs(cmd.Execute(), ErrInvalidWatchInterval)
+	}
+	suite.Equal(0, httpmock.GetTotalCallCount(), "no deployment should be started with an invalid interval")
+}
+
 func registerResponder(body interface{}, status int, kind string) error {
 	responder, err := httpmock.NewJsonResponder(status, body)
 	if err != nil {
@@ -593,9 +890,9 @@ func getDefaultConfiguration(output string) *config.Configuration {
 
 const expectedValidationError = `YAML is NOT valid. See the following errors:
 
-#PipelineRequest.targets."dev-west".strategy: 1 errors in empty disjunction:
+#PipelineRequest.targets."dev-west".strategy: line 9, column 19: 1 errors in empty disjunction:
 
-#PipelineRequest.targets."dev-west".strategy: conflicting values "strategy1" and "strategy0"
+#PipelineRequest.targets."dev-west".strategy: line 9, column 19: conflicting values "strategy1" and "strategy0"
 
 deploymentId: "12345"
 
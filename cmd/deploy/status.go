@@ -50,11 +50,11 @@ func (ds formattableDeployStatus) String() string {
 	case deploy.WorkflowStatusPaused:
 		for _, stages := range ds.pipeline.Steps {
 			if stages.Type == "pause" && stages.Status == deploy.WorkflowStatusPaused {
-				ret += fmt.Sprintf("[%s] msg: Paused for %d %s. You can skip the pause in the CD-as-a-Service Console or CLI\n", status, stages.Pause.Duration, stages.Pause.Unit)
+				ret += fmt.Sprintf("[%s] msg: Paused for %d %s. You can skip the pause in the CD-as-a-Service Console or with 'armory deploy skip-pause'\n", status, stages.Pause.Duration, stages.Pause.Unit)
 			}
 		}
 	case deploy.WorkflowStatusAwaitingApproval:
-		ret += fmt.Sprintf("[%s] msg: Paused for Manual Judgment. You can approve the rollout and continue the deployment in the CD-as-a-Service Console or CLI.\n", status)
+		ret += fmt.Sprintf("[%s] msg: Paused for Manual Judgment. You can approve the rollout and continue the deployment in the CD-as-a-Service Console or with 'armory deploy approve'.\n", status)
 	default:
 		ret += string(status) + "\n"
 	}
@@ -0,0 +1,219 @@
+package deploy
+
+import (
+	"context"
+	"fmt"
+	de "github.com/armory-io/deploy-engine/pkg/api"
+	"github.com/armory/armory-cli/cmd/utils"
+	"github.com/armory/armory-cli/pkg/cmdUtils"
+	"github.com/armory/armory-cli/pkg/config"
+	deployment "github.com/armory/armory-cli/pkg/deploy"
+	errorUtils "github.com/armory/armory-cli/pkg/errors"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	log "go.uber.org/zap"
+	nethttp "net/http"
+	"strings"
+	"time"
+)
+
+const (
+	deployApproveShort = "Approve a deployment step that is awaiting manual judgment"
+	deployApproveLong  = "Approve a deployment step that is awaiting manual judgment\n\n" +
+		"If more than one step is awaiting approval, use --target or --step to select the step to approve"
+	deployApproveExample = "armory deploy approve --deploymentId [deploymentId] [--target <target name>]"
+
+	deployRejectShort = "Reject a deployment step that is awaiting manual judgment"
+	deployRejectLong  = "Reject a deployment step that is awaiting manual judgment, failing the step\n\n" +
+		"If more than one step is awaiting approval, use --target or --step to select the step to reject"
+	deployRejectExample = "armory deploy reject --deploymentId [deploymentId] [--target <target name>]"
+
+	deploySkipPauseShort = "Skip a timed pause in a deployment"
+	deploySkipPauseLong  = "Skip a timed pause in a deployment so that it continues immediately\n\n" +
+		"If more than one step is paused, use --target or --step to select the pause to skip"
+	deploySkipPauseExample = "armory deploy skip-pause --deploymentId [deploymentId] [--target <target name>]"
+)
+
+type (
+	stepActionOptions struct {
+		deploymentID string
+		target       string
+		stepRef      string
+	}
+
+	// stepActionSpec describes one of the operator actions that can be performed on a deployment step.
+	stepActionSpec struct {
+		use     string
+		short   string
+		long    string
+		example string
+		action  deployment.StepAction
+		// waitingStatus is the status a step must be in for the action to apply to it.
+		waitingStatus de.WorkflowStatus
+		// performed describes the completed action in text output, e.g. "Approved".
+		performed string
+		perform   func(client *deployment.Client, ctx context.Context, pipelineID, stepRef string) (*nethttp.Response, error)
+	}
+
+	FormattableStepActionResponse struct {
+		DeploymentID     string `json:"deploymentId" yaml:"deploymentId"`
+		Action           string `json:"action" yaml:"action"`
+		Step             string `json:"step" yaml:"step"`
+		Target           string `json:"target,omitempty" yaml:"target,omitempty"`
+		performed        string
+		currentTimestamp time.Time
+		httpResponse     *nethttp.Response
+		err              error
+	}
+)
+
+func (r FormattableStepActionResponse) Get() interface{} {
+	return r
+}
+
+func (r FormattableStepActionResponse) GetHttpResponse() *nethttp.Response {
+	return r.httpResponse
+}
+
+func (r FormattableStepActionResponse) GetFetchError() error {
+	return r.err
+}
+
+func (r FormattableStepActionResponse) String() string {
+	target := lo.Ternary(r.Target == "", "", fmt.Sprintf(" of target %s", r.Target))
+	return fmt.Sprintf("[%v] %s step %s%s in deployment %s", r.currentTimestamp.Format(time.RFC3339), r.performed, r.Step, target, r.DeploymentID)
+}
+
+func NewDeployApproveCmd(configuration *config.Configuration) *cobra.Command {
+	return newStepActionCmd(configuration, stepActionSpec{
+		use:           "approve --deploymentId [deploymentId]",
+		short:         deployApproveShort,
+		long:          deployApproveLong,
+		example:       deployApproveExample,
+		action:        deployment.StepActionApprove,
+		waitingStatus: de.WorkflowStatusAwaitingApproval,
+		performed:     "Approved",
+		perform:       (*deployment.Client).Approve,
+	})
+}
+
+func NewDeployRejectCmd(configuration *config.Configuration) *cobra.Command {
+	return newStepActionCmd(configuration, stepActionSpec{
+		use:           "reject --deploymentId [deploymentId]",
+		short:         deployRejectShort,
+		long:          deployRejectLong,
+		example:       deployRejectExample,
+		action:        deployment.StepActionReject,
+		waitingStatus: de.WorkflowStatusAwaitingApproval,
+		performed:     "Rejected",
+		perform:       (*deployment.Client).Reject,
+	})
+}
+
+func NewDeploySkipPauseCmd(configuration *config.Configuration) *cobra.Command {
+	return newStepActionCmd(configuration, stepActionSpec{
+		use:           "skip-pause --deploymentId [deploymentId]",
+		short:         deploySkipPauseShort,
+		long:          deploySkipPauseLong,
+		example:       deploySkipPauseExample,
+		action:        deployment.StepActionSkipPause,
+		waitingStatus: de.WorkflowStatusPaused,
+		performed:     "Skipped pause",
+		perform:       (*deployment.Client).SkipPause,
+	})
+}
+
+func newStepActionCmd(configuration *config.Configuration, spec stepActionSpec) *cobra.Command {
+	options := &stepActionOptions{}
+	cmd := &cobra.Command{
+		Use:     spec.use,
+		Short:   spec.short,
+		Long:    spec.long,
+		Example: spec.example,
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			cmdUtils.ExecuteParentHooks(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return performStepAction(cmd, configuration, options, spec)
+		},
+	}
+	addDeploymentIDFlag(cmd, &options.deploymentID)
+	cmd.Flags().StringVarP(&options.target, "target", "t", "", "only consider steps that belong to this deployment target")
+	cmd.Flags().StringVar(&options.stepRef, "step", "", "the ref of the step to act on, as shown by 'armory deploy status -o json'")
+	return cmd
+}
+
+func performStepAction(cmd *cobra.Command, configuration *config.Configuration, options *stepActionOptions, spec stepActionSpec) error {
+	if *configuration.GetIsTest() {
+		utils.ConfigureLoggingForTesting(cmd)
+	}
+
+	storeCommandResult(cmd, DeployResultDeploymentID, options.deploymentID)
+
+	deployClient := deployment.NewClient(configuration)
+	ctx, cancel := context.WithTimeout(deployClient.ArmoryCloudClient.Context, time.Minute)
+	defer cancel()
+
+	// if we've made it this far, the command is valid. if an error occurs it isn't a usage error
+	cmd.SilenceUsage = true
+	pipeline, _, err := deployClient.PipelineStatus(ctx, options.deploymentID)
+	if err != nil {
+		return errorUtils.NewWrappedError(ErrDeploymentStatusRequest, err)
+	}
+
+	step, err := selectStep(pipeline, spec.waitingStatus, options)
+	if err != nil {
+		return err
+	}
+
+	response, err := spec.perform(deployClient, ctx, options.deploymentID, step.Ref)
+	if err != nil {
+		return err
+	}
+
+	dataFormat, err := configuration.GetOutputFormatter()(FormattableStepActionResponse{
+		DeploymentID:     options.deploymentID,
+		Action:           string(spec.action),
+		Step:             step.Ref,
+		Target:           stepTarget(step),
+		performed:        spec.performed,
+		currentTimestamp: configuration.Now(),
+		httpResponse:     response,
+	})
+	if err != nil {
+		return err
+	}
+	log.S().Info(dataFormat)
+	return nil
+}
+
+// selectStep finds the single step in the pipeline that is in the given status and matches the target and step
+// selectors. It is an error for the selectors to match no step or more than one step.
+func selectStep(pipeline *de.PipelineStatusResponse, status de.WorkflowStatus, options *stepActionOptions) (*de.PipelineStep, error) {
+	candidates := lo.Filter(pipeline.Steps, func(step *de.PipelineStep, _ int) bool {
+		return step != nil &&
+			step.Status == status &&
+			(options.stepRef == "" || step.Ref == options.stepRef) &&
+			(options.target == "" || stepTarget(step) == options.target)
+	})
+
+	switch len(candidates) {
+	case 0:
+		return nil, errorUtils.NewErrorWithDynamicContext(ErrNoMatchingStep, fmt.Sprintf(", deployment %s has no matching step with status %s", options.deploymentID, status))
+	case 1:
+		return candidates[0], nil
+	default:
+		refs := lo.Map(candidates, func(step *de.PipelineStep, _ int) string {
+			return fmt.Sprintf("%s (target: %s)", step.Ref, stepTarget(step))
+		})
+		return nil, errorUtils.NewErrorWithDynamicContext(ErrAmbiguousStep, ", matching steps: "+strings.Join(refs, ", "))
+	}
+}
+
+// stepTarget returns the name of the deployment target a pipeline step belongs to.
+func stepTarget(step *de.PipelineStep) string {
+	if step.Deployment != nil {
+		return step.Deployment.Environment
+	}
+	return step.ConstraintFor
+}
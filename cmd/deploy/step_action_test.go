@@ -0,0 +1,169 @@
+package deploy
+
+import (
+	"bytes"
+	"encoding/json"
+	api "github.com/armory-io/deploy-engine/pkg/api"
+	"github.com/armory/armory-cli/internal/clierr"
+	"github.com/armory/armory-cli/internal/clierr/exitcodes"
+	"github.com/armory/armory-cli/pkg/config"
+	"github.com/jarcoal/httpmock"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+var (
+	testPausedPipeline = api.PipelineStatusResponse{
+		ID:          "12345",
+		Application: "app",
+		Status:      api.WorkflowStatusAwaitingApproval,
+		Steps: []*api.PipelineStep{
+			{
+				Ref:    "deploy-staging",
+				Type:   "deployment",
+				Status: api.WorkflowStatusSucceeded,
+				Deployment: &api.PipelineDeploymentStepResponse{
+					ID:          "5678",
+					Environment: "staging",
+				},
+			},
+			{
+				Ref:           "approve-prod-east",
+				Type:          "pause",
+				Status:        api.WorkflowStatusAwaitingApproval,
+				ConstraintFor: "prod-east",
+			},
+			{
+				Ref:           "approve-prod-west",
+				Type:          "pause",
+				Status:        api.WorkflowStatusAwaitingApproval,
+				ConstraintFor: "prod-west",
+			},
+			{
+				Ref:           "pause-dev",
+				Type:          "pause",
+				Status:        api.WorkflowStatusPaused,
+				ConstraintFor: "dev",
+				Pause: &api.PauseStepResponse{
+					Duration: 5,
+					Unit:     api.TimeUnitMinutes,
+				},
+			},
+		},
+	}
+)
+
+func TestDeployStepActions(t *testing.T) {
+	cases := []struct {
+		name              string
+		args              []string
+		actionPath        string
+		actionStatus      int
+		assertion         func(t *testing.T, output []byte)
+		expectErrContains string
+		expectExitCode    exitcodes.ExitCode
+	}{
+		{
+			name:         "approve step selected by target",
+			args:         []string{"approve", "--deploymentId=12345", "--target=prod-west"},
+			actionPath:   "/pipelines/12345/steps/approve-prod-west/approve",
+			actionStatus: http.StatusAccepted,
+			assertion: func(t *testing.T, output []byte) {
+				var received FormattableStepActionResponse
+				assert.NoError(t, json.Unmarshal(output, &received))
+				assert.Equal(t, "approve", received.Action)
+				assert.Equal(t, "approve-prod-west", received.Step)
+				assert.Equal(t, "prod-west", received.Target)
+			},
+		},
+		{
+			name:         "reject step selected by ref",
+			args:         []string{"reject", "--deploymentId=12345", "--step=approve-prod-east"},
+			actionPath:   "/pipelines/12345/steps/approve-prod-east/reject",
+			actionStatus: http.StatusAccepted,
+			assertion: func(t *testing.T, output []byte) {
+				var received FormattableStepActionResponse
+				assert.NoError(t, json.Unmarshal(output, &received))
+				assert.Equal(t, "reject", received.Action)
+				assert.Equal(t, "prod-east", received.Target)
+			},
+		},
+		{
+			name:         "skip the only paused step",
+			args:         []string{"skip-pause", "--deploymentId=12345"},
+			actionPath:   "/pipelines/12345/steps/pause-dev/skip",
+			actionStatus: http.StatusNoContent,
+			assertion: func(t *testing.T, output []byte) {
+				var received FormattableStepActionResponse
+				assert.NoError(t, json.Unmarshal(output, &received))
+				assert.Equal(t, "skip", received.Action)
+				assert.Equal(t, "pause-dev", received.Step)
+			},
+		},
+		{
+			name:              "approve is ambiguous without a selector",
+			args:              []string{"approve", "--deploymentId=12345"},
+			expectErrContains: "approve-prod-east (target: prod-east), approve-prod-west (target: prod-west)",
+		},
+		{
+			name:              "no step matches the selector",
+			args:              []string{"skip-pause", "--deploymentId=12345", "--target=prod-east"},
+			expectErrContains: "no step found to act on",
+		},
+		{
+			name:              "step was already acted on",
+			args:              []string{"approve", "--deploymentId=12345", "--target=prod-east"},
+			actionPath:        "/pipelines/12345/steps/approve-prod-east/approve",
+			actionStatus:      http.StatusConflict,
+			expectErrContains: "status code: 409",
+			expectExitCode:    exitcodes.Conflict,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			httpmock.Activate()
+			defer httpmock.DeactivateAndReset()
+
+			statusResponder, err := httpmock.NewJsonResponder(http.StatusOK, testPausedPipeline)
+			assert.NoError(t, err)
+			httpmock.RegisterResponder("GET", "https://localhost/pipelines/12345", statusResponder)
+			if c.actionPath != "" {
+				actionResponder, err := httpmock.NewJsonResponder(c.actionStatus, clierr.ApiErrorResponse{
+					ErrorID: "249fdf16-e97b-4507-bf85-df9ec66f2b87",
+					Errors:  []clierr.ApiErrorDTO{{Message: "step is no longer waiting"}},
+				})
+				assert.NoError(t, err)
+				httpmock.RegisterResponder("POST", "https://localhost"+c.actionPath, actionResponder)
+			}
+
+			cmd := NewDeployCmd(config.New(&config.Input{
+				AccessToken:  lo.ToPtr("some-token"),
+				ApiAddr:      lo.ToPtr("https://localhost"),
+				ClientId:     lo.ToPtr(""),
+				ClientSecret: lo.ToPtr(""),
+				OutFormat:    lo.ToPtr("json"),
+				IsTest:       lo.ToPtr(true),
+			}))
+			writer := bytes.NewBufferString("")
+			cmd.SetOut(writer)
+			cmd.SetArgs(c.args)
+
+			err = cmd.Execute()
+			if c.expectErrContains != "" {
+				assert.ErrorContains(t, err, c.expectErrContains)
+				if c.expectExitCode != 0 {
+					var apiError *clierr.APIError
+					assert.ErrorAs(t, err, &apiError)
+					assert.Equal(t, int(c.expectExitCode), apiError.ExitCode())
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, 1, httpmock.GetCallCountInfo()["POST https://localhost"+c.actionPath])
+			c.assertion(t, writer.Bytes())
+		})
+	}
+}
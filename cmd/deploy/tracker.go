@@ -0,0 +1,199 @@
+package deploy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	de "github.com/armory-io/deploy-engine/pkg/api"
+	"io"
+	nethttp "net/http"
+	"strings"
+	"time"
+)
+
+const (
+	deploymentEventTypeDeployment = "deployment"
+	deploymentEventTypeStep       = "step"
+)
+
+type (
+	pipelineStatusClient interface {
+		PipelineStatus(ctx context.Context, pipelineID string) (*de.PipelineStatusResponse, *nethttp.Response, error)
+	}
+
+	// DeploymentEvent is a single change observed while watching a deployment. With JSON output, each event is
+	// written on its own line so that the stream can be consumed as NDJSON.
+	DeploymentEvent struct {
+		Timestamp      time.Time `json:"timestamp"`
+		Type           string    `json:"type"`
+		DeploymentID   string    `json:"deploymentId"`
+		Target         string    `json:"target,omitempty"`
+		Step           string    `json:"step,omitempty"`
+		StepType       string    `json:"stepType,omitempty"`
+		Status         string    `json:"status"`
+		PreviousStatus string    `json:"previousStatus,omitempty"`
+		Detail         string    `json:"detail,omitempty"`
+	}
+
+	// deploymentTracker polls the status of a deployment until it reaches a final state, reporting every transition
+	// of the deployment and of its steps along the way.
+	deploymentTracker struct {
+		client   pipelineStatusClient
+		interval time.Duration
+		now      func() time.Time
+		// out receives the reported events. Nothing is reported when it is nil.
+		out    io.Writer
+		asJSON bool
+
+		status de.WorkflowStatus
+		steps  map[string]stepSnapshot
+	}
+
+	stepSnapshot struct {
+		status de.WorkflowStatus
+		detail string
+	}
+)
+
+func (e DeploymentEvent) String() string {
+	subject := fmt.Sprintf("Deployment %s", e.DeploymentID)
+	if e.Type == deploymentEventTypeStep {
+		subject = fmt.Sprintf("%s step %s", e.StepType, e.Step)
+		if e.Target != "" {
+			subject = fmt.Sprintf("Target %s: %s", e.Target, subject)
+		}
+	}
+	status := e.Status
+	if e.PreviousStatus != "" && e.PreviousStatus != e.Status {
+		status = fmt.Sprintf("%s -> %s", e.PreviousStatus, e.Status)
+	}
+	detail := ""
+	if e.Detail != "" {
+		detail = fmt.Sprintf(" (%s)", e.Detail)
+	}
+	return fmt.Sprintf("[%v] %s: %s%s", e.Timestamp.Format(time.RFC3339), subject, status, detail)
+}
+
+func newDeploymentTracker(client pipelineStatusClient, interval time.Duration, now func() time.Time, out io.Writer, asJSON bool) *deploymentTracker {
+	return &deploymentTracker{
+		client:   client,
+		interval: interval,
+		now:      now,
+		out:      out,
+		asJSON:   asJSON,
+		steps:    map[string]stepSnapshot{},
+	}
+}
+
+// track polls the deployment until it reaches a final state or the context is done. The last observed status is
+// returned in both cases, along with ErrWatchTimeout if the context expired first.
+func (t *deploymentTracker) track(ctx context.Context, pipelineID string) (de.WorkflowStatus, error) {
+	for {
+		pipeline, err := t.poll(ctx, pipelineID)
+		if err != nil {
+			if ctx.Err() != nil {
+				return t.status, ErrWatchTimeout
+			}
+			return de.WorkflowStatusUnknown, err
+		}
+
+		t.observe(pipelineID, pipeline)
+		if isDeploymentInFinalState(t.status) {
+			return t.status, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return t.status, ErrWatchTimeout
+		case <-time.After(t.interval):
+		}
+	}
+}
+
+func (t *deploymentTracker) poll(ctx context.Context, pipelineID string) (*de.PipelineStatusResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+	pipeline, _, err := t.client.PipelineStatus(ctx, pipelineID)
+	return pipeline, err
+}
+
+// observe compares the polled pipeline with the previous poll and reports what changed. Steps are reported before
+// the deployment so that the final deployment status is the last event of a watch.
+func (t *deploymentTracker) observe(pipelineID string, pipeline *de.PipelineStatusResponse) {
+	for i, step := range pipeline.Steps {
+		if step == nil {
+			continue
+		}
+		key := step.Ref
+		if key == "" {
+			key = fmt.Sprintf("%d", i)
+		}
+		current := stepSnapshot{status: step.Status, detail: stepDetail(step)}
+		previous, seen := t.steps[key]
+		t.steps[key] = current
+		if (!seen && current.status == de.WorkflowStatusNotStarted) || previous == current {
+			continue
+		}
+		t.report(DeploymentEvent{
+			Type:           deploymentEventTypeStep,
+			DeploymentID:   pipelineID,
+			Target:         stepTarget(step),
+			Step:           step.Ref,
+			StepType:       step.Type,
+			Status:         string(current.status),
+			PreviousStatus: string(previous.status),
+			Detail:         current.detail,
+		})
+	}
+
+	if pipeline.Status != t.status {
+		t.report(DeploymentEvent{
+			Type:           deploymentEventTypeDeployment,
+			DeploymentID:   pipelineID,
+			Status:         string(pipeline.Status),
+			PreviousStatus: string(t.status),
+		})
+		t.status = pipeline.Status
+	}
+}
+
+func (t *deploymentTracker) report(event DeploymentEvent) {
+	if t.out == nil {
+		return
+	}
+	event.Timestamp = t.now()
+	if t.asJSON {
+		line, err := json.Marshal(event)
+		if err != nil {
+			return
+		}
+		_, _ = fmt.Fprintln(t.out, string(line))
+		return
+	}
+	_, _ = fmt.Fprintln(t.out, event.String())
+}
+
+// stepDetail describes the progress of a step beyond its status, e.g. the current strategy step of a canary.
+func stepDetail(step *de.PipelineStep) string {
+	switch {
+	case step.Deployment != nil && step.Deployment.TotalSteps > 0:
+		return fmt.Sprintf("strategy step %d of %d", step.Deployment.Step, step.Deployment.TotalSteps)
+	case step.Pause != nil && step.Status == de.WorkflowStatusPaused:
+		return fmt.Sprintf("paused for %d %s", step.Pause.Duration, strings.ToLower(string(step.Pause.Unit)))
+	case step.Status == de.WorkflowStatusAwaitingApproval:
+		return "awaiting approval"
+	case step.Analysis != nil && len(step.Analysis.Queries) > 0:
+		return fmt.Sprintf("queries: %s", strings.Join(step.Analysis.Queries, ", "))
+	case step.Webhook != nil && step.Webhook.Name != "":
+		return fmt.Sprintf("webhook: %s", step.Webhook.Name)
+	}
+	return ""
+}
+
+func isDeploymentInFinalState(status de.WorkflowStatus) bool {
+	switch status {
+	case de.WorkflowStatusFailed, de.WorkflowStatusSucceeded, de.WorkflowStatusCancelled:
+		return true
+	}
+	return false
+}
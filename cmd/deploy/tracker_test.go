@@ -0,0 +1,124 @@
+package deploy
+
+import (
+	"bytes"
+	"context"
+	de "github.com/armory-io/deploy-engine/pkg/api"
+	"github.com/stretchr/testify/assert"
+	nethttp "net/http"
+	"testing"
+	"time"
+)
+
+type fakePipelineStatusClient struct {
+	responses []*de.PipelineStatusResponse
+	calls     int
+}
+
+func (f *fakePipelineStatusClient) PipelineStatus(_ context.Context, _ string) (*de.PipelineStatusResponse, *nethttp.Response, error) {
+	response := f.responses[len(f.responses)-1]
+	if f.calls < len(f.responses) {
+		response = f.responses[f.calls]
+	}
+	f.calls++
+	return response, nil, nil
+}
+
+func canaryStep(status de.WorkflowStatus, step int) *de.PipelineStep {
+	return &de.PipelineStep{
+		Ref:    "deploy-prod",
+		Type:   "deployment",
+		Status: status,
+		Deployment: &de.PipelineDeploymentStepResponse{
+			ID:          "5678",
+			Environment: "prod",
+			Step:        step,
+			TotalSteps:  3,
+		},
+	}
+}
+
+func pauseStep(status de.WorkflowStatus) *de.PipelineStep {
+	return &de.PipelineStep{
+		Ref:           "pause-prod",
+		Type:          "pause",
+		Status:        status,
+		ConstraintFor: "prod",
+		Pause:         &de.PauseStepResponse{Duration: 5, Unit: de.TimeUnitMinutes},
+	}
+}
+
+func TestDeploymentTracker(t *testing.T) {
+	cases := []struct {
+		name           string
+		responses      []*de.PipelineStatusResponse
+		timeout        time.Duration
+		asJSON         bool
+		expectedStatus de.WorkflowStatus
+		expectedErr    error
+		expectedOutput string
+	}{
+		{
+			name: "reports step transitions and canary progress",
+			responses: []*de.PipelineStatusResponse{
+				{Status: de.WorkflowStatusRunning, Steps: []*de.PipelineStep{pauseStep(de.WorkflowStatusNotStarted), canaryStep(de.WorkflowStatusRunning, 1)}},
+				{Status: de.WorkflowStatusRunning, Steps: []*de.PipelineStep{pauseStep(de.WorkflowStatusNotStarted), canaryStep(de.WorkflowStatusRunning, 1)}},
+				{Status: de.WorkflowStatusPaused, Steps: []*de.PipelineStep{pauseStep(de.WorkflowStatusPaused), canaryStep(de.WorkflowStatusRunning, 2)}},
+				{Status: de.WorkflowStatusSucceeded, Steps: []*de.PipelineStep{pauseStep(de.WorkflowStatusSucceeded), canaryStep(de.WorkflowStatusSucceeded, 3)}},
+			},
+			expectedStatus: de.WorkflowStatusSucceeded,
+			expectedOutput: "[0001-01-01T00:00:00Z] Target prod: deployment step deploy-prod: RUNNING (strategy step 1 of 3)\n" +
+				"[0001-01-01T00:00:00Z] Deployment 12345: RUNNING\n" +
+				"[0001-01-01T00:00:00Z] Target prod: pause step pause-prod: NOT_STARTED -> PAUSED (paused for 5 minutes)\n" +
+				"[0001-01-01T00:00:00Z] Target prod: deployment step deploy-prod: RUNNING (strategy step 2 of 3)\n" +
+				"[0001-01-01T00:00:00Z] Deployment 12345: RUNNING -> PAUSED\n" +
+				"[0001-01-01T00:00:00Z] Target prod: pause step pause-prod: PAUSED -> SUCCEEDED\n" +
+				"[0001-01-01T00:00:00Z] Target prod: deployment step deploy-prod: RUNNING -> SUCCEEDED (strategy step 3 of 3)\n" +
+				"[0001-01-01T00:00:00Z] Deployment 12345: PAUSED -> SUCCEEDED\n",
+		},
+		{
+			name: "writes events as NDJSON",
+			responses: []*de.PipelineStatusResponse{
+				{Status: de.WorkflowStatusFailed},
+			},
+			asJSON:         true,
+			expectedStatus: de.WorkflowStatusFailed,
+			expectedOutput: `{"timestamp":"0001-01-01T00:00:00Z","type":"deployment","deploymentId":"12345","status":"FAILED"}` + "\n",
+		},
+		{
+			name: "stops at the watch timeout",
+			responses: []*de.PipelineStatusResponse{
+				{Status: de.WorkflowStatusRunning},
+			},
+			timeout:        50 * time.Millisecond,
+			expectedStatus: de.WorkflowStatusRunning,
+			expectedErr:    ErrWatchTimeout,
+			expectedOutput: "[0001-01-01T00:00:00Z] Deployment 12345: RUNNING\n",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			out := bytes.NewBufferString("")
+			tracker := newDeploymentTracker(
+				&fakePipelineStatusClient{responses: c.responses},
+				time.Millisecond,
+				func() time.Time { return time.Time{} },
+				out,
+				c.asJSON,
+			)
+
+			ctx := context.Background()
+			if c.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, c.timeout)
+				defer cancel()
+			}
+
+			status, err := tracker.track(ctx, "12345")
+			assert.ErrorIs(t, err, c.expectedErr)
+			assert.Equal(t, c.expectedStatus, status)
+			assert.Equal(t, c.expectedOutput, out.String())
+		})
+	}
+}
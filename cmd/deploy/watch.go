@@ -0,0 +1,140 @@
+package deploy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	de "github.com/armory-io/deploy-engine/pkg/api"
+	"github.com/armory/armory-cli/cmd/utils"
+	"github.com/armory/armory-cli/pkg/cmdUtils"
+	"github.com/armory/armory-cli/pkg/config"
+	deployment "github.com/armory/armory-cli/pkg/deploy"
+	errorUtils "github.com/armory/armory-cli/pkg/errors"
+	"github.com/armory/armory-cli/pkg/output"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	log "go.uber.org/zap"
+	"io"
+	"time"
+)
+
+const (
+	deployWatchShort = "Watch a running deployment until it completes"
+	deployWatchLong  = "Watch a running deployment until it completes, reporting the progress of each target and step\n\n" +
+		"Use this to follow a deployment that was started elsewhere, or to resume watching after a CI runner restarted. " +
+		"The command exits with a non-zero status if the deployment does not succeed"
+	deployWatchExample = "armory deploy watch --deploymentId [deploymentId] [--watch-timeout 30m]"
+)
+
+type deployWatchOptions struct {
+	deploymentID  string
+	watchTimeout  time.Duration
+	watchInterval time.Duration
+}
+
+func NewDeployWatchCmd(configuration *config.Configuration) *cobra.Command {
+	options := &deployWatchOptions{}
+	cmd := &cobra.Command{
+		Use:     "watch --deploymentId [deploymentId]",
+		Short:   deployWatchShort,
+		Long:    deployWatchLong,
+		Example: deployWatchExample,
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			cmdUtils.ExecuteParentHooks(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return watch(cmd, configuration, options)
+		},
+	}
+	addDeploymentIDFlag(cmd, &options.deploymentID)
+	addWatchFlags(cmd, &options.watchInterval, &options.watchTimeout)
+	return cmd
+}
+
+func addWatchFlags(cmd *cobra.Command, watchInterval, watchTimeout *time.Duration) {
+	cmd.Flags().DurationVar(watchTimeout, "watch-timeout", 0, "stop watching the deployment after this long, e.g. 30m. The deployment keeps running. Waits indefinitely if not set")
+	cmd.Flags().DurationVar(watchInterval, "watch-interval", statusCheckTick, "how often to check the status of the deployment when watching it")
+}
+
+// validateWatchInterval rejects intervals that would check the status of the deployment in a busy loop.
+func validateWatchInterval(watchInterval time.Duration) error {
+	if watchInterval <= 0 {
+		return ErrInvalidWatchInterval
+	}
+	return nil
+}
+
+func watch(cmd *cobra.Command, configuration *config.Configuration, options *deployWatchOptions) error {
+	if err := validateWatchInterval(options.watchInterval); err != nil {
+		return err
+	}
+	if *configuration.GetIsTest() {
+		utils.ConfigureLoggingForTesting(cmd)
+	}
+
+	storeCommandResult(cmd, DeployResultDeploymentID, options.deploymentID)
+
+	// if we've made it this far, the command is valid. if an error occurs it isn't a usage error
+	cmd.SilenceUsage = true
+	deployClient := deployment.NewClient(configuration)
+	return trackDeployment(cmd, configuration, FormattableDeployStartResponse{DeploymentId: options.deploymentID}, deployClient, options.watchInterval, options.watchTimeout)
+}
+
+// trackDeployment watches the deployment until it completes and writes the result. With JSON output the result is
+// written as the last line of the NDJSON event stream.
+func trackDeployment(cmd *cobra.Command, configuration *config.Configuration, deploy FormattableDeployStartResponse, deployClient pipelineStatusClient, watchInterval, watchTimeout time.Duration) error {
+	beginTrackingDeployment(cmd, configuration, &deploy, deployClient, watchInterval, watchTimeout)
+	if configuration.GetOutputType() == output.Json {
+		return outputNDJSONResult(deploy)
+	}
+	return outputCommandResult(deploy, configuration)
+}
+
+func beginTrackingDeployment(cmd *cobra.Command, configuration *config.Configuration, deploy *FormattableDeployStartResponse, deployClient pipelineStatusClient, watchInterval, watchTimeout time.Duration) {
+	outputType := configuration.GetOutputType()
+	canWriteProgress := outputType == output.Text
+	if canWriteProgress {
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "[%v] Waiting for deployment to complete. Status UI: %s\n", configuration.Now().Format(time.RFC3339), buildMonitoringUrl(configuration, deploy.DeploymentId))
+	}
+
+	var out io.Writer
+	if canWriteProgress || outputType == output.Json {
+		out = cmd.OutOrStdout()
+	}
+	tracker := newDeploymentTracker(deployClient, watchInterval, configuration.Now, out, outputType == output.Json)
+
+	ctx := context.Background()
+	if watchTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, watchTimeout)
+		defer cancel()
+	}
+
+	var reportedStatus string
+	status, err := tracker.track(ctx, deploy.DeploymentId)
+	switch {
+	case errors.Is(err, ErrWatchTimeout):
+		reportedStatus = string(status) + " (timed out)"
+	case err != nil:
+		reportedStatus = de.WorkflowStatusUnknown + " (error)"
+	default:
+		reportedStatus = string(status)
+	}
+	if canWriteProgress {
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "[%v] Deployment %s completed with status: %s\n", configuration.Now().Format(time.RFC3339), deploy.DeploymentId, reportedStatus)
+	}
+
+	deploy.ExecutionStatus = reportedStatus
+	storeCommandResult(cmd, DeployResultSyncStatus, reportedStatus)
+	storeCommandResult(cmd, DeployResultStatusCode, lo.Ternary(err == nil && status == de.WorkflowStatusSucceeded, "0", "1"))
+}
+
+func outputNDJSONResult(deploy FormattableDeployStartResponse) error {
+	line, err := json.Marshal(deploy.Get())
+	if err != nil {
+		return errorUtils.NewWrappedError(output.ErrJsonMarshal, err)
+	}
+	log.S().Info(string(line))
+	return nil
+}
@@ -0,0 +1,84 @@
+package deploy
+
+import (
+	"bytes"
+	"encoding/json"
+	de "github.com/armory-io/deploy-engine/pkg/api"
+	"github.com/jarcoal/httpmock"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestDeployWatch(t *testing.T) {
+	cases := []struct {
+		name              string
+		output            string
+		statuses          []de.WorkflowStatus
+		args              []string
+		expectedStatus    string
+		expectErrContains string
+	}{
+		{
+			name:           "deployment succeeds",
+			output:         "json",
+			statuses:       []de.WorkflowStatus{de.WorkflowStatusRunning, de.WorkflowStatusSucceeded},
+			expectedStatus: de.WorkflowStatusSucceeded,
+		},
+		{
+			name:              "deployment fails",
+			output:            "json",
+			statuses:          []de.WorkflowStatus{de.WorkflowStatusRunning, de.WorkflowStatusFailed},
+			expectedStatus:    de.WorkflowStatusFailed,
+			expectErrContains: "non-success status code returned by deploy command: 1",
+		},
+		{
+			name:              "watch times out",
+			output:            "json",
+			statuses:          lo.RepeatBy(1000, func(_ int) de.WorkflowStatus { return de.WorkflowStatusRunning }),
+			args:              []string{"--watch-timeout=20ms"},
+			expectedStatus:    de.WorkflowStatusRunning + " (timed out)",
+			expectErrContains: "non-success status code returned by deploy command: 1",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			httpmock.Activate()
+			defer httpmock.DeactivateAndReset()
+			assert.NoError(t, registerStatusResponder(c.statuses, "12345"))
+
+			cmd := NewDeployCmd(getDefaultConfiguration(c.output))
+			writer := bytes.NewBufferString("")
+			cmd.SetOut(writer)
+			cmd.SetArgs(append([]string{"watch", "--deploymentId=12345", "--watch-interval=1ms"}, c.args...))
+
+			err := cmd.Execute()
+			if c.expectErrContains != "" {
+				assert.ErrorContains(t, err, c.expectErrContains)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			lines := strings.Split(strings.TrimSpace(writer.String()), "\n")
+			var received FormattableDeployStartResponse
+			assert.NoError(t, json.Unmarshal([]byte(lines[len(lines)-1]), &received))
+			assert.Equal(t, "12345", received.DeploymentId)
+			assert.Equal(t, c.expectedStatus, received.ExecutionStatus)
+		})
+	}
+}
+
+func TestDeployWatchRejectsInvalidInterval(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	for _, interval := range []string{"0s", "-1ms"} {
+		cmd := NewDeployCmd(getDefaultConfiguration("json"))
+		cmd.SetOut(bytes.NewBufferString(""))
+		cmd.SetArgs([]string{"watch", "--deploymentId=12345", "--watch-interval=" + interval})
+		assert.ErrorIs(t, cmd.Execute(), ErrInvalidWatchInterval)
+	}
+	assert.Equal(t, 0, httpmock.GetTotalCallCount())
+}
@@ -0,0 +1,12 @@
+package format
+
+import (
+	"errors"
+)
+
+var (
+	ErrYAMLFileRead          = errors.New("error trying to read the YAML file")
+	ErrYAMLFileWrite         = errors.New("error trying to write the YAML file")
+	ErrParsingDeploymentFile = errors.New("error trying to parse the deployment file")
+	ErrUnformattedFiles      = errors.New("deployment files are not formatted, run armory fmt -w to format them")
+)
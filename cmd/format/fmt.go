@@ -0,0 +1,95 @@
+package format
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/armory/armory-cli/pkg/cmdUtils"
+	errorUtils "github.com/armory/armory-cli/pkg/errors"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+const (
+	fmtShort = "Format deployment files"
+	fmtLong  = "Format deployment files in a canonical layout, so that their diffs only show what changed\n\n" +
+		"Keys are ordered as in the schema of the kind of deployment (version, kind, application, deploymentConfig, " +
+		"targets, manifests, strategies, ...), with unknown keys after them. Mappings and sequences are written in block " +
+		"style with an indentation of two spaces, values are quoted only when needed and time units and modes are written " +
+		"in lower case. Comments are kept\n\n" +
+		"By default the formatted files are written to the standard output. With --check, the files that are not " +
+		"formatted are listed and the command fails, which can be used in CI"
+	fmtExample = "armory fmt -w -f deploy.yml"
+)
+
+type fmtOptions struct {
+	deploymentFiles []string
+	write           bool
+	check           bool
+}
+
+func NewFmtCmd() *cobra.Command {
+	options := &fmtOptions{}
+	cmd := &cobra.Command{
+		Use:     "fmt --file [<path to file>]",
+		Short:   fmtShort,
+		Long:    fmtLong,
+		Example: fmtExample,
+		GroupID: "deployment",
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			cmdUtils.ExecuteParentHooks(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return formatFiles(cmd, options)
+		},
+	}
+	cmd.Flags().StringArrayVarP(&options.deploymentFiles, "file", "f", []string{}, "path to a deployment file. Can be repeated")
+	cmd.Flags().BoolVarP(&options.write, "write", "w", false, "write the formatted files in place instead of to the standard output")
+	cmd.Flags().BoolVarP(&options.check, "check", "", false, "list the files that are not formatted and fail if there are any, without changing them")
+	cmd.MarkFlagRequired("file")
+	cmd.MarkFlagsMutuallyExclusive("write", "check")
+	return cmd
+}
+
+func formatFiles(cmd *cobra.Command, options *fmtOptions) error {
+	// if we've made it this far, the command is valid. if an error occurs it isn't a usage error
+	cmd.SilenceUsage = true
+	unformatted := 0
+	for _, deploymentFile := range options.deploymentFiles {
+		file, err := os.ReadFile(deploymentFile)
+		if err != nil {
+			return errorUtils.NewWrappedError(ErrYAMLFileRead, err)
+		}
+		formatted, err := Format(file)
+		if err != nil {
+			return errorUtils.NewWrappedError(ErrParsingDeploymentFile, fmt.Errorf("%s: %w", deploymentFile, err))
+		}
+		switch {
+		case options.check:
+			if !bytes.Equal(file, formatted) {
+				unformatted++
+				if _, err := fmt.Fprintln(cmd.OutOrStdout(), deploymentFile); err != nil {
+					return err
+				}
+			}
+		case options.write:
+			if bytes.Equal(file, formatted) {
+				continue
+			}
+			info, err := os.Stat(deploymentFile)
+			if err != nil {
+				return errorUtils.NewWrappedError(ErrYAMLFileWrite, err)
+			}
+			if err := os.WriteFile(deploymentFile, formatted, info.Mode().Perm()); err != nil {
+				return errorUtils.NewWrappedError(ErrYAMLFileWrite, err)
+			}
+		default:
+			if _, err := cmd.OutOrStdout().Write(formatted); err != nil {
+				return err
+			}
+		}
+	}
+	if unformatted > 0 {
+		return ErrUnformattedFiles
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package format
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFmtCmd(t *testing.T) {
+	dir := t.TempDir()
+	formattedFile := filepath.Join(dir, "formatted.yml")
+	unformattedFile := filepath.Join(dir, "unformatted.yml")
+	assert.NoError(t, os.WriteFile(formattedFile, []byte(formattedYamlStr), 0o644))
+	assert.NoError(t, os.WriteFile(unformattedFile, []byte(unformattedYamlStr), 0o644))
+
+	outWriter := bytes.NewBufferString("")
+	cmd := NewFmtCmd()
+	cmd.SetOut(outWriter)
+	cmd.SetArgs([]string{"--check", "-f", formattedFile, "-f", unformattedFile})
+	assert.ErrorIs(t, cmd.Execute(), ErrUnformattedFiles)
+	assert.Equal(t, unformattedFile+"\n", outWriter.String())
+
+	outWriter = bytes.NewBufferString("")
+	cmd = NewFmtCmd()
+	cmd.SetOut(outWriter)
+	cmd.SetArgs([]string{"-f", unformattedFile})
+	assert.NoError(t, cmd.Execute())
+	assert.Equal(t, formattedYamlStr, outWriter.String())
+
+	cmd = NewFmtCmd()
+	cmd.SetOut(bytes.NewBufferString(""))
+	cmd.SetArgs([]string{"-w", "-f", unformattedFile})
+	assert.NoError(t, cmd.Execute())
+	written, err := os.ReadFile(unformattedFile)
+	assert.NoError(t, err)
+	assert.Equal(t, formattedYamlStr, string(written))
+}
@@ -0,0 +1,177 @@
+package format
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/armory/armory-cli/cmd/validate"
+	"github.com/armory/armory-cli/pkg/schema"
+	"github.com/samber/lo"
+	"gopkg.in/yaml.v3"
+)
+
+const indent = 2
+
+// caseInsensitiveFields are the fields whose values the schema matches regardless of case, such as #TimeUnit, and that
+// are written in lower case.
+var caseInsensitiveFields = map[string]bool{
+	"unit":            true,
+	"units":           true,
+	"rollBackMode":    true,
+	"rollForwardMode": true,
+	"lookbackMethod":  true,
+}
+
+// Format returns the canonical form of a deployment file: keys in the order of the schema of its kind, with unknown
+// keys after them in their original order, block style mappings and sequences, indentation of two spaces, quotes only
+// where they are needed and lower case time units and modes. Comments are kept.
+func Format(file []byte) ([]byte, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(file))
+	var formatted bytes.Buffer
+	encoder := yaml.NewEncoder(&formatted)
+	encoder.SetIndent(indent)
+	for {
+		var document yaml.Node
+		if err := decoder.Decode(&document); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+		documentSchema, err := schemaOf(&document)
+		if err != nil {
+			return nil, err
+		}
+		format(&document, documentSchema)
+		if err := encoder.Encode(&document); err != nil {
+			return nil, err
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return formatted.Bytes(), nil
+}
+
+// schemaOf returns the schema of the kind of a deployment file, or nil for kinds without a schema, whose keys keep
+// their order.
+func schemaOf(document *yaml.Node) (*schema.JSONSchema, error) {
+	var requestKind struct {
+		Kind string `yaml:"kind"`
+	}
+	if err := document.Decode(&requestKind); err != nil {
+		// the document is not a mapping, there are no keys to order
+		return nil, nil
+	}
+	file, definition, ok := validate.Schema(lo.Ternary(requestKind.Kind == "", "kubernetes", requestKind.Kind))
+	if !ok {
+		return nil, nil
+	}
+	return schema.ToJSONSchema(file, definition)
+}
+
+func format(node *yaml.Node, nodeSchema *schema.JSONSchema) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, content := range node.Content {
+			keepHeader(content)
+			format(content, nodeSchema)
+		}
+	case yaml.SequenceNode:
+		node.Style = 0
+		for _, item := range node.Content {
+			format(item, itemsOf(nodeSchema))
+		}
+	case yaml.MappingNode:
+		node.Style = 0
+		sortKeys(node, propertyNames(nodeSchema))
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			format(key, nil)
+			format(value, propertyOf(nodeSchema, key.Value))
+			if caseInsensitiveFields[key.Value] && value.Kind == yaml.ScalarNode {
+				value.Value = strings.ToLower(value.Value)
+			}
+		}
+	case yaml.ScalarNode:
+		// literal and folded blocks, such as inline manifests, are kept as written. The encoder quotes the other
+		// scalars only when they would not be read back as the same value
+		if node.Style != yaml.LiteralStyle && node.Style != yaml.FoldedStyle && !isYAML11Bool(node) {
+			node.Style = 0
+		}
+	}
+}
+
+// keepHeader moves the comment above the first key of a document to the document itself, so that it stays at the top
+// of the file when the keys are sorted.
+func keepHeader(node *yaml.Node) {
+	if node.Kind != yaml.MappingNode || len(node.Content) == 0 || node.Content[0].HeadComment == "" {
+		return
+	}
+	node.HeadComment = strings.TrimSpace(node.HeadComment + "\n" + node.Content[0].HeadComment)
+	node.Content[0].HeadComment = ""
+}
+
+// isYAML11Bool tells whether a string would be read as a boolean by YAML 1.1 parsers, which needs quotes even though
+// the encoder, following YAML 1.2, does not add them.
+func isYAML11Bool(node *yaml.Node) bool {
+	if node.Tag != "!!str" {
+		return false
+	}
+	return lo.Contains([]string{"y", "yes", "n", "no", "on", "off"}, strings.ToLower(node.Value))
+}
+
+// sortKeys orders the entries of a mapping node by the position of their keys in names, keeping entries whose key is
+// not in names after the others, in their original order.
+func sortKeys(node *yaml.Node, names []string) {
+	entries := lo.Chunk(node.Content, 2)
+	position := func(entry []*yaml.Node) int {
+		if i := lo.IndexOf(names, entry[0].Value); i >= 0 {
+			return i
+		}
+		return len(names)
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return position(entries[i]) < position(entries[j]) })
+	node.Content = lo.Flatten(entries)
+}
+
+// alternatives returns the schemas a value may match: the alternatives of a disjunction, or the schema itself.
+func alternatives(nodeSchema *schema.JSONSchema) []*schema.JSONSchema {
+	if nodeSchema == nil {
+		return nil
+	}
+	if len(nodeSchema.AnyOf) > 0 {
+		return nodeSchema.AnyOf
+	}
+	return []*schema.JSONSchema{nodeSchema}
+}
+
+func propertyNames(nodeSchema *schema.JSONSchema) []string {
+	return lo.Uniq(lo.FlatMap(alternatives(nodeSchema), func(s *schema.JSONSchema, _ int) []string { return s.Properties.Names() }))
+}
+
+func propertyOf(nodeSchema *schema.JSONSchema, name string) *schema.JSONSchema {
+	for _, alternative := range alternatives(nodeSchema) {
+		if property, ok := alternative.Properties.Get(name); ok {
+			return property
+		}
+	}
+	for _, alternative := range alternatives(nodeSchema) {
+		if pattern, ok := alternative.AdditionalProperties.(*schema.JSONSchema); ok {
+			return pattern
+		}
+	}
+	return nil
+}
+
+func itemsOf(nodeSchema *schema.JSONSchema) *schema.JSONSchema {
+	for _, alternative := range alternatives(nodeSchema) {
+		if alternative.Items != nil {
+			return alternative.Items
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package format
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+const unformattedYamlStr = `# deployment of potato facts
+strategies:
+    rolling:
+        canary:
+            steps:
+                - setWeight: {weight: 100}
+                - pause: {unit: SECONDS, duration: 30}
+application: "potato-facts"   # the app
+targets:
+    prod:
+        strategy: rolling
+        account: "prod-east"
+        constraints:
+          afterDeployment:
+            - pause: {untilApproved: true}
+kind: kubernetes
+manifests:
+  - inline: |
+      apiVersion: v1
+      kind: Namespace
+  - path: ./manifests
+custom: "yes"
+version: v1
+`
+
+const formattedYamlStr = `# deployment of potato facts
+version: v1
+kind: kubernetes
+application: potato-facts # the app
+targets:
+  prod:
+    account: prod-east
+    strategy: rolling
+    constraints:
+      afterDeployment:
+        - pause:
+            untilApproved: true
+manifests:
+  - inline: |
+      apiVersion: v1
+      kind: Namespace
+  - path: ./manifests
+strategies:
+  rolling:
+    canary:
+      steps:
+        - setWeight:
+            weight: 100
+        - pause:
+            duration: 30
+            unit: seconds
+custom: "yes"
+`
+
+func TestFormat(t *testing.T) {
+	formatted, err := Format([]byte(unformattedYamlStr))
+	assert.NoError(t, err)
+	assert.Equal(t, formattedYamlStr, string(formatted))
+
+	// formatting is idempotent
+	formatted, err = Format(formatted)
+	assert.NoError(t, err)
+	assert.Equal(t, formattedYamlStr, string(formatted))
+}
+
+func TestFormatWithoutSchema(t *testing.T) {
+	// keys of kinds without a schema keep their order
+	formatted, err := Format([]byte("kind: other\napplication: 'app'\nsteps: [{b: 1, a: 2}]\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, "kind: other\napplication: app\nsteps:\n  - b: 1\n    a: 2\n", string(formatted))
+
+	_, err = Format([]byte("kind: [kubernetes\n"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,11 @@
+package graph
+
+import (
+	"errors"
+)
+
+var (
+	ErrYAMLFileRead          = errors.New("error trying to read the YAML file")
+	ErrParsingDeploymentFile = errors.New("error trying to parse the deployment file")
+	ErrUnknownGraphFormat    = errors.New("unknown graph format, expected mermaid, dot or text")
+)
@@ -0,0 +1,70 @@
+package graph
+
+import (
+	"github.com/armory/armory-cli/cmd/utils"
+	"github.com/armory/armory-cli/pkg/cmdUtils"
+	errorUtils "github.com/armory/armory-cli/pkg/errors"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+const (
+	graphShort = "Draw the targets of a deployment and the steps between them"
+	graphLong  = "Draw the targets of a deployment file, their dependsOn edges, the steps before and after their deployment " +
+		"and the steps of their strategy\n\n" +
+		"The mermaid format can be pasted in a mermaid code block of a pull request description. With --step-summary, " +
+		"the graph is also added to the summary of the current GitHub Actions job"
+	graphExample = "armory graph -f deploy.yml --format mermaid"
+)
+
+type graphOptions struct {
+	deploymentFile string
+	format         string
+	stepSummary    bool
+}
+
+func NewGraphCmd() *cobra.Command {
+	options := &graphOptions{}
+	cmd := &cobra.Command{
+		Use:     "graph --file [<path to file>]",
+		Short:   graphShort,
+		Long:    graphLong,
+		Example: graphExample,
+		GroupID: "deployment",
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			cmdUtils.ExecuteParentHooks(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return graph(cmd, options)
+		},
+	}
+	cmd.Flags().StringVarP(&options.deploymentFile, "file", "f", "", "path to the deployment file")
+	cmd.Flags().StringVarP(&options.format, "format", "", formatMermaid, "format of the graph, one of mermaid, dot or text")
+	cmd.Flags().BoolVarP(&options.stepSummary, "step-summary", "", false, "add the graph, in the mermaid format, to the GitHub Actions job summary")
+	cmd.MarkFlagRequired("file")
+	return cmd
+}
+
+func graph(cmd *cobra.Command, options *graphOptions) error {
+	file, err := os.ReadFile(options.deploymentFile)
+	if err != nil {
+		return errorUtils.NewWrappedError(ErrYAMLFileRead, err)
+	}
+	topology, err := NewTopology(file)
+	if err != nil {
+		return errorUtils.NewWrappedError(ErrParsingDeploymentFile, err)
+	}
+
+	rendered, ok := topology.Render(options.format)
+	if !ok {
+		return errorUtils.NewErrorWithDynamicContext(ErrUnknownGraphFormat, ": "+options.format)
+	}
+
+	// if we've made it this far, the command is valid. if an error occurs it isn't a usage error
+	cmd.SilenceUsage = true
+	if options.stepSummary {
+		utils.TryWriteGitHubStepSummary("```mermaid\n" + topology.Mermaid() + "```")
+	}
+	_, err = cmd.OutOrStdout().Write([]byte(rendered))
+	return err
+}
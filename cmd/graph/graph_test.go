@@ -0,0 +1,35 @@
+package graph
+
+import (
+	"bytes"
+	"github.com/armory/armory-cli/cmd/utils"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGraphCmd(t *testing.T) {
+	dir := t.TempDir()
+	deploymentFile := filepath.Join(dir, "deploy.yml")
+	assert.NoError(t, os.WriteFile(deploymentFile, []byte(graphYamlStr), 0o644))
+	summaryFile := filepath.Join(dir, "summary.md")
+	t.Setenv(utils.GithubSummary, summaryFile)
+
+	outWriter := bytes.NewBufferString("")
+	cmd := NewGraphCmd()
+	cmd.SetOut(outWriter)
+	cmd.SetArgs([]string{"-f", deploymentFile, "--format", "text", "--step-summary"})
+	assert.NoError(t, cmd.Execute())
+	topology, err := NewTopology([]byte(graphYamlStr))
+	assert.NoError(t, err)
+	assert.Equal(t, topology.Text(), outWriter.String())
+	summary, err := os.ReadFile(summaryFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "```mermaid\n"+topology.Mermaid()+"```\n", string(summary))
+
+	cmd = NewGraphCmd()
+	cmd.SetOut(bytes.NewBufferString(""))
+	cmd.SetArgs([]string{"-f", deploymentFile, "--format", "svg"})
+	assert.ErrorIs(t, cmd.Execute(), ErrUnknownGraphFormat)
+}
@@ -0,0 +1,150 @@
+package graph
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+const (
+	formatMermaid = "mermaid"
+	formatDot     = "dot"
+	formatText    = "text"
+
+	stepSeparator = " → "
+)
+
+// node is a box of a rendered graph: a target, or the steps that run before or after the deployment of a target.
+type node struct {
+	id    string
+	lines []string
+	// constraint tells the steps around a target apart from the target itself.
+	constraint bool
+}
+
+type edge struct {
+	from, to string
+}
+
+// graph lays out a topology as nodes and edges. Targets that depend on another target follow the steps after its
+// deployment, and are preceded by the steps before their own deployment.
+func (t *Topology) graph() ([]node, []edge) {
+	var nodes []node
+	var edges []edge
+	first, last := map[string]string{}, map[string]string{}
+	for i, target := range t.Targets {
+		id := "t" + strconv.Itoa(i)
+		first[target.Name], last[target.Name] = id, id
+		if len(target.Before) > 0 {
+			nodes = append(nodes, node{id: id + "_before", lines: []string{"before " + target.Name, strings.Join(target.Before, stepSeparator)}, constraint: true})
+			edges = append(edges, edge{id + "_before", id})
+			first[target.Name] = id + "_before"
+		}
+		nodes = append(nodes, node{id: id, lines: targetLines(target)})
+		if len(target.After) > 0 {
+			nodes = append(nodes, node{id: id + "_after", lines: []string{"after " + target.Name, strings.Join(target.After, stepSeparator)}, constraint: true})
+			edges = append(edges, edge{id, id + "_after"})
+			last[target.Name] = id + "_after"
+		}
+	}
+	for _, target := range t.Targets {
+		for _, dependency := range target.DependsOn {
+			if from, ok := last[dependency]; ok {
+				edges = append(edges, edge{from, first[target.Name]})
+			}
+		}
+	}
+	return nodes, edges
+}
+
+func targetLines(target Target) []string {
+	lines := []string{target.Name}
+	if target.Account != "" {
+		lines = append(lines, "account "+target.Account)
+	}
+	if target.Strategy != "" {
+		lines = append(lines, strings.TrimSpace(fmt.Sprintf("%s: %s", target.Strategy, strings.Join(target.Steps, stepSeparator))))
+	}
+	return lines
+}
+
+// Mermaid renders the topology as a Mermaid flowchart, which GitHub draws in Markdown code blocks of type mermaid.
+func (t *Topology) Mermaid() string {
+	nodes, edges := t.graph()
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	for _, n := range nodes {
+		label := strings.Join(lo.Map(n.lines, func(line string, _ int) string {
+			return strings.ReplaceAll(line, `"`, "#quot;")
+		}), "<br/>")
+		if n.constraint {
+			fmt.Fprintf(&b, "  %s>\"%s\"]\n", n.id, label)
+		} else {
+			fmt.Fprintf(&b, "  %s[\"%s\"]\n", n.id, label)
+		}
+	}
+	for _, e := range edges {
+		fmt.Fprintf(&b, "  %s --> %s\n", e.from, e.to)
+	}
+	return b.String()
+}
+
+// Dot renders the topology as a Graphviz digraph.
+func (t *Topology) Dot() string {
+	nodes, edges := t.graph()
+	var b strings.Builder
+	b.WriteString("digraph deployment {\n  rankdir=LR;\n  node [shape=box];\n")
+	for _, n := range nodes {
+		label := strings.Join(lo.Map(n.lines, func(line string, _ int) string {
+			return strings.ReplaceAll(strings.ReplaceAll(line, `\`, `\\`), `"`, `\"`)
+		}), `\n`)
+		style := lo.Ternary(n.constraint, ", style=dashed", "")
+		fmt.Fprintf(&b, "  %s [label=\"%s\"%s];\n", n.id, label, style)
+	}
+	for _, e := range edges {
+		fmt.Fprintf(&b, "  %s -> %s;\n", e.from, e.to)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// Text renders the topology as an indented list of targets.
+func (t *Topology) Text() string {
+	var b strings.Builder
+	for _, target := range t.Targets {
+		b.WriteString(target.Name)
+		if target.Account != "" {
+			fmt.Fprintf(&b, " (account %s)", target.Account)
+		}
+		b.WriteString("\n")
+		if len(target.DependsOn) > 0 {
+			fmt.Fprintf(&b, "  depends on: %s\n", strings.Join(target.DependsOn, ", "))
+		}
+		if len(target.Before) > 0 {
+			fmt.Fprintf(&b, "  before deployment: %s\n", strings.Join(target.Before, stepSeparator))
+		}
+		if target.Strategy != "" {
+			fmt.Fprintf(&b, "  strategy %s: %s\n", target.Strategy, strings.Join(target.Steps, stepSeparator))
+		}
+		if len(target.After) > 0 {
+			fmt.Fprintf(&b, "  after deployment: %s\n", strings.Join(target.After, stepSeparator))
+		}
+	}
+	return b.String()
+}
+
+// Render renders the topology in a format.
+func (t *Topology) Render(format string) (string, bool) {
+	switch format {
+	case formatMermaid:
+		return t.Mermaid(), true
+	case formatDot:
+		return t.Dot(), true
+	case formatText:
+		return t.Text(), true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,113 @@
+package graph
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/armory/armory-cli/pkg/util"
+	"github.com/samber/lo"
+	"gopkg.in/yaml.v3"
+)
+
+// Topology is the promotion graph of a deployment: its targets, in the order of the deployment file, and the steps
+// that run around and during their deployment.
+type Topology struct {
+	Targets []Target
+}
+
+type Target struct {
+	Name      string
+	Account   string
+	DependsOn []string
+	Before    []string
+	Strategy  string
+	// Steps describe the steps of the strategy of the target, in order.
+	Steps []string
+	After []string
+}
+
+// NewTopology reads the topology of a deployment file. Missing or malformed sections are left out, armory validate
+// reports them.
+func NewTopology(file []byte) (*Topology, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(file, &document); err != nil {
+		return nil, err
+	}
+	topology := &Topology{Targets: []Target{}}
+	if len(document.Content) == 0 {
+		return topology, nil
+	}
+	root := document.Content[0]
+	strategies := util.MappingValue(root, "strategies")
+	targets := util.MappingValue(root, "targets")
+	if targets.Kind != yaml.MappingNode {
+		return topology, nil
+	}
+	for i := 0; i+1 < len(targets.Content); i += 2 {
+		name, node := targets.Content[i].Value, targets.Content[i+1]
+		constraints := util.MappingValue(node, "constraints")
+		strategy := util.MappingValue(node, "strategy").Value
+		topology.Targets = append(topology.Targets, Target{
+			Name:      name,
+			Account:   util.MappingValue(node, "account").Value,
+			DependsOn: scalars(util.MappingValue(constraints, "dependsOn")),
+			Before:    describeSteps(util.MappingValue(constraints, "beforeDeployment")),
+			Strategy:  strategy,
+			Steps:     describeStrategy(util.MappingValue(strategies, strategy)),
+			After:     describeSteps(util.MappingValue(constraints, "afterDeployment")),
+		})
+	}
+	return topology, nil
+}
+
+func scalars(node *yaml.Node) []string {
+	return lo.Map(util.SequenceItems(node), func(item *yaml.Node, _ int) string { return item.Value })
+}
+
+func describeStrategy(strategy *yaml.Node) []string {
+	if canary := util.MappingValue(strategy, "canary"); canary.Kind != 0 {
+		return describeSteps(util.MappingValue(canary, "steps"))
+	}
+	blueGreen := util.MappingValue(strategy, "blueGreen")
+	if blueGreen.Kind == 0 {
+		return nil
+	}
+	steps := []string{"deploy preview"}
+	steps = append(steps, describeSteps(util.MappingValue(blueGreen, "redirectTrafficAfter"))...)
+	steps = append(steps, "redirect traffic")
+	steps = append(steps, describeSteps(util.MappingValue(blueGreen, "shutDownOldVersionAfter"))...)
+	return append(steps, "shut down old version")
+}
+
+func describeSteps(steps *yaml.Node) []string {
+	return lo.Map(util.SequenceItems(steps), func(step *yaml.Node, _ int) string { return describeStep(step) })
+}
+
+// describeStep returns a short description of a step, such as "weight 25%" or "pause 30 seconds".
+func describeStep(step *yaml.Node) string {
+	if step.Kind != yaml.MappingNode || len(step.Content) < 2 {
+		return step.Value
+	}
+	kind, value := step.Content[0].Value, step.Content[1]
+	field := func(name string) string { return util.MappingValue(value, name).Value }
+	switch kind {
+	case "setWeight":
+		return fmt.Sprintf("weight %s%%", field("weight"))
+	case "pause":
+		if field("untilApproved") == "true" {
+			if roles := scalars(util.MappingValue(value, "requiresRoles")); len(roles) > 0 {
+				return "approval by " + strings.Join(roles, ", ")
+			}
+			return "approval"
+		}
+		return strings.TrimSpace(fmt.Sprintf("pause %s %s", field("duration"), strings.ToLower(field("unit"))))
+	case "analysis":
+		return "analysis " + strings.Join(scalars(util.MappingValue(value, "queries")), ", ")
+	case "runWebhook":
+		return "webhook " + field("name")
+	case "exposeServices":
+		return "expose " + strings.Join(scalars(util.MappingValue(value, "services")), ", ")
+	default:
+		return kind
+	}
+}
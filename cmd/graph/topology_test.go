@@ -0,0 +1,116 @@
+package graph
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+const graphYamlStr = `version: v1
+kind: kubernetes
+application: potato-facts
+targets:
+  staging:
+    account: staging
+    strategy: rolling
+    constraints:
+      afterDeployment:
+        - runWebhook:
+            name: "smoke tests"
+  prod:
+    account: prod-east
+    strategy: canary
+    constraints:
+      dependsOn: ["staging"]
+      beforeDeployment:
+        - pause:
+            untilApproved: true
+            requiresRoles: ["release"]
+manifests:
+  - path: ./manifests
+strategies:
+  rolling:
+    canary:
+      steps:
+        - setWeight:
+            weight: 100
+  canary:
+    canary:
+      steps:
+        - setWeight:
+            weight: 25
+        - pause:
+            duration: 30
+            unit: SECONDS
+        - analysis:
+            queries: [errorRate]
+        - setWeight:
+            weight: 100
+`
+
+func TestNewTopology(t *testing.T) {
+	topology, err := NewTopology([]byte(graphYamlStr))
+	assert.NoError(t, err)
+	assert.Equal(t, []Target{
+		{Name: "staging", Account: "staging", Strategy: "rolling", Steps: []string{"weight 100%"}, After: []string{"webhook smoke tests"}, DependsOn: []string{}, Before: []string{}},
+		{Name: "prod", Account: "prod-east", Strategy: "canary", DependsOn: []string{"staging"}, Before: []string{"approval by release"},
+			Steps: []string{"weight 25%", "pause 30 seconds", "analysis errorRate", "weight 100%"}, After: []string{}},
+	}, topology.Targets)
+}
+
+func TestRender(t *testing.T) {
+	topology, err := NewTopology([]byte(graphYamlStr))
+	assert.NoError(t, err)
+
+	assert.Equal(t, `flowchart LR
+  t0["staging<br/>account staging<br/>rolling: weight 100%"]
+  t0_after>"after staging<br/>webhook smoke tests"]
+  t1_before>"before prod<br/>approval by release"]
+  t1["prod<br/>account prod-east<br/>canary: weight 25% → pause 30 seconds → analysis errorRate → weight 100%"]
+  t0 --> t0_after
+  t1_before --> t1
+  t0_after --> t1_before
+`, topology.Mermaid())
+
+	assert.Equal(t, `digraph deployment {
+  rankdir=LR;
+  node [shape=box];
+  t0 [label="staging\naccount staging\nrolling: weight 100%"];
+  t0_after [label="after staging\nwebhook smoke tests", style=dashed];
+  t1_before [label="before prod\napproval by release", style=dashed];
+  t1 [label="prod\naccount prod-east\ncanary: weight 25% → pause 30 seconds → analysis errorRate → weight 100%"];
+  t0 -> t0_after;
+  t1_before -> t1;
+  t0_after -> t1_before;
+}
+`, topology.Dot())
+
+	assert.Equal(t, `staging (account staging)
+  strategy rolling: weight 100%
+  after deployment: webhook smoke tests
+prod (account prod-east)
+  depends on: staging
+  before deployment: approval by release
+  strategy canary: weight 25% → pause 30 seconds → analysis errorRate → weight 100%
+`, topology.Text())
+}
+
+func TestDescribeBlueGreenStrategy(t *testing.T) {
+	topology, err := NewTopology([]byte(`targets:
+  prod:
+    strategy: bluegreen
+strategies:
+  bluegreen:
+    blueGreen:
+      redirectTrafficAfter:
+        - exposeServices:
+            services: [potato-facts-preview]
+        - pause:
+            untilApproved: true
+      shutDownOldVersionAfter:
+        - pause:
+            duration: 1
+            unit: hours
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"deploy preview", "expose potato-facts-preview", "approval", "redirect traffic", "pause 1 hours", "shut down old version"}, topology.Targets[0].Steps)
+}
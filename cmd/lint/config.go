@@ -0,0 +1,118 @@
+package lint
+
+import (
+	"errors"
+	"fmt"
+	errorUtils "github.com/armory/armory-cli/pkg/errors"
+	"github.com/armory/armory-cli/pkg/findings"
+	"gopkg.in/yaml.v3"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+const (
+	// ConfigFileName is the lint configuration looked up from the directory of the deployment file to the root.
+	ConfigFileName = ".armory-lint.yaml"
+
+	severityOff = "off"
+)
+
+// defaultProductionTargets are the name patterns of the targets treated as production-like when the configuration
+// does not set them.
+var defaultProductionTargets = []string{"*prod*"}
+
+// Config is the lint configuration, e.g.
+//
+//	rules:
+//	  missing-timeout: off
+//	  approval-without-roles: error
+//	productionTargets: ["prod-*", "live"]
+type Config struct {
+	// Rules sets the severity of rules by name, or turns them off.
+	Rules map[string]string `yaml:"rules"`
+	// ProductionTargets are the name patterns, as matched by path.Match, of production-like targets.
+	ProductionTargets []string `yaml:"productionTargets"`
+}
+
+// severity returns the severity a rule reports with, and whether it is turned on.
+func (c Config) severity(r rule) (findings.Severity, bool) {
+	configured, ok := c.Rules[r.name]
+	if !ok {
+		return r.severity, true
+	}
+	return findings.Severity(configured), configured != severityOff
+}
+
+func (c Config) isProductionTarget(name string) bool {
+	patterns := c.ProductionTargets
+	if patterns == nil {
+		patterns = defaultProductionTargets
+	}
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+func (c Config) validate() error {
+	for name, severity := range c.Rules {
+		if _, ok := ruleNamed(name); !ok {
+			return errorUtils.NewErrorWithDynamicContext(ErrUnknownLintRule, ": "+name)
+		}
+		switch findings.Severity(severity) {
+		case findings.SeverityError, findings.SeverityWarning, findings.SeverityNote, severityOff:
+		default:
+			return errorUtils.NewErrorWithDynamicContext(ErrInvalidLintSeverity, fmt.Sprintf(": %s: %s", name, severity))
+		}
+	}
+	for _, pattern := range c.ProductionTargets {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return errorUtils.NewWrappedError(ErrReadingLintConfig, fmt.Errorf("productionTargets: %w", err))
+		}
+	}
+	return nil
+}
+
+// LoadConfig reads a lint configuration file. When no file is given, the nearest .armory-lint.yaml from the
+// directory of the deployment file up is used, if any.
+func LoadConfig(configFile, deploymentFile string) (Config, error) {
+	if configFile == "" {
+		configFile = findConfig(deploymentFile)
+		if configFile == "" {
+			return Config{}, nil
+		}
+	}
+	contents, err := os.ReadFile(configFile)
+	if err != nil {
+		return Config{}, errorUtils.NewWrappedError(ErrReadingLintConfig, err)
+	}
+	var config Config
+	if err := yaml.Unmarshal(contents, &config); err != nil {
+		return Config{}, errorUtils.NewWrappedError(ErrReadingLintConfig, err)
+	}
+	return config, config.validate()
+}
+
+func findConfig(deploymentFile string) string {
+	dir, err := filepath.Abs(filepath.Dir(deploymentFile))
+	if err != nil {
+		return ""
+	}
+	for {
+		candidate := filepath.Join(dir, ConfigFileName)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return ""
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
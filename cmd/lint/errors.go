@@ -0,0 +1,13 @@
+package lint
+
+import (
+	"errors"
+)
+
+var (
+	ErrLintFindings          = errors.New("the deployment file has lint errors")
+	ErrReadingLintConfig     = errors.New("error trying to read the lint configuration")
+	ErrUnknownLintRule       = errors.New("unknown lint rule in the lint configuration")
+	ErrInvalidLintSeverity   = errors.New("invalid lint rule severity, expected one of error, warning, note or off")
+	ErrParsingDeploymentFile = errors.New("error trying to parse the deployment file")
+)
@@ -0,0 +1,120 @@
+package lint
+
+import (
+	"fmt"
+	"github.com/armory/armory-cli/cmd/utils"
+	"github.com/armory/armory-cli/cmd/validate"
+	"github.com/armory/armory-cli/cmd/version"
+	"github.com/armory/armory-cli/pkg/cmdUtils"
+	"github.com/armory/armory-cli/pkg/config"
+	errorUtils "github.com/armory/armory-cli/pkg/errors"
+	"github.com/armory/armory-cli/pkg/findings"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	"net/http"
+	"os"
+	"strings"
+)
+
+const (
+	lintShort   = "Check a deployment file against best practices"
+	lintExample = "armory lint --file deploy.yml"
+)
+
+var lintLong = "Check a deployment file against best practices, beyond what armory validate requires\n\n" +
+	"Each rule can be turned off or given a severity of error, warning or note in a " + ConfigFileName + " file, " +
+	"looked up from the directory of the deployment file to the root. Findings of severity error make the command fail. " +
+	"Targets whose name contains prod are production-like, unless name patterns are set in productionTargets.\n\n" +
+	"Rules:\n" + strings.Join(lo.Map(rules, func(r rule, _ int) string {
+	return fmt.Sprintf("  %-25s %s (default %s)", r.name, r.description, r.severity)
+}), "\n")
+
+type lintOptions struct {
+	deploymentFile string
+	configFile     string
+}
+
+func NewLintCmd(configuration *config.Configuration) *cobra.Command {
+	options := &lintOptions{}
+	cmd := &cobra.Command{
+		Use:     "lint --file [<path to file>]",
+		Aliases: []string{"lint"},
+		Short:   lintShort,
+		Long:    lintLong,
+		Example: lintExample,
+		GroupID: "deployment",
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			cmdUtils.ExecuteParentHooks(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return lint(cmd, configuration, options)
+		},
+	}
+	cmd.Flags().StringVarP(&options.deploymentFile, "file", "f", "", "path to the deployment file")
+	cmd.Flags().StringVarP(&options.configFile, "config", "", "", "path to the lint configuration, by default the nearest "+ConfigFileName)
+	cmd.MarkFlagRequired("file")
+	return cmd
+}
+
+func lint(cmd *cobra.Command, configuration *config.Configuration, options *lintOptions) error {
+	if *configuration.GetIsTest() {
+		utils.ConfigureLoggingForTesting(cmd)
+	}
+	file, err := os.ReadFile(options.deploymentFile)
+	if err != nil {
+		return err
+	}
+	lintConfig, err := LoadConfig(options.configFile, options.deploymentFile)
+	if err != nil {
+		return err
+	}
+
+	// if we've made it this far, the command is valid. if an error occurs it isn't a usage error
+	cmd.SilenceUsage = true
+	found, err := Lint(file, lintConfig)
+	if err != nil {
+		return errorUtils.NewWrappedError(ErrParsingDeploymentFile, err)
+	}
+	for i := range found {
+		found[i].File = options.deploymentFile
+	}
+	if err := validate.LogFindings(configuration, FormattableLintResult{Findings: found}, found); err != nil {
+		return err
+	}
+	if findings.HasErrors(found) {
+		return ErrLintFindings
+	}
+	return nil
+}
+
+type FormattableLintResult struct {
+	Findings     []findings.Finding `json:"findings" yaml:"findings"`
+	httpResponse *http.Response
+	err          error
+}
+
+func (r FormattableLintResult) Get() interface{} {
+	return r.Findings
+}
+
+func (r FormattableLintResult) GetHttpResponse() *http.Response {
+	return r.httpResponse
+}
+
+func (r FormattableLintResult) GetFetchError() error {
+	return r.err
+}
+
+func (r FormattableLintResult) SarifLog() any {
+	return findings.NewSarifLog("armory-lint", version.Version, r.Findings)
+}
+
+func (r FormattableLintResult) String() string {
+	if len(r.Findings) == 0 {
+		return "No lint findings."
+	}
+	descriptions := lo.Map(r.Findings, func(f findings.Finding, _ int) string {
+		return fmt.Sprintf("%s: %s [%s]", f.Severity, f.String(), f.Rule)
+	})
+	return strings.Join(descriptions, "\n")
+}
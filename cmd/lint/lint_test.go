@@ -0,0 +1,59 @@
+package lint
+
+import (
+	"bytes"
+	"github.com/armory/armory-cli/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func getTestConfig(output string) *config.Configuration {
+	token := "some-token"
+	addr := "https://localhost"
+	clientId := ""
+	clientSecret := ""
+	isTest := true
+	return config.New(&config.Input{
+		AccessToken:  &token,
+		ApiAddr:      &addr,
+		ClientId:     &clientId,
+		ClientSecret: &clientSecret,
+		OutFormat:    &output,
+		IsTest:       &isTest,
+	})
+}
+
+func TestLintCmd(t *testing.T) {
+	t.Setenv("GITHUB_ACTIONS", "")
+	repo := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(repo, "deploy"), 0o755))
+	deploymentFile := filepath.Join(repo, "deploy", "deploy.yml")
+	assert.NoError(t, os.WriteFile(deploymentFile, []byte(lintYamlStr), 0o644))
+
+	// the configuration is found in a parent directory of the deployment file
+	assert.NoError(t, os.WriteFile(filepath.Join(repo, ConfigFileName), []byte(`rules:
+  canary-without-analysis: off
+  approval-without-roles: off
+  non-increasing-weights: off
+  steps-after-full-weight: off
+  webhook-without-retries: off
+  missing-timeout: error
+`), 0o644))
+
+	outWriter := bytes.NewBufferString("")
+	cmd := NewLintCmd(getTestConfig("text"))
+	cmd.SetOut(outWriter)
+	cmd.SetArgs([]string{"--file", deploymentFile})
+	assert.ErrorIs(t, cmd.Execute(), ErrLintFindings)
+	assert.Equal(t, "error: deploymentConfig.timeout: line 1, column 1: deploymentConfig.timeout is not set, deployments that never become ready are not rolled back until the default timeout [missing-timeout]\n", outWriter.String())
+
+	// warnings don't fail the command
+	configFile := filepath.Join(repo, "lint.yaml")
+	assert.NoError(t, os.WriteFile(configFile, []byte("rules:\n  missing-timeout: warning\n"), 0o644))
+	cmd = NewLintCmd(getTestConfig("text"))
+	cmd.SetOut(bytes.NewBufferString(""))
+	cmd.SetArgs([]string{"--file", deploymentFile, "--config", configFile})
+	assert.NoError(t, cmd.Execute())
+}
@@ -0,0 +1,204 @@
+package lint
+
+import (
+	"fmt"
+	"github.com/armory/armory-cli/pkg/findings"
+	"github.com/armory/armory-cli/pkg/util"
+	"github.com/samber/lo"
+	"gopkg.in/yaml.v3"
+	"strconv"
+)
+
+const (
+	ruleCanaryWithoutAnalysis = "canary-without-analysis"
+	ruleApprovalWithoutRoles  = "approval-without-roles"
+	ruleMissingTimeout        = "missing-timeout"
+	ruleNonIncreasingWeights  = "non-increasing-weights"
+	ruleStepsAfterFullWeight  = "steps-after-full-weight"
+	ruleWebhookWithoutRetries = "webhook-without-retries"
+)
+
+// rule is a best practice that deployment files are checked against.
+type rule struct {
+	name        string
+	description string
+	severity    findings.Severity
+	check       func(l *linter, root *yaml.Node)
+}
+
+var rules = []rule{
+	{ruleCanaryWithoutAnalysis, "canary strategies should have an analysis step", findings.SeverityWarning, checkCanaryAnalysis},
+	{ruleApprovalWithoutRoles, "manual approvals of production-like targets should require roles", findings.SeverityWarning, checkApprovalRoles},
+	{ruleMissingTimeout, "deploymentConfig.timeout should be set", findings.SeverityWarning, checkTimeout},
+	{ruleNonIncreasingWeights, "canary weights should increase from step to step", findings.SeverityWarning, checkWeights},
+	{ruleStepsAfterFullWeight, "canary steps should not run after setWeight 100", findings.SeverityWarning, checkStepsAfterFullWeight},
+	{ruleWebhookWithoutRetries, "webhooks should be retried", findings.SeverityWarning, checkWebhookRetries},
+}
+
+func ruleNamed(name string) (rule, bool) {
+	return lo.Find(rules, func(r rule) bool { return r.name == name })
+}
+
+type linter struct {
+	config   Config
+	current  rule
+	findings []findings.Finding
+}
+
+// Lint checks a deployment file against the rules turned on by the configuration.
+func Lint(file []byte, config Config) ([]findings.Finding, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(file, &document); err != nil {
+		return nil, err
+	}
+	l := &linter{config: config, findings: []findings.Finding{}}
+	if len(document.Content) == 0 {
+		return l.findings, nil
+	}
+	for _, r := range rules {
+		l.current = r
+		if _, on := config.severity(r); on {
+			r.check(l, document.Content[0])
+		}
+	}
+	return l.findings, nil
+}
+
+func (l *linter) report(path string, node *yaml.Node, format string, args ...any) {
+	severity, _ := l.config.severity(l.current)
+	l.findings = append(l.findings, findings.Finding{
+		Path:     path,
+		Line:     node.Line,
+		Column:   node.Column,
+		Message:  fmt.Sprintf(format, args...),
+		Severity: severity,
+		Rule:     l.current.name,
+	})
+}
+
+// step is a step of a strategy or a constraint of a target, with its path in the deployment file.
+type step struct {
+	path string
+	node *yaml.Node
+}
+
+func stepsOf(node *yaml.Node, path string) []step {
+	return lo.Map(util.SequenceItems(node), func(item *yaml.Node, i int) step {
+		return step{path: fmt.Sprintf("%s[%d]", path, i), node: item}
+	})
+}
+
+// strategySteps returns the steps of every phase of a strategy.
+func strategySteps(strategy *yaml.Node, path string) []step {
+	steps := stepsOf(util.MappingValue(util.MappingValue(strategy, "canary"), "steps"), path+".canary.steps")
+	blueGreen := util.MappingValue(strategy, "blueGreen")
+	for _, phase := range []string{"redirectTrafficAfter", "shutDownOldVersionAfter"} {
+		steps = append(steps, stepsOf(util.MappingValue(blueGreen, phase), path+".blueGreen."+phase)...)
+	}
+	return steps
+}
+
+// forEachEntry calls f with the key and value of each entry of a mapping node.
+func forEachEntry(node *yaml.Node, f func(key, value *yaml.Node)) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		f(node.Content[i], node.Content[i+1])
+	}
+}
+
+func checkCanaryAnalysis(l *linter, root *yaml.Node) {
+	forEachEntry(util.MappingValue(root, "strategies"), func(name, strategy *yaml.Node) {
+		canary := util.MappingValue(strategy, "canary")
+		if canary.Kind != yaml.MappingNode {
+			return
+		}
+		steps := util.SequenceItems(util.MappingValue(canary, "steps"))
+		if !lo.SomeBy(steps, func(s *yaml.Node) bool { return util.MappingValue(s, "analysis").Kind != 0 }) {
+			l.report("strategies."+name.Value+".canary", name, "canary strategy %q has no analysis step, its new version is only checked by hand", name.Value)
+		}
+	})
+}
+
+func checkApprovalRoles(l *linter, root *yaml.Node) {
+	strategies := util.MappingValue(root, "strategies")
+	checked := map[*yaml.Node]bool{}
+	check := func(target string, steps []step) {
+		for _, s := range steps {
+			pause := util.MappingValue(s.node, "pause")
+			untilApproved := util.MappingValue(pause, "untilApproved")
+			if checked[untilApproved] || untilApproved.Value != "true" || util.MappingValue(pause, "requiresRoles").Kind != 0 {
+				continue
+			}
+			checked[untilApproved] = true
+			l.report(s.path+".pause.untilApproved", untilApproved, "approval of production-like target %q does not require roles, anyone can approve it", target)
+		}
+	}
+
+	forEachEntry(util.MappingValue(root, "targets"), func(name, target *yaml.Node) {
+		if !l.config.isProductionTarget(name.Value) {
+			return
+		}
+		path := "targets." + name.Value + ".constraints"
+		constraints := util.MappingValue(target, "constraints")
+		check(name.Value, stepsOf(util.MappingValue(constraints, "beforeDeployment"), path+".beforeDeployment"))
+		check(name.Value, stepsOf(util.MappingValue(constraints, "afterDeployment"), path+".afterDeployment"))
+		strategy := util.MappingValue(target, "strategy").Value
+		check(name.Value, strategySteps(util.MappingValue(strategies, strategy), "strategies."+strategy))
+	})
+}
+
+func checkTimeout(l *linter, root *yaml.Node) {
+	deploymentConfig := util.MappingValue(root, "deploymentConfig")
+	if util.MappingValue(deploymentConfig, "timeout").Kind != 0 {
+		return
+	}
+	node := root
+	if deploymentConfig.Kind != 0 {
+		node = deploymentConfig
+	}
+	l.report("deploymentConfig.timeout", node, "deploymentConfig.timeout is not set, deployments that never become ready are not rolled back until the default timeout")
+}
+
+func checkWeights(l *linter, root *yaml.Node) {
+	forEachEntry(util.MappingValue(root, "strategies"), func(name, strategy *yaml.Node) {
+		previous := 0
+		for _, s := range stepsOf(util.MappingValue(util.MappingValue(strategy, "canary"), "steps"), "strategies."+name.Value+".canary.steps") {
+			weightNode := util.MappingValue(util.MappingValue(s.node, "setWeight"), "weight")
+			weight, err := strconv.Atoi(weightNode.Value)
+			if err != nil {
+				continue
+			}
+			if weight <= previous {
+				l.report(s.path+".setWeight.weight", weightNode, "weight %d is not greater than the previous weight %d", weight, previous)
+			}
+			previous = weight
+		}
+	})
+}
+
+func checkStepsAfterFullWeight(l *linter, root *yaml.Node) {
+	forEachEntry(util.MappingValue(root, "strategies"), func(name, strategy *yaml.Node) {
+		steps := stepsOf(util.MappingValue(util.MappingValue(strategy, "canary"), "steps"), "strategies."+name.Value+".canary.steps")
+		_, full, found := lo.FindIndexOf(steps, func(s step) bool {
+			return util.MappingValue(util.MappingValue(s.node, "setWeight"), "weight").Value == "100"
+		})
+		if found && full+1 < len(steps) {
+			next := steps[full+1]
+			l.report(next.path, next.node, "step runs after setWeight 100, when all traffic already goes to the new version")
+		}
+	})
+}
+
+func checkWebhookRetries(l *linter, root *yaml.Node) {
+	for _, s := range stepsOf(util.MappingValue(root, "webhooks"), "webhooks") {
+		retryCount := util.MappingValue(s.node, "retryCount")
+		if retryCount.Kind == 0 {
+			name := util.MappingValue(s.node, "name")
+			l.report(s.path, s.node, "webhook %q is not retried when it fails, set retryCount", name.Value)
+		} else if retryCount.Value == "0" {
+			l.report(s.path+".retryCount", retryCount, "webhook %q is not retried when it fails", util.MappingValue(s.node, "name").Value)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package lint
+
+import (
+	"github.com/armory/armory-cli/pkg/findings"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+const lintYamlStr = `version: v1
+kind: kubernetes
+application: potato-facts
+targets:
+  staging:
+    account: staging
+    strategy: rolling
+  prod-west:
+    account: prod
+    strategy: rolling
+    constraints:
+      beforeDeployment:
+        - pause:
+            untilApproved: true
+            requiresRoles: [release]
+      afterDeployment:
+        - pause:
+            untilApproved: true
+manifests:
+  - path: manifests
+strategies:
+  rolling:
+    canary:
+      steps:
+        - setWeight:
+            weight: 50
+        - pause:
+            untilApproved: true
+        - setWeight:
+            weight: 25
+        - setWeight:
+            weight: 100
+        - pause:
+            duration: 1
+            unit: minutes
+webhooks:
+  - name: notify
+    method: POST
+    uriTemplate: https://example.com
+    retryCount: 0
+  - name: check
+    method: GET
+    uriTemplate: https://example.com
+`
+
+func TestLint(t *testing.T) {
+	found, err := Lint([]byte(lintYamlStr), Config{})
+	assert.NoError(t, err)
+	assert.Equal(t, []findings.Finding{
+		{Path: "strategies.rolling.canary", Line: 22, Column: 3, Message: `canary strategy "rolling" has no analysis step, its new version is only checked by hand`, Severity: findings.SeverityWarning, Rule: ruleCanaryWithoutAnalysis},
+		{Path: "targets.prod-west.constraints.afterDeployment[0].pause.untilApproved", Line: 18, Column: 28, Message: `approval of production-like target "prod-west" does not require roles, anyone can approve it`, Severity: findings.SeverityWarning, Rule: ruleApprovalWithoutRoles},
+		{Path: "strategies.rolling.canary.steps[1].pause.untilApproved", Line: 28, Column: 28, Message: `approval of production-like target "prod-west" does not require roles, anyone can approve it`, Severity: findings.SeverityWarning, Rule: ruleApprovalWithoutRoles},
+		{Path: "deploymentConfig.timeout", Line: 1, Column: 1, Message: "deploymentConfig.timeout is not set, deployments that never become ready are not rolled back until the default timeout", Severity: findings.SeverityWarning, Rule: ruleMissingTimeout},
+		{Path: "strategies.rolling.canary.steps[2].setWeight.weight", Line: 30, Column: 21, Message: "weight 25 is not greater than the previous weight 50", Severity: findings.SeverityWarning, Rule: ruleNonIncreasingWeights},
+		{Path: "strategies.rolling.canary.steps[4]", Line: 33, Column: 11, Message: "step runs after setWeight 100, when all traffic already goes to the new version", Severity: findings.SeverityWarning, Rule: ruleStepsAfterFullWeight},
+		{Path: "webhooks[0].retryCount", Line: 40, Column: 17, Message: `webhook "notify" is not retried when it fails`, Severity: findings.SeverityWarning, Rule: ruleWebhookWithoutRetries},
+		{Path: "webhooks[1]", Line: 41, Column: 5, Message: `webhook "check" is not retried when it fails, set retryCount`, Severity: findings.SeverityWarning, Rule: ruleWebhookWithoutRetries},
+	}, found)
+}
+
+func TestLintConfig(t *testing.T) {
+	config := Config{
+		Rules: map[string]string{
+			ruleCanaryWithoutAnalysis: "off",
+			ruleMissingTimeout:        "off",
+			ruleNonIncreasingWeights:  "off",
+			ruleStepsAfterFullWeight:  "off",
+			ruleWebhookWithoutRetries: "note",
+			ruleApprovalWithoutRoles:  "error",
+		},
+		ProductionTargets: []string{"staging"},
+	}
+	assert.NoError(t, config.validate())
+
+	found, err := Lint([]byte(lintYamlStr), config)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{ruleApprovalWithoutRoles, ruleWebhookWithoutRetries, ruleWebhookWithoutRetries}, rulesOf(found))
+	assert.Equal(t, "strategies.rolling.canary.steps[1].pause.untilApproved", found[0].Path)
+	assert.Equal(t, findings.SeverityError, found[0].Severity)
+	assert.Equal(t, findings.SeverityNote, found[1].Severity)
+
+	assert.ErrorIs(t, Config{Rules: map[string]string{"no-such-rule": "error"}}.validate(), ErrUnknownLintRule)
+	assert.ErrorIs(t, Config{Rules: map[string]string{ruleMissingTimeout: "fatal"}}.validate(), ErrInvalidLintSeverity)
+}
+
+func rulesOf(found []findings.Finding) []string {
+	var names []string
+	for _, f := range found {
+		names = append(names, f.Rule)
+	}
+	return names
+}
@@ -0,0 +1,10 @@
+package policy
+
+import (
+	"errors"
+)
+
+var (
+	ErrYAMLFileRead            = errors.New("error trying to read the YAML file")
+	ErrInvalidDeploymentObject = errors.New("error invalid deployment object")
+)
@@ -0,0 +1,31 @@
+package policy
+
+import (
+	"github.com/armory/armory-cli/pkg/cmdUtils"
+	"github.com/armory/armory-cli/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+const (
+	policyShort   = "Check deployment files against organization policies"
+	policyLong    = "Check deployment files against organization policies written in CUE"
+	policyExample = "armory policy test --policy policies -f deploy.yml"
+)
+
+func NewPolicyCmd(configuration *config.Configuration) *cobra.Command {
+	command := &cobra.Command{
+		Use:     "policy",
+		Aliases: []string{"policy"},
+		Short:   policyShort,
+		Long:    policyLong,
+		Example: policyExample,
+		GroupID: "deployment",
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			cmdUtils.ExecuteParentHooks(cmd, args)
+		},
+	}
+	// create subcommands
+	command.AddCommand(NewPolicyTestCmd(configuration))
+	cmdUtils.SetPersistentFlagsFromEnvVariables(command.Commands())
+	return command
+}
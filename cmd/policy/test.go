@@ -0,0 +1,136 @@
+package policy
+
+import (
+	"fmt"
+	"github.com/armory/armory-cli/cmd/utils"
+	"github.com/armory/armory-cli/cmd/validate"
+	"github.com/armory/armory-cli/cmd/version"
+	"github.com/armory/armory-cli/pkg/cmdUtils"
+	"github.com/armory/armory-cli/pkg/config"
+	deployment "github.com/armory/armory-cli/pkg/deploy"
+	errorUtils "github.com/armory/armory-cli/pkg/errors"
+	"github.com/armory/armory-cli/pkg/findings"
+	policies "github.com/armory/armory-cli/pkg/policy"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+	"net/http"
+	"os"
+	"strings"
+)
+
+const (
+	policyTestShort = "Check deployment files against policies without deploying"
+	policyTestLong  = "Check deployment files against the CUE policies of a directory, as armory deploy start --policy does before starting a deployment\n\n" +
+		"The constraints of each policy are unified with the body of the request that would start the deployment, which is " +
+		"the deployment file with the contents of its manifests in files. A policy can also list custom messages in a " +
+		"violations field, computed from the request, e.g.\n\n" +
+		"  targets: _\n" +
+		"  violations: [for name, t in targets if name =~ \"^prod\" && t.constraints == _|_ {\"target \\(name) must depend on staging\"}]\n\n" +
+		"A violation exits with code 4. Rego policies are not supported"
+	policyTestExample = "armory policy test --policy policies -f deploy.yml"
+)
+
+type policyTestOptions struct {
+	policyDir       string
+	deploymentFiles []string
+}
+
+func NewPolicyTestCmd(configuration *config.Configuration) *cobra.Command {
+	options := &policyTestOptions{}
+	cmd := &cobra.Command{
+		Use:     "test --policy <dir> --file [<path to file>]",
+		Aliases: []string{"test"},
+		Short:   policyTestShort,
+		Long:    policyTestLong,
+		Example: policyTestExample,
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			cmdUtils.ExecuteParentHooks(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return policyTest(cmd, configuration, options)
+		},
+	}
+	cmd.Flags().StringVarP(&options.policyDir, "policy", "", "", "directory of CUE policies")
+	cmd.Flags().StringArrayVarP(&options.deploymentFiles, "file", "f", []string{}, "path to a deployment file. Can be repeated")
+	cmd.MarkFlagRequired("policy")
+	cmd.MarkFlagRequired("file")
+	return cmd
+}
+
+func policyTest(cmd *cobra.Command, configuration *config.Configuration, options *policyTestOptions) error {
+	if *configuration.GetIsTest() {
+		utils.ConfigureLoggingForTesting(cmd)
+	}
+	loaded, err := policies.Load(options.policyDir)
+	if err != nil {
+		return err
+	}
+
+	// if we've made it this far, the command is valid. if an error occurs it isn't a usage error
+	cmd.SilenceUsage = true
+	violations := []findings.Finding{}
+	for _, deploymentFile := range options.deploymentFiles {
+		file, err := os.ReadFile(deploymentFile)
+		if err != nil {
+			return errorUtils.NewWrappedError(ErrYAMLFileRead, err)
+		}
+		var payload map[string]any
+		if err := yaml.Unmarshal(file, &payload); err != nil {
+			return errorUtils.NewWrappedError(ErrInvalidDeploymentObject, err)
+		}
+		// policies are evaluated against the body that deploy start would send, with its manifests read
+		request, err := deployment.NewPipelineRequest(deployment.StartPipelineOptions{UnstructuredDeployment: payload})
+		if err != nil {
+			return err
+		}
+		found, err := policies.Evaluate(loaded, request.Body)
+		if err != nil {
+			return err
+		}
+		for i := range found {
+			found[i].File = deploymentFile
+		}
+		violations = append(violations, found...)
+	}
+
+	if err := validate.LogFindings(configuration, FormattablePolicyResult{Findings: violations}, violations); err != nil {
+		return err
+	}
+	if len(violations) > 0 {
+		return policies.NewViolationError(violations)
+	}
+	return nil
+}
+
+type FormattablePolicyResult struct {
+	Findings     []findings.Finding `json:"findings" yaml:"findings"`
+	httpResponse *http.Response
+	err          error
+}
+
+func (r FormattablePolicyResult) Get() interface{} {
+	return r.Findings
+}
+
+func (r FormattablePolicyResult) GetHttpResponse() *http.Response {
+	return r.httpResponse
+}
+
+func (r FormattablePolicyResult) GetFetchError() error {
+	return r.err
+}
+
+func (r FormattablePolicyResult) SarifLog() any {
+	return findings.NewSarifLog("armory-policy", version.Version, r.Findings)
+}
+
+func (r FormattablePolicyResult) String() string {
+	if len(r.Findings) == 0 {
+		return "No policy violations."
+	}
+	descriptions := lo.Map(r.Findings, func(f findings.Finding, _ int) string {
+		return fmt.Sprintf("%s: %s [%s]", f.File, f.String(), f.Rule)
+	})
+	return "Policy violations:\n\n" + strings.Join(descriptions, "\n")
+}
@@ -0,0 +1,85 @@
+package policy
+
+import (
+	"bytes"
+	"github.com/armory/armory-cli/pkg/config"
+	policies "github.com/armory/armory-cli/pkg/policy"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func getTestConfig(output string) *config.Configuration {
+	token := "some-token"
+	addr := "https://localhost"
+	clientId := ""
+	clientSecret := ""
+	isTest := true
+	return config.New(&config.Input{
+		AccessToken:  &token,
+		ApiAddr:      &addr,
+		ClientId:     &clientId,
+		ClientSecret: &clientSecret,
+		OutFormat:    &output,
+		IsTest:       &isTest,
+	})
+}
+
+const deploymentYamlStr = `version: v1
+kind: kubernetes
+application: potato-facts
+targets:
+  staging:
+    account: staging
+  prod:
+    account: prod-east
+`
+
+func TestPolicyTestCmd(t *testing.T) {
+	t.Setenv("GITHUB_ACTIONS", "")
+	dir := t.TempDir()
+	policyDir := filepath.Join(dir, "policies")
+	assert.NoError(t, os.Mkdir(policyDir, 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(policyDir, "constraints.cue"), []byte(`targets: _
+violations: [for name, t in targets if name =~ "^prod" && t.constraints == _|_ {"target \(name) must depend on staging"}]
+`), 0o644))
+	deploymentFile := filepath.Join(dir, "deploy.yml")
+	assert.NoError(t, os.WriteFile(deploymentFile, []byte(deploymentYamlStr), 0o644))
+
+	outWriter := bytes.NewBufferString("")
+	cmd := NewPolicyTestCmd(getTestConfig("text"))
+	cmd.SetOut(outWriter)
+	cmd.SetArgs([]string{"--policy", policyDir, "-f", deploymentFile})
+	assert.ErrorIs(t, cmd.Execute(), policies.ErrPolicyViolation)
+	assert.Equal(t, "Policy violations:\n\n"+deploymentFile+": target prod must depend on staging [constraints.cue]\n", outWriter.String())
+
+	assert.NoError(t, os.WriteFile(filepath.Join(policyDir, "constraints.cue"), []byte(`targets: [string]: account: =~"^(staging|prod-)"`), 0o644))
+	outWriter = bytes.NewBufferString("")
+	cmd = NewPolicyTestCmd(getTestConfig("text"))
+	cmd.SetOut(outWriter)
+	cmd.SetArgs([]string{"--policy", policyDir, "-f", deploymentFile})
+	assert.NoError(t, cmd.Execute())
+	assert.Equal(t, "No policy violations.\n", outWriter.String())
+}
+
+func TestPolicyTestCmdChecksManifests(t *testing.T) {
+	t.Setenv("GITHUB_ACTIONS", "")
+	dir := t.TempDir()
+	policyDir := filepath.Join(dir, "policies")
+	assert.NoError(t, os.Mkdir(policyDir, 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(policyDir, "images.cue"), []byte(`files: _
+violations: [for path, contents in files for c in contents if c =~ ":latest" {"\(path) uses a latest image"}]
+`), 0o644))
+	manifestFile := filepath.Join(dir, "deployment.yaml")
+	assert.NoError(t, os.WriteFile(manifestFile, []byte("kind: Deployment\nimage: potato-facts:latest\n"), 0o644))
+	deploymentFile := filepath.Join(dir, "deploy.yml")
+	assert.NoError(t, os.WriteFile(deploymentFile, []byte(deploymentYamlStr+"manifests:\n  - path: "+manifestFile+"\n"), 0o644))
+
+	outWriter := bytes.NewBufferString("")
+	cmd := NewPolicyTestCmd(getTestConfig("text"))
+	cmd.SetOut(outWriter)
+	cmd.SetArgs([]string{"--policy", policyDir, "-f", deploymentFile})
+	assert.ErrorIs(t, cmd.Execute(), policies.ErrPolicyViolation)
+	assert.Equal(t, "Policy violations:\n\n"+deploymentFile+": "+manifestFile+" uses a latest image [images.cue]\n", outWriter.String())
+}
@@ -8,11 +8,17 @@ import (
 	configCmd "github.com/armory/armory-cli/cmd/config"
 	"github.com/armory/armory-cli/cmd/config/aws"
 	"github.com/armory/armory-cli/cmd/deploy"
+	"github.com/armory/armory-cli/cmd/format"
+	"github.com/armory/armory-cli/cmd/graph"
+	"github.com/armory/armory-cli/cmd/lint"
 	"github.com/armory/armory-cli/cmd/login"
 	"github.com/armory/armory-cli/cmd/logout"
+	policyCmd "github.com/armory/armory-cli/cmd/policy"
 	"github.com/armory/armory-cli/cmd/preview"
 	"github.com/armory/armory-cli/cmd/quickStart"
+	schemaCmd "github.com/armory/armory-cli/cmd/schema"
 	"github.com/armory/armory-cli/cmd/template"
+	"github.com/armory/armory-cli/cmd/tenant"
 	"github.com/armory/armory-cli/cmd/validate"
 	"github.com/armory/armory-cli/cmd/version"
 	"github.com/armory/armory-cli/pkg/cmdUtils"
@@ -52,7 +58,7 @@ func NewCmdRoot(outWriter, errWriter io.Writer) (*cobra.Command, error) {
 	clientId := rootCmd.PersistentFlags().StringP("clientId", "c", "", "Authenticate using an Armory CD-as-a-Service client ID")
 	clientSecret := rootCmd.PersistentFlags().StringP("clientSecret", "s", "", "Authenticate using an Armory CD-as-a-Service client secret")
 	verbose := rootCmd.PersistentFlags().BoolP("verbose", "v", false, "Enable verbose logging")
-	outFormat := rootCmd.PersistentFlags().StringP("output", "o", "text", "Set the output type. Available options: [json, yaml, text]")
+	outFormat := rootCmd.PersistentFlags().StringP("output", "o", "text", "Set the output type. Available options: [json, yaml, text, csv, sarif]")
 
 	// configure stdout and stderr and verbosity levels
 	console.Configure(&console.Options{
@@ -100,7 +106,13 @@ func NewCmdRoot(outWriter, errWriter io.Writer) (*cobra.Command, error) {
 		agent.NewCmdAgent(configuration),
 		cluster.NewClusterCmd(configuration, &cluster.SandboxClusterFileStore{}),
 		preview.NewCmdPreview(configuration),
+		schemaCmd.NewSchemaCmd(),
 		validate.NewValidateCmd(configuration),
+		lint.NewLintCmd(configuration),
+		format.NewFmtCmd(),
+		graph.NewGraphCmd(),
+		policyCmd.NewPolicyCmd(configuration),
+		tenant.NewTenantCmd(configuration),
 	)
 
 	cmdUtils.SetPersistentFlagsFromEnvVariables(rootCmd.Commands())
@@ -0,0 +1,12 @@
+package schema
+
+import (
+	"errors"
+)
+
+var (
+	ErrUnknownSchemaFormat = errors.New("unknown schema format, expected jsonschema or cue")
+	ErrUnknownSchemaKind   = errors.New("unknown deployment kind, expected kubernetes or lambda")
+	ErrSchemaConversion    = errors.New("error trying to convert the deployment schema")
+	ErrSchemaWrite         = errors.New("error trying to write the deployment schema")
+)
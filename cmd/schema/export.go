@@ -0,0 +1,87 @@
+package schema
+
+import (
+	"encoding/json"
+	"github.com/armory/armory-cli/cmd/template"
+	"github.com/armory/armory-cli/cmd/validate"
+	"github.com/armory/armory-cli/pkg/cmdUtils"
+	errorUtils "github.com/armory/armory-cli/pkg/errors"
+	"github.com/armory/armory-cli/pkg/schema"
+	"github.com/spf13/cobra"
+)
+
+const (
+	exportShort = "Export the schema of deployment files"
+	exportLong  = "Export the schema that deployment files are validated against\n\n" +
+		"The jsonschema format can be used by editors for completion and validation while writing a deployment file. " +
+		"For Visual Studio Code with the YAML extension, add the exported file to the yaml.schemas setting, or add " +
+		"`# yaml-language-server: $schema=<path to file>` at the top of the deployment file. Checks that depend on " +
+		"the rest of the file, such as strategy names used by targets, are only done by armory validate"
+	exportExample = "armory schema export --format jsonschema > deploy.schema.json"
+
+	formatJsonSchema = "jsonschema"
+	formatCue        = "cue"
+)
+
+type exportOptions struct {
+	format string
+	kind   string
+}
+
+func NewSchemaExportCmd() *cobra.Command {
+	options := &exportOptions{}
+	cmd := &cobra.Command{
+		Use:     "export --format [jsonschema|cue]",
+		Aliases: []string{"export"},
+		Short:   exportShort,
+		Long:    exportLong,
+		Example: exportExample,
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			cmdUtils.ExecuteParentHooks(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return export(cmd, options)
+		},
+	}
+	cmd.Flags().StringVarP(&options.format, "format", "", formatJsonSchema, "format of the schema, one of jsonschema or cue")
+	cmd.Flags().StringVarP(&options.kind, "kind", "", "kubernetes", "kind of deployment file, one of kubernetes or lambda")
+	return cmd
+}
+
+func export(cmd *cobra.Command, options *exportOptions) error {
+	file, definition, ok := validate.Schema(options.kind)
+	if !ok {
+		return errorUtils.NewErrorWithDynamicContext(ErrUnknownSchemaKind, ": "+options.kind)
+	}
+	if options.format != formatJsonSchema && options.format != formatCue {
+		return errorUtils.NewErrorWithDynamicContext(ErrUnknownSchemaFormat, ": "+options.format)
+	}
+
+	// if we've made it this far, the command is valid. if an error occurs it isn't a usage error
+	cmd.SilenceUsage = true
+
+	exported := file
+	if options.format == formatJsonSchema {
+		jsonSchema, err := schema.ToJSONSchema(file, definition)
+		if err != nil {
+			return errorUtils.NewWrappedError(ErrSchemaConversion, err)
+		}
+		examples, err := template.Examples()
+		if err != nil {
+			return errorUtils.NewWrappedError(ErrSchemaConversion, err)
+		}
+		for _, example := range examples {
+			schema.Describe(jsonSchema, example)
+		}
+		exported, err = json.MarshalIndent(jsonSchema, "", "  ")
+		if err != nil {
+			return errorUtils.NewWrappedError(ErrSchemaConversion, err)
+		}
+		exported = append(exported, '\n')
+	}
+
+	if _, err := cmd.OutOrStdout().Write(exported); err != nil {
+		return errorUtils.NewWrappedError(ErrSchemaWrite, err)
+	}
+	return nil
+}
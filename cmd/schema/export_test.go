@@ -0,0 +1,46 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/armory/armory-cli/cmd/validate"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSchemaExport(t *testing.T) {
+	outWriter := bytes.NewBufferString("")
+	cmd := NewSchemaExportCmd()
+	cmd.SetOut(outWriter)
+	cmd.SetArgs([]string{"--format", "jsonschema"})
+	assert.NoError(t, cmd.Execute())
+
+	var exported struct {
+		Title      string `json:"title"`
+		Properties map[string]struct {
+			Description string `json:"description"`
+		} `json:"properties"`
+		Required []string `json:"required"`
+	}
+	assert.NoError(t, json.Unmarshal(outWriter.Bytes(), &exported))
+	assert.Equal(t, "PipelineRequest", exported.Title)
+	assert.Equal(t, []string{"application", "targets", "manifests"}, exported.Required)
+	// descriptions are taken from the comments of armory template
+	assert.Equal(t, "The name of the application to deploy.", exported.Properties["application"].Description)
+
+	outWriter = bytes.NewBufferString("")
+	cmd = NewSchemaExportCmd()
+	cmd.SetOut(outWriter)
+	cmd.SetArgs([]string{"--format", "cue", "--kind", "lambda"})
+	assert.NoError(t, cmd.Execute())
+	file, _, _ := validate.Schema("lambda")
+	assert.Equal(t, string(file), outWriter.String())
+}
+
+func TestSchemaExportUnknownFormat(t *testing.T) {
+	cmd := NewSchemaExportCmd()
+	cmd.SetOut(bytes.NewBufferString(""))
+	cmd.SetErr(bytes.NewBufferString(""))
+	cmd.SetArgs([]string{"--format", "openapi"})
+	assert.ErrorIs(t, cmd.Execute(), ErrUnknownSchemaFormat)
+}
@@ -0,0 +1,30 @@
+package schema
+
+import (
+	"github.com/armory/armory-cli/pkg/cmdUtils"
+	"github.com/spf13/cobra"
+)
+
+const (
+	schemaShort   = "Work with the schema of deployment files"
+	schemaLong    = "Work with the schema of deployment files"
+	schemaExample = "armory schema export --format jsonschema > deploy.schema.json"
+)
+
+func NewSchemaCmd() *cobra.Command {
+	command := &cobra.Command{
+		Use:     "schema",
+		Aliases: []string{"schema"},
+		Short:   schemaShort,
+		Long:    schemaLong,
+		Example: schemaExample,
+		GroupID: "deployment",
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			cmdUtils.ExecuteParentHooks(cmd, args)
+		},
+	}
+	// create subcommands
+	command.AddCommand(NewSchemaExportCmd())
+	cmdUtils.SetPersistentFlagsFromEnvVariables(command.Commands())
+	return command
+}
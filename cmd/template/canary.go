@@ -38,9 +38,26 @@ func NewTemplateCanaryCmd() *cobra.Command {
 }
 
 func canary(cmd *cobra.Command, options *templateCanaryOptions, args []string) error {
+	root, err := buildCanaryTemplate(options)
+	if err != nil {
+		return err
+	}
+
+	bytes, err := yaml.Marshal(root)
+	if err != nil {
+		return errorUtils.NewWrappedError(ErrCanaryTemplateBuild, err)
+	}
+	_, err = cmd.OutOrStdout().Write(bytes)
+	if err != nil {
+		return errorUtils.NewWrappedError(ErrCanaryTemplateParse, err)
+	}
+	return nil
+}
+
+func buildCanaryTemplate(options *templateCanaryOptions) (*yaml.Node, error) {
 	root, error := buildTemplateKubernetesCore(options)
 	if error != nil {
-		return error
+		return nil, error
 	}
 
 	// Strategies root
@@ -117,7 +134,7 @@ func canary(cmd *cobra.Command, options *templateCanaryOptions, args []string) e
 			hook.Content = append(hook.Content, hookNode, hookValuesNode)
 			stepsValuesNode.Content = append(stepsValuesNode.Content, hook)
 		default:
-			return errorUtils.NewErrorWithDynamicContext(ErrUnknownFeature, ": "+feature)
+			return nil, errorUtils.NewErrorWithDynamicContext(ErrUnknownFeature, ": "+feature)
 		}
 	}
 
@@ -125,16 +142,7 @@ func canary(cmd *cobra.Command, options *templateCanaryOptions, args []string) e
 	strategy1ValuesNode.Content = append(strategy1ValuesNode.Content, canaryNode, canaryValuesNode)
 	strategyValuesNode.Content = append(strategyValuesNode.Content, strategy1Node, strategy1ValuesNode)
 	root.Content = append(root.Content, strategiesNode, strategyValuesNode)
-
-	bytes, err := yaml.Marshal(root)
-	if err != nil {
-		return errorUtils.NewWrappedError(ErrCanaryTemplateBuild, err)
-	}
-	_, err = cmd.OutOrStdout().Write(bytes)
-	if err != nil {
-		return errorUtils.NewWrappedError(ErrCanaryTemplateParse, err)
-	}
-	return nil
+	return root, nil
 }
 func buildAnalysisQueries() (*yaml.Node, *yaml.Node) {
 	queriesNode, queriesValuesNode := util.BuildSequenceNode("queries", "Note that the example queries require Prometheus to have \"kube-state-metrics.metricAnnotationsAllowList[0]=pods=[*]\"\n"+
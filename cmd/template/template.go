@@ -2,7 +2,10 @@ package template
 
 import (
 	"github.com/armory/armory-cli/pkg/cmdUtils"
+	errorUtils "github.com/armory/armory-cli/pkg/errors"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+	"strings"
 )
 
 const (
@@ -28,3 +31,17 @@ func NewTemplateCmd() *cobra.Command {
 	cmdUtils.SetPersistentFlagsFromEnvVariables(command.Commands())
 	return command
 }
+
+// Examples returns the kubernetes templates with every feature, whose comments document the fields of deployment
+// files.
+func Examples() ([]*yaml.Node, error) {
+	canary, err := buildCanaryTemplate(&templateCanaryOptions{features: []string{"automated", "webhook"}})
+	if err != nil {
+		return nil, err
+	}
+	var blueGreen yaml.Node
+	if err := yaml.Unmarshal([]byte(strings.Join([]string{KubernetesCoreTemplate, blueGreenTemplate}, "\n")), &blueGreen); err != nil {
+		return nil, errorUtils.NewWrappedError(ErrBlueGreenTemplateParse, err)
+	}
+	return []*yaml.Node{canary, &blueGreen}, nil
+}
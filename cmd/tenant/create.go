@@ -0,0 +1,66 @@
+package tenant
+
+import (
+	"context"
+	"time"
+
+	"github.com/armory/armory-cli/cmd/utils"
+	"github.com/armory/armory-cli/pkg/cmdUtils"
+	cliconfig "github.com/armory/armory-cli/pkg/config"
+	"github.com/armory/armory-cli/pkg/configuration"
+	errorUtils "github.com/armory/armory-cli/pkg/errors"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	log "go.uber.org/zap"
+)
+
+const (
+	tenantCreateShort   = "Create a tenant"
+	tenantCreateLong    = "Create a tenant"
+	tenantCreateExample = "armory tenant create --name staging"
+)
+
+type tenantOptions struct {
+	name string
+}
+
+func NewTenantCreateCmd(configuration *cliconfig.Configuration) *cobra.Command {
+	options := &tenantOptions{}
+	cmd := &cobra.Command{
+		Use:     "create --name [<tenant name>]",
+		Short:   tenantCreateShort,
+		Long:    tenantCreateLong,
+		Example: tenantCreateExample,
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			cmdUtils.ExecuteParentHooks(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return create(cmd, options, configuration)
+		},
+	}
+	cmd.Flags().StringVarP(&options.name, "name", "", "", "name of the tenant")
+	cmd.MarkFlagRequired("name")
+	return cmd
+}
+
+func create(cmd *cobra.Command, options *tenantOptions, cli *cliconfig.Configuration) error {
+	if lo.FromPtr(cli.GetIsTest()) {
+		utils.ConfigureLoggingForTesting(cmd)
+	}
+	cmd.SilenceUsage = true
+	client := configuration.NewClient(cli)
+	_, exists, err := findTenant(client, options.name)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return errorUtils.NewErrorWithDynamicContext(ErrTenantExists, ": "+options.name)
+	}
+	ctx, cancel := context.WithTimeout(client.ArmoryCloudClient.Context, time.Minute)
+	defer cancel()
+	if _, _, err := client.CreateEnvironment(ctx, configuration.CreateEnvironmentRequest(options.name)); err != nil {
+		return errorUtils.NewWrappedError(ErrCreatingTenant, err)
+	}
+	log.S().Infof("Created tenant: %s", options.name)
+	return nil
+}
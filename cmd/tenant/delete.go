@@ -0,0 +1,65 @@
+package tenant
+
+import (
+	"context"
+	"time"
+
+	"github.com/armory/armory-cli/cmd/utils"
+	"github.com/armory/armory-cli/pkg/cmdUtils"
+	cliconfig "github.com/armory/armory-cli/pkg/config"
+	"github.com/armory/armory-cli/pkg/configuration"
+	errorUtils "github.com/armory/armory-cli/pkg/errors"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	log "go.uber.org/zap"
+)
+
+const (
+	tenantDeleteShort = "Delete a tenant"
+	tenantDeleteLong  = "Delete a tenant\n\n" +
+		"If the tenant is listed in your RBAC configuration file, remove it from the file as well, otherwise the next " +
+		"armory config apply creates it again"
+	tenantDeleteExample = "armory tenant delete --name staging"
+)
+
+func NewTenantDeleteCmd(configuration *cliconfig.Configuration) *cobra.Command {
+	options := &tenantOptions{}
+	cmd := &cobra.Command{
+		Use:     "delete --name [<tenant name>]",
+		Aliases: []string{"rm"},
+		Short:   tenantDeleteShort,
+		Long:    tenantDeleteLong,
+		Example: tenantDeleteExample,
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			cmdUtils.ExecuteParentHooks(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return deleteTenant(cmd, options, configuration)
+		},
+	}
+	cmd.Flags().StringVarP(&options.name, "name", "", "", "name of the tenant")
+	cmd.MarkFlagRequired("name")
+	return cmd
+}
+
+func deleteTenant(cmd *cobra.Command, options *tenantOptions, cli *cliconfig.Configuration) error {
+	if lo.FromPtr(cli.GetIsTest()) {
+		utils.ConfigureLoggingForTesting(cmd)
+	}
+	cmd.SilenceUsage = true
+	client := configuration.NewClient(cli)
+	environment, exists, err := findTenant(client, options.name)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errorUtils.NewErrorWithDynamicContext(ErrTenantNotFound, ": "+options.name)
+	}
+	ctx, cancel := context.WithTimeout(client.ArmoryCloudClient.Context, time.Minute)
+	defer cancel()
+	if _, err := client.DeleteEnvironment(ctx, configuration.DeleteEnvironmentRequest(environment.ID)); err != nil {
+		return errorUtils.NewWrappedError(ErrDeletingTenant, err)
+	}
+	log.S().Infof("Deleted tenant: %s", options.name)
+	return nil
+}
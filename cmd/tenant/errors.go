@@ -0,0 +1,11 @@
+package tenant
+
+import "errors"
+
+var (
+	ErrGettingTenants = errors.New("error getting tenants")
+	ErrCreatingTenant = errors.New("error trying to create tenant")
+	ErrDeletingTenant = errors.New("error trying to delete tenant")
+	ErrTenantExists   = errors.New("there's already a tenant with that name")
+	ErrTenantNotFound = errors.New("tenant not found")
+)
@@ -0,0 +1,96 @@
+package tenant
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/armory/armory-cli/cmd/utils"
+	"github.com/armory/armory-cli/pkg/cmdUtils"
+	cliconfig "github.com/armory/armory-cli/pkg/config"
+	"github.com/armory/armory-cli/pkg/configuration"
+	errorUtils "github.com/armory/armory-cli/pkg/errors"
+	"github.com/armory/armory-cli/pkg/model/configClient"
+	"github.com/armory/armory-cli/pkg/output"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	log "go.uber.org/zap"
+)
+
+const (
+	tenantListShort   = "List your tenants"
+	tenantListLong    = "List your tenants"
+	tenantListExample = "armory tenant list -o json"
+)
+
+type FormattableTenants struct {
+	Tenants      []configClient.Environment
+	httpResponse *http.Response
+	err          error
+}
+
+func NewTenantListCmd(configuration *cliconfig.Configuration) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   tenantListShort,
+		Long:    tenantListLong,
+		Example: tenantListExample,
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			cmdUtils.ExecuteParentHooks(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return list(cmd, configuration)
+		},
+	}
+	return cmd
+}
+
+func list(cmd *cobra.Command, cli *cliconfig.Configuration) error {
+	if lo.FromPtr(cli.GetIsTest()) {
+		utils.ConfigureLoggingForTesting(cmd)
+	}
+	cmd.SilenceUsage = true
+	client := configuration.NewClient(cli)
+	ctx, cancel := context.WithTimeout(client.ArmoryCloudClient.Context, time.Minute)
+	defer cancel()
+	environments, err := client.GetEnvironments(ctx)
+	if err != nil {
+		return errorUtils.NewWrappedError(ErrGettingTenants, err)
+	}
+	dataFormat, err := cli.GetOutputFormatter()(FormattableTenants{Tenants: lo.Ternary(environments == nil, []configClient.Environment{}, environments)})
+	if err != nil {
+		return err
+	}
+	log.S().Info(dataFormat)
+	return nil
+}
+
+func (t FormattableTenants) Get() interface{} {
+	return t.Tenants
+}
+
+func (t FormattableTenants) GetHttpResponse() *http.Response {
+	return t.httpResponse
+}
+
+func (t FormattableTenants) GetFetchError() error {
+	return t.err
+}
+
+func (t FormattableTenants) Header() []string {
+	return []string{"NAME", "ID"}
+}
+
+func (t FormattableTenants) Rows() [][]string {
+	return lo.Map(t.Tenants, func(environment configClient.Environment, _ int) []string {
+		return []string{environment.Name, environment.ID}
+	})
+}
+
+func (t FormattableTenants) String() string {
+	if len(t.Tenants) == 0 {
+		return "No tenants"
+	}
+	return output.FormatTable(t)
+}
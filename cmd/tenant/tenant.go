@@ -0,0 +1,56 @@
+package tenant
+
+import (
+	"context"
+	"time"
+
+	"github.com/armory/armory-cli/pkg/cmdUtils"
+	cliconfig "github.com/armory/armory-cli/pkg/config"
+	"github.com/armory/armory-cli/pkg/configuration"
+	errorUtils "github.com/armory/armory-cli/pkg/errors"
+	"github.com/armory/armory-cli/pkg/model/configClient"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+)
+
+const (
+	tenantShort = "Manage your tenants"
+	tenantLong  = "Manage your tenants\n\n" +
+		"Tenants can also be managed with an RBAC configuration file and armory config apply. " +
+		"For usage documentation, visit https://docs.armory.io/cd-as-a-service/concepts/iam/rbac"
+	tenantExample = "armory tenant list"
+)
+
+func NewTenantCmd(configuration *cliconfig.Configuration) *cobra.Command {
+	command := &cobra.Command{
+		Use:     "tenant",
+		Aliases: []string{"tenants"},
+		Short:   tenantShort,
+		Long:    tenantLong,
+		Example: tenantExample,
+		GroupID: "admin",
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			cmdUtils.ExecuteParentHooks(cmd, args)
+		},
+	}
+	// create subcommands
+	command.AddCommand(NewTenantListCmd(configuration))
+	command.AddCommand(NewTenantCreateCmd(configuration))
+	command.AddCommand(NewTenantDeleteCmd(configuration))
+
+	cmdUtils.SetPersistentFlagsFromEnvVariables(command.Commands())
+
+	return command
+}
+
+// findTenant gets the tenant with the given name, the second value tells whether it exists.
+func findTenant(client *configuration.ConfigClient, name string) (configClient.Environment, bool, error) {
+	ctx, cancel := context.WithTimeout(client.ArmoryCloudClient.Context, time.Minute)
+	defer cancel()
+	environments, err := client.GetEnvironments(ctx)
+	if err != nil {
+		return configClient.Environment{}, false, errorUtils.NewWrappedError(ErrGettingTenants, err)
+	}
+	environment, ok := lo.Find(environments, func(e configClient.Environment) bool { return e.Name == name })
+	return environment, ok, nil
+}
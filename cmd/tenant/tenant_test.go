@@ -0,0 +1,109 @@
+package tenant
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"os"
+	"testing"
+
+	cliconfig "github.com/armory/armory-cli/pkg/config"
+	"github.com/armory/armory-cli/pkg/model/configClient"
+	"github.com/jarcoal/httpmock"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestTenantTestSuite(t *testing.T) {
+	suite.Run(t, new(TenantTestSuite))
+}
+
+type TenantTestSuite struct {
+	suite.Suite
+}
+
+func (suite *TenantTestSuite) SetupSuite() {
+	os.Setenv("ARMORY_CLI_TEST", "true")
+	httpmock.Activate()
+}
+
+func (suite *TenantTestSuite) SetupTest() {
+	httpmock.Reset()
+	environments := []configClient.Environment{{Name: "staging", ID: "env-id-1"}, {Name: "production", ID: "env-id-2"}}
+	assert.NoError(suite.T(), registerResponder(environments, http.StatusOK, "/environments", http.MethodGet))
+}
+
+func (suite *TenantTestSuite) TearDownSuite() {
+	os.Unsetenv("ARMORY_CLI_TEST")
+	httpmock.DeactivateAndReset()
+}
+
+func (suite *TenantTestSuite) TestTenantList() {
+	outWriter := bytes.NewBufferString("")
+	suite.NoError(getTenantCmd(outWriter, "text", "list").Execute())
+	suite.Equal("NAME         ID\nstaging      env-id-1\nproduction   env-id-2\n", outWriter.String())
+}
+
+func (suite *TenantTestSuite) TestTenantListCsv() {
+	outWriter := bytes.NewBufferString("")
+	suite.NoError(getTenantCmd(outWriter, "csv", "list").Execute())
+	suite.Equal("NAME,ID\nstaging,env-id-1\nproduction,env-id-2\n", outWriter.String())
+}
+
+func (suite *TenantTestSuite) TestTenantCreate() {
+	assert.NoError(suite.T(), registerResponder(configClient.CreateEnvironmentResponse{ID: "env-id-3", Name: "qa"}, http.StatusCreated, "/environments", http.MethodPost))
+	outWriter := bytes.NewBufferString("")
+	suite.NoError(getTenantCmd(outWriter, "text", "create", "--name", "qa").Execute())
+	suite.Equal("Created tenant: qa\n", outWriter.String())
+	suite.Equal(1, httpmock.GetCallCountInfo()["POST /environments"])
+}
+
+func (suite *TenantTestSuite) TestTenantCreateExisting() {
+	err := getTenantCmd(io.Discard, "text", "create", "--name", "staging").Execute()
+	suite.ErrorIs(err, ErrTenantExists)
+	suite.Equal(0, httpmock.GetCallCountInfo()["POST /environments"])
+}
+
+func (suite *TenantTestSuite) TestTenantDelete() {
+	assert.NoError(suite.T(), registerResponder(nil, http.StatusNoContent, "/environments/env-id-1", http.MethodDelete))
+	outWriter := bytes.NewBufferString("")
+	suite.NoError(getTenantCmd(outWriter, "text", "delete", "--name", "staging").Execute())
+	suite.Equal("Deleted tenant: staging\n", outWriter.String())
+	suite.Equal(1, httpmock.GetCallCountInfo()["DELETE /environments/env-id-1"])
+}
+
+func (suite *TenantTestSuite) TestTenantDeleteUnknown() {
+	err := getTenantCmd(io.Discard, "text", "delete", "--name", "qa").Execute()
+	suite.ErrorIs(err, ErrTenantNotFound)
+}
+
+func registerResponder(body any, status int, url, method string) error {
+	responder, err := httpmock.NewJsonResponder(status, body)
+	if err != nil {
+		return err
+	}
+	httpmock.RegisterResponder(method, url, responder)
+	return nil
+}
+
+func getTenantCmd(outWriter io.Writer, output string, args ...string) *cobra.Command {
+	token := "some-token"
+	addr := "https://localhost"
+	clientId := ""
+	clientSecret := ""
+	isTest := true
+	configuration := cliconfig.New(&cliconfig.Input{
+		AccessToken:  &token,
+		ApiAddr:      &addr,
+		ClientId:     &clientId,
+		ClientSecret: &clientSecret,
+		OutFormat:    &output,
+		IsTest:       &isTest,
+	})
+	cmd := NewTenantCmd(configuration)
+	cmd.SetOut(outWriter)
+	cmd.SetErr(io.Discard)
+	cmd.SetArgs(args)
+	return cmd
+}
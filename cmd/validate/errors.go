@@ -0,0 +1,11 @@
+package validate
+
+import (
+	"errors"
+)
+
+var (
+	ErrInvalidDeploymentFile = errors.New("the deployment file is not valid")
+	ErrInvalidRBACFile       = errors.New("the RBAC configuration file is not valid")
+	ErrUnknownKind           = errors.New("unknown kind of file, expected one of kubernetes, lambda or rbac")
+)
@@ -0,0 +1,210 @@
+package validate
+
+import (
+	"errors"
+	"fmt"
+	deployment "github.com/armory/armory-cli/pkg/deploy"
+	"github.com/armory/armory-cli/pkg/util"
+	"github.com/samber/lo"
+	"gopkg.in/yaml.v3"
+	"io"
+	"strings"
+)
+
+// workloadKinds are the kinds of Kubernetes objects that run containers.
+var workloadKinds = []string{"Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "Job", "CronJob", "Pod"}
+
+// targetObjects is the set of Kubernetes objects deployed to a target, by kind and then name.
+type targetObjects map[string][]string
+
+func (o targetObjects) has(kind, name string) bool {
+	return lo.Contains(o[kind], name)
+}
+
+func (o targetObjects) hasWorkload() bool {
+	return lo.SomeBy(workloadKinds, func(kind string) bool { return len(o[kind]) > 0 })
+}
+
+// checkManifestContents checks the services and traffic management resources referenced by the deployment file
+// against the objects in its manifests, and that every target deploys at least one workload. Targets deploying a
+// manifest that cannot be read offline, such as a URL, a helm chart or an empty placeholder, are skipped.
+func (c *semanticChecker) checkManifestContents(root *yaml.Node) {
+	targets := util.MappingValue(root, "targets")
+	objects := c.readTargetObjects(targets, util.MappingValue(root, "manifests"))
+
+	for i := 0; i+1 < len(targets.Content); i += 2 {
+		name := targets.Content[i]
+		if deployed, known := objects[name.Value]; known && !deployed.hasWorkload() {
+			c.report(ruleWorkload, joinPath("targets", name.Value), name, "no workload is deployed to target %q, expected one of %s", name.Value, strings.Join(workloadKinds, ", "))
+		}
+	}
+
+	strategies := util.MappingValue(root, "strategies")
+	for i := 0; i+1 < len(strategies.Content); i += 2 {
+		strategy := strategies.Content[i].Value
+		users := targetsUsingStrategy(targets, strategy)
+		c.findExposedServices(strategies.Content[i+1], joinPath("strategies", strategy), func(path string, service *yaml.Node) {
+			c.requireObject(objects, users, path, service, "Service")
+		})
+	}
+
+	for i, trafficManagement := range util.SequenceItems(util.MappingValue(root, "trafficManagement")) {
+		path := fmt.Sprintf("trafficManagement[%d]", i)
+		users := targetNamesOrAll(targets, util.MappingValue(trafficManagement, "targets"))
+		references := []struct{ provider, field, kind string }{
+			{"kubernetes", "activeService", "Service"},
+			{"kubernetes", "previewService", "Service"},
+			{"smi", "rootServiceName", "Service"},
+			{"istio", "virtualService.name", "VirtualService"},
+			{"istio", "destinationRule.name", "DestinationRule"},
+		}
+		for _, reference := range references {
+			for j, config := range util.SequenceItems(util.MappingValue(trafficManagement, reference.provider)) {
+				value := config
+				for _, key := range strings.Split(reference.field, ".") {
+					value = util.MappingValue(value, key)
+				}
+				if value.Kind == yaml.ScalarNode && value.Value != "" {
+					c.requireObject(objects, users, fmt.Sprintf("%s.%s[%d].%s", path, reference.provider, j, reference.field), value, reference.kind)
+				}
+			}
+		}
+	}
+}
+
+// requireObject reports the first target that uses a reference but does not deploy the referenced object.
+func (c *semanticChecker) requireObject(objects map[string]targetObjects, targets []string, path string, node *yaml.Node, kind string) {
+	for _, target := range targets {
+		if deployed, known := objects[target]; known && !deployed.has(kind, node.Value) {
+			c.report(ruleManifestReference, path, node, "%s %q is not in the manifests deployed to target %q", kind, node.Value, target)
+			return
+		}
+	}
+}
+
+// readTargetObjects parses the manifests deployed to each target. Targets are missing from the result when any of
+// their manifests cannot be read.
+func (c *semanticChecker) readTargetObjects(targets, manifests *yaml.Node) map[string]targetObjects {
+	objects := map[string]targetObjects{}
+	for i := 0; i+1 < len(targets.Content); i += 2 {
+		objects[targets.Content[i].Value] = targetObjects{}
+	}
+
+	for _, manifest := range util.SequenceItems(manifests) {
+		found, readable := c.readManifestObjects(manifest)
+		for _, target := range targetNamesOrAll(targets, util.MappingValue(manifest, "targets")) {
+			deployed, known := objects[target]
+			if !known {
+				continue
+			}
+			if !readable {
+				delete(objects, target)
+				continue
+			}
+			for kind, names := range found {
+				deployed[kind] = append(deployed[kind], names...)
+			}
+		}
+	}
+	return objects
+}
+
+// readManifestObjects parses the objects of a manifest entry. A manifest without any object, such as an empty
+// placeholder, is not considered readable since its contents are only known when deploying.
+func (c *semanticChecker) readManifestObjects(manifest *yaml.Node) (targetObjects, bool) {
+	contents, readable := c.readManifest(manifest)
+	if !readable {
+		return nil, false
+	}
+	found := targetObjects{}
+	for _, content := range contents {
+		if err := collectObjects(content, found); err != nil {
+			return nil, false
+		}
+	}
+	return found, len(found) > 0
+}
+
+// readManifest returns the contents of a manifest entry, and whether they could be read without deploying. Contents
+// that still have placeholders once the values are substituted are only known when deploying too.
+func (c *semanticChecker) readManifest(manifest *yaml.Node) ([]string, bool) {
+	if inline := util.MappingValue(manifest, "inline"); inline.Kind == yaml.ScalarNode {
+		return []string{inline.Value}, !deployment.HasPlaceholders(inline.Value)
+	}
+	path := util.MappingValue(manifest, "path")
+	if path.Value == "" {
+		path = util.MappingValue(manifest, "kustomize")
+	}
+	if path.Value == "" || deployment.IsURL(path.Value) {
+		return nil, false
+	}
+	contents, err := deployment.ReadManifests(path.Value, c.values)
+	if err != nil || lo.SomeBy(contents, deployment.HasPlaceholders) {
+		return nil, false
+	}
+	return contents, true
+}
+
+func collectObjects(content string, objects targetObjects) error {
+	decoder := yaml.NewDecoder(strings.NewReader(content))
+	for {
+		var object struct {
+			Kind     string `yaml:"kind"`
+			Metadata struct {
+				Name string `yaml:"name"`
+			} `yaml:"metadata"`
+		}
+		if err := decoder.Decode(&object); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		if object.Kind != "" {
+			objects[object.Kind] = append(objects[object.Kind], object.Metadata.Name)
+		}
+	}
+}
+
+// findExposedServices calls found for every service exposed by a step of a strategy.
+func (c *semanticChecker) findExposedServices(node *yaml.Node, path string, found func(path string, service *yaml.Node)) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i].Value, node.Content[i+1]
+			if key == "exposeServices" {
+				for j, service := range util.SequenceItems(util.MappingValue(value, "services")) {
+					found(fmt.Sprintf("%s.exposeServices.services[%d]", path, j), service)
+				}
+				continue
+			}
+			c.findExposedServices(value, joinPath(path, key), found)
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			c.findExposedServices(item, fmt.Sprintf("%s[%d]", path, i), found)
+		}
+	}
+}
+
+func targetsUsingStrategy(targets *yaml.Node, strategy string) []string {
+	var names []string
+	for i := 0; i+1 < len(targets.Content); i += 2 {
+		if util.MappingValue(targets.Content[i+1], "strategy").Value == strategy {
+			names = append(names, targets.Content[i].Value)
+		}
+	}
+	return names
+}
+
+// targetNamesOrAll returns the target names listed in a targets field, or every target when it is not set.
+func targetNamesOrAll(targets, selected *yaml.Node) []string {
+	if selected.Kind == yaml.SequenceNode {
+		return lo.Map(selected.Content, func(target *yaml.Node, _ int) string { return target.Value })
+	}
+	var names []string
+	for i := 0; i+1 < len(targets.Content); i += 2 {
+		names = append(names, targets.Content[i].Value)
+	}
+	return names
+}
@@ -0,0 +1,147 @@
+package validate
+
+import (
+	deployment "github.com/armory/armory-cli/pkg/deploy"
+	"github.com/armory/armory-cli/pkg/findings"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const manifestContentsYamlStr = `version: v1
+kind: kubernetes
+application: potato-facts
+targets:
+  staging:
+    account: staging
+    namespace: staging
+    strategy: blue-green
+  prod:
+    account: prod
+    namespace: prod
+    strategy: blue-green
+  jobs:
+    account: prod
+    namespace: jobs
+    strategy: blue-green
+manifests:
+  - path: manifests
+    targets: [staging, prod]
+  - inline: |
+      kind: Service
+      metadata:
+        name: potato-facts-preview
+    targets: [staging]
+  - path: https://example.com/jobs.yaml
+    targets: [jobs]
+strategies:
+  blue-green:
+    blueGreen:
+      redirectTrafficAfter:
+        - exposeServices:
+            services: [potato-facts-preview]
+trafficManagement:
+  - targets: [staging, prod]
+    kubernetes:
+      - activeService: potato-facts
+        previewService: potato-fact-preview
+    istio:
+      - virtualService:
+          name: potato-facts
+        destinationRule:
+          name: potato-facts
+`
+
+func TestValidateManifestContents(t *testing.T) {
+	workspace := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(workspace, "manifests"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(workspace, "manifests", "app.yaml"), []byte(`kind: Deployment
+metadata:
+  name: potato-facts
+---
+kind: Service
+metadata:
+  name: potato-facts
+---
+kind: VirtualService
+metadata:
+  name: potato-facts
+`), 0o644))
+	t.Setenv("GITHUB_WORKSPACE", workspace)
+
+	errs, err := ValidateSemantics([]byte(manifestContentsYamlStr), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []findings.Finding{
+		{Path: "strategies.blue-green.blueGreen.redirectTrafficAfter[0].exposeServices.services[0]", Line: 32, Column: 24, Message: `Service "potato-facts-preview" is not in the manifests deployed to target "prod"`, Severity: findings.SeverityError, Rule: ruleManifestReference},
+		{Path: "trafficManagement[0].kubernetes[0].previewService", Line: 37, Column: 25, Message: `Service "potato-fact-preview" is not in the manifests deployed to target "staging"`, Severity: findings.SeverityError, Rule: ruleManifestReference},
+		{Path: "trafficManagement[0].istio[0].destinationRule.name", Line: 42, Column: 17, Message: `DestinationRule "potato-facts" is not in the manifests deployed to target "staging"`, Severity: findings.SeverityError, Rule: ruleManifestReference},
+	}, errs)
+
+	// the workload check only applies to targets whose manifests could all be read
+	errs, err = ValidateSemantics([]byte(`kind: kubernetes
+targets:
+  staging:
+    strategy: rolling
+manifests:
+  - inline: |
+      kind: Service
+      metadata:
+        name: potato-facts
+`), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []findings.Finding{
+		{Path: "targets.staging", Line: 3, Column: 3, Message: `no workload is deployed to target "staging", expected one of Deployment, StatefulSet, DaemonSet, ReplicaSet, Job, CronJob, Pod`, Severity: findings.SeverityError, Rule: ruleWorkload},
+	}, errs)
+}
+
+const templatedManifestYamlStr = `version: v1
+kind: kubernetes
+application: potato-facts
+targets:
+  staging:
+    account: staging
+    namespace: staging
+    strategy: blue-green
+manifests:
+  - path: manifests
+strategies:
+  blue-green:
+    blueGreen:
+      redirectTrafficAfter:
+        - exposeServices:
+            services: [potato-facts-preview]
+`
+
+func TestValidateManifestContentsWithPlaceholders(t *testing.T) {
+	workspace := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(workspace, "manifests"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(workspace, "manifests", "app.yaml"), []byte(`kind: Deployment
+metadata:
+  name: potato-facts
+---
+kind: Service
+metadata:
+  name: ${service}
+`), 0o644))
+	t.Setenv("GITHUB_WORKSPACE", workspace)
+
+	// manifests with placeholders left are only known when deploying
+	errs, err := ValidateSemantics([]byte(templatedManifestYamlStr), nil)
+	assert.NoError(t, err)
+	assert.Empty(t, errs)
+
+	values, err := deployment.NewValues(nil, []string{"service=potato-facts-preview"}, false)
+	assert.NoError(t, err)
+	errs, err = ValidateSemantics([]byte(templatedManifestYamlStr), values)
+	assert.NoError(t, err)
+	assert.Empty(t, errs)
+
+	values, err = deployment.NewValues(nil, []string{"service=potato-facts"}, false)
+	assert.NoError(t, err)
+	errs, err = ValidateSemantics([]byte(templatedManifestYamlStr), values)
+	assert.NoError(t, err)
+	assert.Equal(t, []findings.Finding{
+		{Path: "strategies.blue-green.blueGreen.redirectTrafficAfter[0].exposeServices.services[0]", Line: 16, Column: 24, Message: `Service "potato-facts-preview" is not in the manifests deployed to target "staging"`, Severity: findings.SeverityError, Rule: ruleManifestReference},
+	}, errs)
+}
@@ -0,0 +1,168 @@
+package validate
+
+import (
+	_ "embed"
+	"fmt"
+	"sort"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	cueyaml "cuelang.org/go/encoding/yaml"
+	"github.com/armory/armory-cli/pkg/findings"
+	"github.com/armory/armory-cli/pkg/util"
+	"github.com/samber/lo"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed resources/rbacConfiguration.cue
+var rbacSchemaFile []byte
+
+const (
+	// rbacKind is the kind of RBAC configuration files, which do not declare a kind like deployment files do.
+	rbacKind = "rbac"
+
+	rbacDefinition = "#RBACConfiguration"
+)
+
+// Rules of the checks of RBAC configuration files, reported with their findings.
+const (
+	ruleGrant         = "grant"
+	ruleDuplicateRole = "duplicate-role"
+	ruleTenantName    = "tenant-name"
+	ruleTenantDeleted = "tenant-deleted"
+)
+
+// grantCatalog lists the permissions of each resource of each grant type that roles are known to be given, such as
+// the api agentHub grant of the Remote Network Agent role. The API may accept grants that are not listed here, so
+// grants outside the catalog are reported as warnings rather than errors.
+var grantCatalog = map[string]map[string][]string{
+	"api": {
+		"organization": {"full"},
+		"tenant":       {"full"},
+		"deployment":   {"full"},
+		"agentHub":     {"full"},
+	},
+}
+
+// ValidateRBACFindings checks an RBAC configuration file against its schema and the grant catalog. Roles that are
+// defined twice in a tenant, tenants that are listed twice, and roles of tenants that allowAutoDelete would delete are
+// reported too.
+func ValidateRBACFindings(file []byte) ([]findings.Finding, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(file, &document); err != nil {
+		return nil, err
+	}
+	if len(document.Content) == 0 {
+		return []findings.Finding{}, nil
+	}
+	root := document.Content[0]
+	schema := cuecontext.New().CompileBytes(rbacSchemaFile).LookupPath(cue.ParsePath(rbacDefinition))
+	c := &semanticChecker{findings: schemaFindings(cueyaml.Validate(file, schema), root)}
+	if root.Kind != yaml.MappingNode {
+		return c.findings, nil
+	}
+	tenants := util.MappingValue(root, "tenants")
+	listed := c.checkTenants(tenants)
+	c.checkRoles(util.MappingValue(root, "roles"))
+	if tenants.Kind == yaml.SequenceNode && util.MappingValue(root, "allowAutoDelete").Value == "true" {
+		c.checkDeletedTenants(util.MappingValue(root, "roles"), listed)
+	}
+	sort.SliceStable(c.findings, func(i, j int) bool { return c.findings[i].Line < c.findings[j].Line })
+	return c.findings, nil
+}
+
+// checkTenants reports the tenants that are listed more than once and returns the names of those that are listed.
+func (c *semanticChecker) checkTenants(tenants *yaml.Node) map[string]bool {
+	seen := map[string]bool{}
+	for i, tenant := range util.SequenceItems(tenants) {
+		name := tenant
+		if tenant.Kind == yaml.MappingNode {
+			name = util.MappingValue(tenant, "name")
+		}
+		if name.Kind != yaml.ScalarNode || name.Value == "" {
+			continue
+		}
+		if seen[name.Value] {
+			c.report(ruleTenantName, fmt.Sprintf("tenants[%d]", i), name, "tenant %q is listed more than once", name.Value)
+		}
+		seen[name.Value] = true
+	}
+	return seen
+}
+
+// checkDeletedTenants reports the roles of tenants that are not listed, since apply deletes those tenants, along with
+// their roles, when allowAutoDelete is set.
+func (c *semanticChecker) checkDeletedTenants(roles *yaml.Node, listed map[string]bool) {
+	for i, role := range util.SequenceItems(roles) {
+		if role.Kind != yaml.MappingNode {
+			continue
+		}
+		tenant := util.MappingValue(role, "tenant")
+		if tenant.Kind != yaml.ScalarNode || tenant.Value == "" || listed[tenant.Value] {
+			continue
+		}
+		c.report(ruleTenantDeleted, fmt.Sprintf("roles[%d].tenant", i), tenant,
+			"tenant %q is not listed in tenants, it would be deleted because allowAutoDelete is set", tenant.Value)
+	}
+}
+
+func (c *semanticChecker) checkRoles(roles *yaml.Node) {
+	seen := map[string]bool{}
+	for i, role := range util.SequenceItems(roles) {
+		if role.Kind != yaml.MappingNode {
+			continue
+		}
+		path := fmt.Sprintf("roles[%d]", i)
+		name, tenant := util.MappingValue(role, "name"), util.MappingValue(role, "tenant")
+		if name.Value != "" {
+			key := tenant.Value + "/" + name.Value
+			if seen[key] {
+				c.report(ruleDuplicateRole, joinPath(path, "name"), name, "role %q is defined more than once%s", name.Value,
+					lo.Ternary(tenant.Value == "", "", " in tenant "+tenant.Value))
+			}
+			seen[key] = true
+		}
+		for j, grant := range util.SequenceItems(util.MappingValue(role, "grants")) {
+			if grant.Kind == yaml.MappingNode {
+				c.checkGrant(fmt.Sprintf("%s.grants[%d]", path, j), grant)
+			}
+		}
+	}
+}
+
+// checkGrant checks a grant against the catalog, warning about the first of its type, resource and permission that is
+// not known.
+func (c *semanticChecker) checkGrant(path string, grant *yaml.Node) {
+	grantType := util.MappingValue(grant, "type")
+	resource := util.MappingValue(grant, "resource")
+	permission := util.MappingValue(grant, "permission")
+	if grantType.Kind == 0 || resource.Kind == 0 || permission.Kind == 0 {
+		// missing fields are reported by the schema
+		return
+	}
+	resources, ok := grantCatalog[grantType.Value]
+	if !ok {
+		types := lo.Keys(grantCatalog)
+		c.reportWithSeverity(findings.SeverityWarning, ruleGrant, joinPath(path, "type"), grantType, "grant type %q is not known, expected one of %s%s",
+			grantType.Value, quotedList(types), suggestion(grantType.Value, types))
+		return
+	}
+	permissions, ok := resources[resource.Value]
+	if !ok {
+		names := lo.Keys(resources)
+		c.reportWithSeverity(findings.SeverityWarning, ruleGrant, joinPath(path, "resource"), resource, "resource %q is not known for grant type %s, expected one of %s%s",
+			resource.Value, grantType.Value, quotedList(names), suggestion(resource.Value, names))
+		return
+	}
+	if !lo.Contains(permissions, permission.Value) {
+		c.reportWithSeverity(findings.SeverityWarning, ruleGrant, joinPath(path, "permission"), permission, "permission %q is not known for resource %s, expected one of %s%s",
+			permission.Value, resource.Value, quotedList(permissions), suggestion(permission.Value, permissions))
+	}
+}
+
+func quotedList(values []string) string {
+	sorted := append([]string(nil), values...)
+	sort.Strings(sorted)
+	return strings.Join(lo.Map(sorted, func(v string, _ int) string { return fmt.Sprintf("%q", v) }), ", ")
+}
@@ -0,0 +1,127 @@
+package validate
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/armory/armory-cli/pkg/findings"
+	"github.com/armory/armory-cli/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+const invalidRBACYamlStr = `allowAutoDelete: true
+tenants:
+  - staging
+  - name: production
+    previousName: prod
+  - staging
+roles:
+  - name: deployer
+    tenant: staging
+    grants:
+      - type: api
+        resource: deploymnet
+        permission: full
+  - name: deployer
+    tenant: staging
+    grants:
+      - type: api
+        resource: tenant
+        permission: read
+  - name: viewer
+    grants:
+      - type: ui
+        resource: tenant
+        permission: full
+      - type: api
+        resource: organization
+`
+
+func TestValidateRBACFindings(t *testing.T) {
+	found, err := ValidateRBACFindings([]byte(invalidRBACYamlStr))
+	assert.NoError(t, err)
+	assert.Equal(t, []findings.Finding{
+		{Path: "#RBACConfiguration.roles.2.grants.1.permission", Message: "incomplete value string", Severity: findings.SeverityError, Rule: ruleSchema},
+		{Path: "tenants[2]", Line: 6, Column: 5, Message: `tenant "staging" is listed more than once`, Severity: findings.SeverityError, Rule: ruleTenantName},
+		{Path: "roles[0].grants[0].resource", Line: 12, Column: 19, Message: `resource "deploymnet" is not known for grant type api, expected one of "agentHub", "deployment", "organization", "tenant", did you mean "deployment"?`, Severity: findings.SeverityWarning, Rule: ruleGrant},
+		{Path: "roles[1].name", Line: 14, Column: 11, Message: `role "deployer" is defined more than once in tenant staging`, Severity: findings.SeverityError, Rule: ruleDuplicateRole},
+		{Path: "roles[1].grants[0].permission", Line: 19, Column: 21, Message: `permission "read" is not known for resource tenant, expected one of "full"`, Severity: findings.SeverityWarning, Rule: ruleGrant},
+		{Path: "roles[2].grants[0].type", Line: 22, Column: 15, Message: `grant type "ui" is not known, expected one of "api"`, Severity: findings.SeverityWarning, Rule: ruleGrant},
+	}, found)
+}
+
+func TestValidateRBACFindingsSchema(t *testing.T) {
+	cases := []struct {
+		name string
+		file string
+	}{
+		{name: "tenants not a list", file: "tenants: staging\n"},
+		{name: "unknown field", file: "roles:\n  - name: deployer\n    grants: []\n    tenants: [staging]\n"},
+		{name: "empty role name", file: "roles:\n  - name: \"\"\n    grants: []\n"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			found, err := ValidateRBACFindings([]byte(c.file))
+			assert.NoError(t, err)
+			assert.Len(t, found, 1)
+			assert.Equal(t, ruleSchema, found[0].Rule)
+		})
+	}
+}
+
+func TestValidateRBACFindingsDeletedTenant(t *testing.T) {
+	file := `tenants:
+  - staging
+roles:
+  - name: deployer
+    tenant: production
+    grants: []
+`
+	found, err := ValidateRBACFindings([]byte("allowAutoDelete: true\n" + file))
+	assert.NoError(t, err)
+	assert.Equal(t, []findings.Finding{
+		{Path: "roles[0].tenant", Line: 6, Column: 13, Message: `tenant "production" is not listed in tenants, it would be deleted because allowAutoDelete is set`, Severity: findings.SeverityError, Rule: ruleTenantDeleted},
+	}, found)
+
+	found, err = ValidateRBACFindings([]byte("allowAutoDelete: false\n" + file))
+	assert.NoError(t, err)
+	assert.Empty(t, found)
+}
+
+func TestValidateRBACFindingsValid(t *testing.T) {
+	found, err := ValidateRBACFindings([]byte(`tenants:
+  - staging
+roles:
+  - name: Organization Admin
+    grants:
+      - type: api
+        resource: organization
+        permission: full
+  - name: deployer
+    tenant: staging
+    grants:
+      - type: api
+        resource: deployment
+        permission: full
+`))
+	assert.NoError(t, err)
+	assert.Empty(t, found)
+}
+
+func TestValidateKindRBAC(t *testing.T) {
+	t.Setenv("GITHUB_ACTIONS", "")
+	tempFile := util.TempAppFile("", "rbac.yaml", "roles:\n  - name: deployer\n    grants:\n      - type: api\n        resource: tenant\n        permission: ful\n")
+	if tempFile == nil {
+		t.Fatal("TestValidateKindRBAC failed with: Could not create temp app file.")
+	}
+
+	outWriter := bytes.NewBufferString("")
+	err := getValidateCmdWithFileName(outWriter, tempFile.Name(), "text", "--kind", "rbac").Execute()
+	assert.NoError(t, err)
+	assert.Equal(t, "YAML is valid. See the following warnings:\n\n"+
+		`roles[0].grants[0].permission: line 6, column 21: permission "ful" is not known for resource tenant, expected one of "full"`+"\n", outWriter.String())
+
+	err = getValidateCmdWithFileName(io.Discard, tempFile.Name(), "text", "--kind", "helm").Execute()
+	assert.ErrorIs(t, err, ErrUnknownKind)
+}
@@ -0,0 +1,177 @@
+package lambdaPipelineRequest
+
+import "list"
+import "struct"
+
+#LambdaPipelineRequest: {
+  version: string | *"v1"
+  kind: "lambda"
+  application: string
+  description?: string
+  context?: [string]: string
+  deploymentConfig?: #DeploymentConfig
+  targets: [string]: #LambdaTarget
+  targets: struct.MinFields(1)
+  targets: [string]: {
+    constraints?: #Constraints & { dependsOn?: [... or(_targetNames)]}
+  }
+  _targetNames: [ for k, v in targets {k}]
+  artifacts: [... #Artifact]
+  artifacts: list.MinItems(1)
+  _functionNames: [ for a in artifacts {a.functionName}]
+  providerOptions: {
+    lambda: [... #LambdaFunctionOptions & { name: or(_functionNames), target: or(_targetNames) }]
+    lambda: list.MinItems(1)
+  }
+  strategies: #Strategies
+  _strategyNames: [ for k, v in strategies {k}]
+  targets: [string]: { strategy?: or(_strategyNames) }
+  analysis?: #PipelineAnalysisConfig
+  webhooks?: [... #WebhookConfig]
+  targetFilters?: [... #IncludeTargetByName & { includeTarget: or(_targetNames) }]
+  targetFilters?: list.MinItems(1)
+}
+
+#TimeUnit: =~ "(?i)^none|seconds|minutes|hours$"
+#RollMode: =~ "(?i)^automatic|manual$"
+#LookbackMethod: =~ "(?i)^growing|sliding$"
+
+#DeploymentConfig: {
+  timeout?: #Timeout
+}
+
+#Timeout: {
+  duration: >=1
+  unit: #TimeUnit
+}
+
+#LambdaTarget: {
+  account: string
+  deployAsIamRole: string
+  region: string
+  strategy?: string
+  constraints?: #Constraints
+}
+
+#Artifact: {
+  functionName: string
+  path: string
+  type: "zipFile"
+}
+
+// Function settings are passed through to AWS, so settings other than the required ones are not checked here.
+#LambdaFunctionOptions: {
+  name: string
+  target: string
+  runAsIamRole: string
+  handler: string
+  runtime: string
+  ...
+}
+
+#Constraints: {
+  dependsOn?: [... string]
+  dependsOn?: list.MinItems(1)
+  beforeDeployment?: [... #BeforeDeploymentStep]
+  afterDeployment?: [... #AfterDeploymentStep]
+}
+
+#BeforeDeploymentStep: #PauseStep | #WebhookStep
+#AfterDeploymentStep: #PauseStep | #WebhookStep | #AnalysisStep
+#CanaryStep: #WeightStep | #PauseStep | #WebhookStep | #AnalysisStep
+
+#PauseStep: {
+  pause: {
+    untilApproved: bool
+    requiresRoles?: [... string]
+    requiresRoles?: list.MinItems(1)
+    approvalExpiration?: #Timeout
+  } | {
+    duration: int
+    unit: #TimeUnit
+  }
+}
+
+#WebhookStep: {
+  runWebhook: {
+    name: string
+    context?: [string]: string
+  }
+}
+
+#WeightStep: {
+  setWeight: {
+    weight: int
+  }
+}
+
+#AnalysisStep: {
+  analysis: {
+    context?: [string]: string
+    rollBackMode: #RollMode
+    rollForwardMode: #RollMode
+    interval: >=1
+    units: #TimeUnit
+    numberOfJudgmentRuns: >=1
+    queries: [... string]
+    queries: list.MinItems(1)
+    lookbackMethod: #LookbackMethod
+    abortOnFailedJudgment?:bool
+    metricProviderName?: string
+  }
+}
+
+#Strategies: [string]: #Strategy
+
+#Strategy: #LambdaCanaryStrategy
+
+#LambdaCanaryStrategy: {
+  canary: {
+    steps: [... #CanaryStep]
+  }
+}
+
+#PipelineAnalysisConfig: {
+  defaultMetricProviderName?: string
+  queries: [... #Query]
+}
+
+#Query: this={
+  name: string
+  queryTemplate: string
+  upperLimit?: float
+  lowerLimit?: float
+  #AnyOfLimits: true & list.MinItems([ for label, _ in this if list.Contains(["upperLimit", "lowerLimit"], label) {label}], 1)
+  metricProviderName?: string
+}
+
+#WebhookConfig: {
+  name: string
+  method?: string
+  uriTemplate: string
+  networkMode?: "direct" | "remoteNetworkAgent"
+  isRemoteNetworkAgent: bool | *false
+  if networkMode != _|_ {
+    isRemoteNetworkAgent: networkMode == "remoteNetworkAgent"
+  }
+  if isRemoteNetworkAgent == true {
+    agentIdentifier?: string
+  }
+  headers?: [... #Header]
+  bodyTemplate?: #Body
+  retryCount?: int
+  disableCallback?: bool
+}
+
+#Header: {
+  key: string
+  value: string
+}
+
+#Body: {
+  inline: string
+}
+
+#IncludeTargetByName: {
+	includeTarget: string
+}
@@ -121,7 +121,14 @@ import "struct"
   targets?: [... string]
 }
 
-#PathOrInline: {path: string} | {inline: string}
+#PathOrInline: {path: string} | {inline: string} | {kustomize: string} | {helm: #HelmChart}
+
+#HelmChart: {
+  chart: string
+  releaseName?: string
+  valuesFiles?: [... string]
+  set?: [string]: string | number | bool | [...] | {...}
+}
 
 #Strategies: [string]: #Strategy
 
@@ -0,0 +1,27 @@
+package rbacConfiguration
+
+#RBACConfiguration: {
+  allowAutoDelete?: bool
+  tenants?: [... #Tenant]
+  roles?: [... #Role]
+}
+
+#Tenant: #TenantName | {
+  name: #TenantName
+  previousName?: #TenantName
+}
+
+#TenantName: string & !=""
+
+#Role: {
+  name: string & !=""
+  tenant?: #TenantName
+  grants: [... #Grant]
+}
+
+// the combinations of type, resource and permission are checked against the grant catalog
+#Grant: {
+  type: string
+  resource: string
+  permission: string
+}
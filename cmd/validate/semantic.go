@@ -0,0 +1,278 @@
+package validate
+
+import (
+	"fmt"
+	deployment "github.com/armory/armory-cli/pkg/deploy"
+	"github.com/armory/armory-cli/pkg/findings"
+	"github.com/armory/armory-cli/pkg/util"
+	"github.com/samber/lo"
+	"gopkg.in/yaml.v3"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Rules of the semantic checks, reported with their findings.
+const (
+	ruleWebhookReference   = "webhook-reference"
+	ruleQueryReference     = "query-reference"
+	ruleDependencyCycle    = "dependency-cycle"
+	ruleMetricProviderName = "metric-provider-name"
+	ruleManifestPath       = "manifest-path"
+	ruleManifestReference  = "manifest-reference"
+	ruleWorkload           = "workload"
+)
+
+type semanticChecker struct {
+	// values are substituted into the manifests before their objects are read
+	values    *deployment.Values
+	webhooks  []string
+	queries   []string
+	providers []providerUse
+	findings  []findings.Finding
+}
+
+// providerUse is a metric provider name and where it appears, used to spot names that are likely typos of each other.
+type providerUse struct {
+	name string
+	path string
+	node *yaml.Node
+}
+
+// ValidateSemantics checks the references between the sections of a deployment file: webhooks and analysis queries
+// used by steps must be defined, targets must not depend on each other in a cycle, metric provider names must be
+// consistent and manifest paths must exist. Services and traffic management resources are checked against the
+// objects in the manifests, once the values are substituted into them. The file is expected to be well-formed YAML,
+// with its own values already substituted.
+func ValidateSemantics(file []byte, values *deployment.Values) ([]findings.Finding, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(file, &document); err != nil {
+		return nil, err
+	}
+	if len(document.Content) == 0 || document.Content[0].Kind != yaml.MappingNode {
+		return []findings.Finding{}, nil
+	}
+	root := document.Content[0]
+
+	c := &semanticChecker{values: values}
+	c.collectDefinitions(root)
+	c.walk(root, "", true)
+	c.checkDependencyCycles(util.MappingValue(root, "targets"))
+	c.checkMetricProviders()
+	if util.MappingValue(root, "kind").Value != lambdaKind {
+		c.checkManifestPaths(util.MappingValue(root, "manifests"))
+		c.checkManifestContents(root)
+	}
+
+	sort.SliceStable(c.findings, func(i, j int) bool {
+		if c.findings[i].Line != c.findings[j].Line {
+			return c.findings[i].Line < c.findings[j].Line
+		}
+		return c.findings[i].Column < c.findings[j].Column
+	})
+	return c.findings, nil
+}
+
+func (c *semanticChecker) report(rule string, path string, node *yaml.Node, format string, args ...any) {
+	c.reportWithSeverity(findings.SeverityError, rule, path, node, format, args...)
+}
+
+func (c *semanticChecker) reportWithSeverity(severity findings.Severity, rule string, path string, node *yaml.Node, format string, args ...any) {
+	c.findings = append(c.findings, findings.Finding{
+		Path:     path,
+		Line:     node.Line,
+		Column:   node.Column,
+		Message:  fmt.Sprintf(format, args...),
+		Severity: severity,
+		Rule:     rule,
+	})
+}
+
+func (c *semanticChecker) collectDefinitions(root *yaml.Node) {
+	for _, webhook := range util.SequenceItems(util.MappingValue(root, "webhooks")) {
+		if name := util.MappingValue(webhook, "name"); name.Value != "" {
+			c.webhooks = append(c.webhooks, name.Value)
+		}
+	}
+
+	analysis := util.MappingValue(root, "analysis")
+	if provider := util.MappingValue(analysis, "defaultMetricProviderName"); provider.Value != "" {
+		c.providers = append(c.providers, providerUse{name: provider.Value, path: "analysis.defaultMetricProviderName", node: provider})
+	}
+	for i, query := range util.SequenceItems(util.MappingValue(analysis, "queries")) {
+		if name := util.MappingValue(query, "name"); name.Value != "" {
+			c.queries = append(c.queries, name.Value)
+		}
+		if provider := util.MappingValue(query, "metricProviderName"); provider.Value != "" {
+			c.providers = append(c.providers, providerUse{name: provider.Value, path: fmt.Sprintf("analysis.queries[%d].metricProviderName", i), node: provider})
+		}
+	}
+}
+
+// walk visits every step of the file, wherever it is declared, checking the webhooks and queries it references.
+func (c *semanticChecker) walk(node *yaml.Node, path string, isRoot bool) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i].Value, node.Content[i+1]
+			childPath := joinPath(path, key)
+			switch {
+			case key == "runWebhook":
+				c.checkWebhookStep(childPath, value)
+			case key == "analysis" && !isRoot:
+				c.checkAnalysisStep(childPath, value)
+			}
+			c.walk(value, childPath, false)
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			c.walk(item, fmt.Sprintf("%s[%d]", path, i), false)
+		}
+	}
+}
+
+func (c *semanticChecker) checkWebhookStep(path string, step *yaml.Node) {
+	name := util.MappingValue(step, "name")
+	if name.Kind != yaml.ScalarNode || lo.Contains(c.webhooks, name.Value) {
+		return
+	}
+	c.report(ruleWebhookReference, joinPath(path, "name"), name, "webhook %q is not defined in webhooks%s", name.Value, suggestion(name.Value, c.webhooks))
+}
+
+func (c *semanticChecker) checkAnalysisStep(path string, step *yaml.Node) {
+	for i, query := range util.SequenceItems(util.MappingValue(step, "queries")) {
+		if query.Kind != yaml.ScalarNode || lo.Contains(c.queries, query.Value) {
+			continue
+		}
+		c.report(ruleQueryReference, fmt.Sprintf("%s.queries[%d]", path, i), query, "query %q is not defined in analysis.queries%s", query.Value, suggestion(query.Value, c.queries))
+	}
+	if provider := util.MappingValue(step, "metricProviderName"); provider.Value != "" {
+		c.providers = append(c.providers, providerUse{name: provider.Value, path: joinPath(path, "metricProviderName"), node: provider})
+	}
+}
+
+// checkDependencyCycles reports every dependsOn entry that closes a cycle between targets, since such targets would
+// never be deployed.
+func (c *semanticChecker) checkDependencyCycles(targets *yaml.Node) {
+	type dependency struct {
+		target string
+		node   *yaml.Node
+		index  int
+	}
+	var names []string
+	dependsOn := map[string][]dependency{}
+	for i := 0; i+1 < len(targets.Content); i += 2 {
+		name := targets.Content[i].Value
+		names = append(names, name)
+		for j, item := range util.SequenceItems(util.MappingValue(util.MappingValue(targets.Content[i+1], "constraints"), "dependsOn")) {
+			dependsOn[name] = append(dependsOn[name], dependency{target: item.Value, node: item, index: j})
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := map[string]int{}
+	var stack []string
+	var visit func(name string)
+	visit = func(name string) {
+		state[name] = visiting
+		stack = append(stack, name)
+		for _, dep := range dependsOn[name] {
+			switch state[dep.target] {
+			case visiting:
+				cycle := append(append([]string{}, stack[lo.IndexOf(stack, dep.target):]...), dep.target)
+				c.report(ruleDependencyCycle, fmt.Sprintf("targets.%s.constraints.dependsOn[%d]", name, dep.index), dep.node,
+					"dependsOn creates a cycle between targets: %s", strings.Join(cycle, " -> "))
+			case unvisited:
+				if lo.Contains(names, dep.target) {
+					visit(dep.target)
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[name] = visited
+	}
+	for _, name := range names {
+		if state[name] == unvisited {
+			visit(name)
+		}
+	}
+}
+
+// checkMetricProviders reports metric provider names that only differ slightly from another name used in the file.
+// The providers themselves are configured outside the deployment file, so near matches are the best hint of a typo.
+func (c *semanticChecker) checkMetricProviders() {
+	for i, use := range c.providers {
+		for _, other := range c.providers[:i] {
+			if use.name != other.name && isLikelyTypo(use.name, other.name) {
+				c.reportWithSeverity(findings.SeverityWarning, ruleMetricProviderName, use.path, use.node, "metric provider %q is similar to %q at line %d, check for a typo", use.name, other.name, other.node.Line)
+				break
+			}
+		}
+	}
+}
+
+func (c *semanticChecker) checkManifestPaths(manifests *yaml.Node) {
+	for i, manifest := range util.SequenceItems(manifests) {
+		for _, key := range []string{"path", "kustomize"} {
+			path := util.MappingValue(manifest, key)
+			if path.Kind != yaml.ScalarNode || path.Value == "" || deployment.IsURL(path.Value) {
+				continue
+			}
+			if _, err := os.Stat(deployment.WorkspacePath(path.Value)); err != nil {
+				c.report(ruleManifestPath, fmt.Sprintf("manifests[%d].%s", i, key), path, "manifest path %q does not exist", path.Value)
+			}
+		}
+	}
+}
+
+// joinPath appends a key to the path of a finding, which is the key itself at the root.
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// suggestion proposes the closest defined name when the given one looks like a typo of it.
+func suggestion(name string, defined []string) string {
+	for _, candidate := range defined {
+		if isLikelyTypo(name, candidate) {
+			return fmt.Sprintf(", did you mean %q?", candidate)
+		}
+	}
+	return ""
+}
+
+func isLikelyTypo(a, b string) bool {
+	if strings.EqualFold(a, b) {
+		return true
+	}
+	if len(a) < 4 || len(b) < 4 {
+		return false
+	}
+	return editDistance(strings.ToLower(a), strings.ToLower(b)) <= 2
+}
+
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = lo.Min([]int{previous[j] + 1, current[j-1] + 1, previous[j-1] + cost})
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
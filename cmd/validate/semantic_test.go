@@ -0,0 +1,72 @@
+package validate
+
+import (
+	"github.com/armory/armory-cli/pkg/findings"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const semanticErrorsYamlStr = `version: v1
+kind: kubernetes
+application: deployment-test
+targets:
+  dev:
+    account: dev
+    namespace: dev
+    strategy: canary
+    constraints:
+      dependsOn: ["prod"]
+      beforeDeployment:
+        - runWebhook:
+            name: check-loggs
+  prod:
+    account: prod
+    namespace: prod
+    strategy: canary
+    constraints:
+      dependsOn: ["dev"]
+manifests:
+  - path: missing.yaml
+  - path: deployment.yaml
+  - path: https://example.com/deployment.yaml
+strategies:
+  canary:
+    canary:
+      steps:
+        - analysis:
+            metricProviderName: prometeus
+            queries: [avgCPU, avgMemory]
+        - runWebhook:
+            name: check-logs
+analysis:
+  defaultMetricProviderName: prometheus
+  queries:
+    - name: avgCPU
+      queryTemplate: cpu
+      upperLimit: 100
+webhooks:
+  - name: check-logs
+    uriTemplate: https://example.com
+`
+
+func TestValidateSemantics(t *testing.T) {
+	workspace := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(workspace, "deployment.yaml"), []byte("kind: Deployment\n"), 0o644))
+	t.Setenv("GITHUB_WORKSPACE", workspace)
+
+	errs, err := ValidateSemantics([]byte(semanticErrorsYamlStr), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []findings.Finding{
+		{Path: "targets.dev.constraints.beforeDeployment[0].runWebhook.name", Line: 13, Column: 19, Message: `webhook "check-loggs" is not defined in webhooks, did you mean "check-logs"?`, Severity: findings.SeverityError, Rule: ruleWebhookReference},
+		{Path: "targets.prod.constraints.dependsOn[0]", Line: 19, Column: 19, Message: "dependsOn creates a cycle between targets: dev -> prod -> dev", Severity: findings.SeverityError, Rule: ruleDependencyCycle},
+		{Path: "manifests[0].path", Line: 21, Column: 11, Message: `manifest path "missing.yaml" does not exist`, Severity: findings.SeverityError, Rule: ruleManifestPath},
+		{Path: "strategies.canary.canary.steps[0].analysis.metricProviderName", Line: 29, Column: 33, Message: `metric provider "prometeus" is similar to "prometheus" at line 34, check for a typo`, Severity: findings.SeverityWarning, Rule: ruleMetricProviderName},
+		{Path: "strategies.canary.canary.steps[0].analysis.queries[1]", Line: 30, Column: 31, Message: `query "avgMemory" is not defined in analysis.queries`, Severity: findings.SeverityError, Rule: ruleQueryReference},
+	}, errs)
+
+	errs, err = ValidateSemantics([]byte(rnaWebhookWithImplicitAgent), nil)
+	assert.NoError(t, err)
+	assert.Empty(t, errs)
+}
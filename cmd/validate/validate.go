@@ -2,25 +2,43 @@ package validate
 
 import (
 	_ "embed"
+	"fmt"
+	"github.com/armory/armory-cli/pkg/findings"
+	"github.com/armory/armory-cli/pkg/output"
+	log "go.uber.org/zap"
 	"gopkg.in/yaml.v3"
 	"io"
+	"net/http"
 	"os"
+	"strconv"
 	"strings"
 
 	"cuelang.org/go/cue"
 	"cuelang.org/go/cue/cuecontext"
 	cueerrors "cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
 	cueyaml "cuelang.org/go/encoding/yaml"
 	"github.com/armory/armory-cli/cmd/utils"
+	"github.com/armory/armory-cli/cmd/version"
 	"github.com/armory/armory-cli/pkg/cmdUtils"
 	"github.com/armory/armory-cli/pkg/config"
+	deployment "github.com/armory/armory-cli/pkg/deploy"
+	errorUtils "github.com/armory/armory-cli/pkg/errors"
+	"github.com/armory/armory-cli/pkg/util"
 	"github.com/samber/lo"
 	"github.com/spf13/cobra"
 )
 
 const (
 	validateShort = "Validate deployment yaml"
-	validateLong  = "Validate deployment yaml\n\n" +
+	validateLong  = "Validate deployment yaml of kind kubernetes or lambda, or an RBAC configuration file with --kind rbac. " +
+		"With --kind kubernetes or lambda, the deployment file is validated against the schema of that kind, whatever " +
+		"kind it declares\n\n" +
+		"Besides the schema, the references between sections are checked: webhooks and analysis queries used by steps, " +
+		"dependsOn cycles between targets, metric provider names and manifest paths. The manifests are read to check that " +
+		"exposed services and traffic management resources are deployed, and that every target deploys a workload. The " +
+		"grants of RBAC configuration files are checked against the grant types, resources and permissions roles can " +
+		"be given\n\n" +
 		"For deployment configuration YAML documentation, visit https://docs.armory.io/cd-as-a-service/reference/ref-deployment-file"
 	validateExample = "armory deploy validate [options]"
 )
@@ -28,8 +46,43 @@ const (
 //go:embed resources/pipelineRequest.cue
 var schemaFile []byte
 
+//go:embed resources/lambdaPipelineRequest.cue
+var lambdaSchemaFile []byte
+
+const (
+	kubernetesKind = "kubernetes"
+	lambdaKind     = "lambda"
+)
+
+// requestSchema is the CUE definition that pipeline requests of a kind are validated against.
+type requestSchema struct {
+	file       []byte
+	definition string
+}
+
+var schemasByKind = map[string]requestSchema{
+	kubernetesKind: {file: schemaFile, definition: "#PipelineRequest"},
+	lambdaKind:     {file: lambdaSchemaFile, definition: "#LambdaPipelineRequest"},
+}
+
+// Schema returns the CUE schema that deployment files of a kind are validated against, and the name of its definition.
+func Schema(kind string) ([]byte, string, bool) {
+	requestSchema, ok := schemasByKind[kind]
+	return requestSchema.file, requestSchema.definition, ok
+}
+
+const (
+	ruleSchema = "schema"
+	// cueYamlInput is the name CUE gives to the YAML being validated in the positions of its errors.
+	cueYamlInput = "yaml.Validate"
+)
+
 type validateOptions struct {
 	deploymentFile string
+	kind           string
+	valuesFiles    []string
+	setValues      []string
+	strictValues   bool
 }
 
 func NewValidateCmd(configuration *config.Configuration) *cobra.Command {
@@ -49,6 +102,10 @@ func NewValidateCmd(configuration *config.Configuration) *cobra.Command {
 		},
 	}
 	cmd.Flags().StringVarP(&options.deploymentFile, "file", "f", "", "path to the deployment file")
+	cmd.Flags().StringArrayVar(&options.valuesFiles, "values", []string{}, "YAML file of values to substitute for ${key} and {{ .key }} placeholders in the deployment file and manifests. Can be repeated, later files take precedence")
+	cmd.Flags().StringArrayVar(&options.setValues, "set", []string{}, "set a value to substitute, as key=value. Nested keys are separated by dots. Takes precedence over --values")
+	cmd.Flags().BoolVar(&options.strictValues, "strict", false, "fail if a placeholder in the deployment file or manifests has no value")
+	cmd.Flags().StringVarP(&options.kind, "kind", "", "", "kind of file: kubernetes or lambda for a deployment file, validated against the schema of that kind, or rbac for an RBAC configuration file. By default, the kind declared by the deployment file")
 	return cmd
 }
 
@@ -56,36 +113,165 @@ func validateCommand(cmd *cobra.Command, configuration *config.Configuration, op
 	if *configuration.GetIsTest() {
 		utils.ConfigureLoggingForTesting(cmd)
 	}
+	if _, ok := schemasByKind[options.kind]; !ok && options.kind != "" && options.kind != rbacKind {
+		return errorUtils.NewErrorWithDynamicContext(ErrUnknownKind, ": "+options.kind)
+	}
 	file, err := os.ReadFile(options.deploymentFile)
 	if err != nil {
 		return err
 	}
-	validationFailures, err := Validate(file)
+	var found []findings.Finding
+	if options.kind == rbacKind {
+		found, err = ValidateRBACFindings(file)
+	} else {
+		found, err = validateDeploymentFile(file, options)
+	}
+	if err != nil {
+		return err
+	}
+	for i := range found {
+		found[i].File = options.deploymentFile
+	}
+
+	// if we've made it this far, the command is valid. if an error occurs it isn't a usage error
+	cmd.SilenceUsage = true
+	if err := LogFindings(configuration, FormattableValidationResult{Findings: found}, found); err != nil {
+		return err
+	}
+	if findings.HasErrors(found) {
+		return lo.Ternary(options.kind == rbacKind, ErrInvalidRBACFile, ErrInvalidDeploymentFile)
+	}
+	return nil
+}
+
+// validateDeploymentFile validates a deployment file as deploy start sends it, once the values are substituted.
+func validateDeploymentFile(file []byte, options *validateOptions) ([]findings.Finding, error) {
+	var values *deployment.Values
+	if len(options.valuesFiles) > 0 || len(options.setValues) > 0 || options.strictValues {
+		var err error
+		if values, err = deployment.NewValues(options.valuesFiles, options.setValues, options.strictValues); err != nil {
+			return nil, err
+		}
+	}
+	file, err := values.Render(options.deploymentFile, file)
+	if err != nil {
+		return nil, err
+	}
+	return validateFindingsOfKind(file, options.kind, values)
+}
+
+// LogFindings writes the findings of a check of a deployment file in the configured output format. With the text
+// output in a GitHub Actions workflow, they are written as annotations so that they are shown inline in pull requests.
+func LogFindings(configuration *config.Configuration, result output.Formattable, found []findings.Finding) error {
+	if configuration.GetOutputType() == output.Text && findings.InGitHubActions() {
+		for _, finding := range found {
+			log.S().Info(finding.GitHubAnnotation())
+		}
+		return nil
+	}
+	dataFormat, err := configuration.GetOutputFormatter()(result)
 	if err != nil {
 		return err
 	}
-	return LogValidationErrors(cmd.OutOrStdout(), validationFailures, true)
+	log.S().Info(dataFormat)
+	return nil
 }
 
-func Validate(file []byte) ([]string, error) {
+// Validate checks a deployment file against the schema of its kind and the semantic checks, returning a description
+// of each finding. The values are substituted into the manifests, the deployment file is expected to be rendered.
+func Validate(file []byte, values *deployment.Values) ([]string, error) {
+	found, err := ValidateFindings(file, values)
+	if err != nil {
+		return nil, err
+	}
+	return lo.Map(found, func(f findings.Finding, _ int) string { return f.String() }), nil
+}
+
+// ValidateFindings checks a deployment file against the schema of its kind and the semantic checks. Kinds without a
+// schema are not checked. The values are substituted into the manifests, the deployment file is expected to be
+// rendered.
+func ValidateFindings(file []byte, values *deployment.Values) ([]findings.Finding, error) {
+	return validateFindingsOfKind(file, "", values)
+}
+
+// validateFindingsOfKind checks a deployment file against the schema of a kind, or of the kind the file declares when
+// kind is empty, and the semantic checks.
+func validateFindingsOfKind(file []byte, kind string, values *deployment.Values) ([]findings.Finding, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(file, &document); err != nil {
+		return nil, err
+	}
 	var requestKind struct {
 		Kind string `json:"kind"`
 	}
-	if err := yaml.Unmarshal(file, &requestKind); err != nil {
+	if err := document.Decode(&requestKind); err != nil {
 		return nil, err
 	}
 
-	// TODO: make this work for lambda deployment kinds CDAAS-2509
-	if requestKind.Kind == "kubernetes" {
-		cueContext := cuecontext.New()
-		v := cueContext.CompileBytes(schemaFile)
-		schema := v.LookupPath(cue.ParsePath("#PipelineRequest"))
-		err := cueyaml.Validate(file, schema)
-		errList := cueerrors.Errors(err)
-		return lo.Map(errList, func(e cueerrors.Error, _ int) string { return e.Error() }), nil
+	if kind == "" {
+		kind = requestKind.Kind
+	}
+	requestSchema, ok := schemasByKind[kind]
+	if !ok {
+		return []findings.Finding{}, nil
 	}
+	cueContext := cuecontext.New()
+	v := cueContext.CompileBytes(requestSchema.file)
+	schema := v.LookupPath(cue.ParsePath(requestSchema.definition))
+	found := schemaFindings(cueyaml.Validate(file, schema), document.Content[0])
+	semanticFindings, err := ValidateSemantics(file, values)
+	if err != nil {
+		return nil, err
+	}
+	return append(found, semanticFindings...), nil
+}
 
-	return []string{}, nil
+// schemaFindings converts CUE validation errors to findings, located by the position of the offending YAML value or,
+// when CUE does not report one, by the path of the error.
+func schemaFindings(err error, root *yaml.Node) []findings.Finding {
+	return lo.Map(cueerrors.Errors(err), func(e cueerrors.Error, _ int) findings.Finding {
+		format, args := e.Msg()
+		message := fmt.Sprintf(format, args...)
+		finding := findings.Finding{
+			Path:     strings.TrimSuffix(e.Error(), ": "+message),
+			Message:  message,
+			Severity: findings.SeverityError,
+			Rule:     ruleSchema,
+		}
+		if position, found := lo.Find(e.InputPositions(), func(p token.Pos) bool { return p.Filename() == cueYamlInput }); found {
+			// CUE counts the columns of YAML input from the character before the value
+			finding.Line, finding.Column = position.Line(), position.Column()-1
+		} else if node := nodeAtPath(root, e.Path()); node != nil {
+			finding.Line, finding.Column = node.Line, node.Column
+		}
+		return finding
+	})
+}
+
+// nodeAtPath finds the YAML node of a CUE path. The first element, the schema definition, is skipped.
+func nodeAtPath(root *yaml.Node, path []string) *yaml.Node {
+	node := root
+	for _, element := range lo.Drop(path, 1) {
+		if unquoted, err := strconv.Unquote(element); err == nil {
+			element = unquoted
+		}
+		switch node.Kind {
+		case yaml.MappingNode:
+			node = util.MappingValue(node, element)
+		case yaml.SequenceNode:
+			index, err := strconv.Atoi(element)
+			if err != nil || index >= len(node.Content) {
+				return nil
+			}
+			node = node.Content[index]
+		default:
+			return nil
+		}
+		if node.Kind == 0 {
+			return nil
+		}
+	}
+	return node
 }
 
 func LogValidationErrors(out io.Writer, validationFailures []string, confirmIsValid bool) error {
@@ -100,3 +286,37 @@ func LogValidationErrors(out io.Writer, validationFailures []string, confirmIsVa
 	}
 	return err
 }
+
+type FormattableValidationResult struct {
+	Findings     []findings.Finding `json:"findings" yaml:"findings"`
+	httpResponse *http.Response
+	err          error
+}
+
+func (r FormattableValidationResult) Get() interface{} {
+	return r.Findings
+}
+
+func (r FormattableValidationResult) GetHttpResponse() *http.Response {
+	return r.httpResponse
+}
+
+func (r FormattableValidationResult) GetFetchError() error {
+	return r.err
+}
+
+func (r FormattableValidationResult) SarifLog() any {
+	return findings.NewSarifLog("armory", version.Version, r.Findings)
+}
+
+func (r FormattableValidationResult) String() string {
+	if len(r.Findings) == 0 {
+		return "YAML is valid."
+	}
+	header := "YAML is valid. See the following warnings:"
+	if findings.HasErrors(r.Findings) {
+		header = "YAML is NOT valid. See the following errors:"
+	}
+	descriptions := lo.Map(r.Findings, func(f findings.Finding, _ int) string { return f.String() })
+	return header + "\n\n" + strings.Join(descriptions, "\n\n")
+}
@@ -2,12 +2,18 @@ package validate
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"github.com/armory/armory-cli/pkg/config"
+	deployment "github.com/armory/armory-cli/pkg/deploy"
+	"github.com/armory/armory-cli/pkg/findings"
 	"github.com/armory/armory-cli/pkg/util"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"io"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -41,10 +47,13 @@ func getTestConfig(output string) *config.Configuration {
 }
 
 func TestDeployValidate(t *testing.T) {
+	// the findings are written as annotations in GitHub Actions
+	t.Setenv("GITHUB_ACTIONS", "")
 	cases := []struct {
 		testName   string
 		deployYaml string
 		output     string
+		invalid    bool
 	}{
 		{
 			testName:   "valid yaml should pass",
@@ -56,15 +65,39 @@ func TestDeployValidate(t *testing.T) {
 			deployYaml: invalidDeployYamlStr,
 			output: `YAML is NOT valid. See the following errors:
 
-#PipelineRequest.targets.dev_1.strategy: 1 errors in empty disjunction:
-
-#PipelineRequest.targets.dev_1.strategy: conflicting values "strategy1" and "strategy0"
+#PipelineRequest.targets.dev_1.strategy: line 9, column 15: 1 errors in empty disjunction:
 
+#PipelineRequest.targets.dev_1.strategy: line 9, column 15: conflicting values "strategy1" and "strategy0"
 `,
+			invalid: true,
 		},
 		{
-			testName:   "invalid lambda yaml should pass",
+			testName:   "invalid lambda yaml should fail",
 			deployYaml: invalidLambdaDeployYamlStr,
+			output: `YAML is NOT valid. See the following errors:
+
+#LambdaPipelineRequest.targets.firstTarget: line 12, column 5: field not allowed: oops
+`,
+			invalid: true,
+		},
+		{
+			testName:   "valid lambda yaml should pass",
+			deployYaml: validLambdaDeployYamlStr,
+			output:     "YAML is valid.\n",
+		},
+		{
+			testName:   "kustomize manifest should pass",
+			deployYaml: strings.Replace(validDeployYamlStr, "- path: deployment.yaml", "- kustomize: overlays/dev\n    targets: [dev_1, dev_2]", 1),
+			output:     "YAML is valid.\n",
+		},
+		{
+			testName:   "helm manifest should pass",
+			deployYaml: strings.Replace(validDeployYamlStr, "- path: deployment.yaml", "- helm:\n      chart: charts/app\n      valuesFiles: [values.yaml]\n      set:\n        replicaCount: 2", 1),
+			output:     "YAML is valid.\n",
+		},
+		{
+			testName:   "helm manifest with list and map values should pass",
+			deployYaml: strings.Replace(validDeployYamlStr, "- path: deployment.yaml", "- helm:\n      chart: charts/app\n      set:\n        hosts: [app.example.com]\n        resources:\n          cpu: 100m", 1),
 			output:     "YAML is valid.\n",
 		},
 		{
@@ -74,6 +107,14 @@ func TestDeployValidate(t *testing.T) {
 		},
 	}
 
+	// manifest paths are checked against the workspace
+	workspace := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(workspace, "overlays", "dev"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(workspace, "deployment.yaml"), []byte("kind: Deployment\nmetadata:\n  name: app\n"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(workspace, "overlays", "dev", "kustomization.yaml"), []byte("resources: [deployment.yaml]\n"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(workspace, "overlays", "dev", "deployment.yaml"), []byte("kind: Deployment\nmetadata:\n  name: app\n"), 0o644))
+	t.Setenv("GITHUB_WORKSPACE", workspace)
+
 	for _, c := range cases {
 		t.Run(fmt.Sprintf("test-%s", c.testName), func(t *testing.T) {
 			tempFile := util.TempAppFile("", "deploy.yaml", c.deployYaml)
@@ -81,9 +122,11 @@ func TestDeployValidate(t *testing.T) {
 				t.Fatal("TestDeployValidateSuccess failed with: Could not create temp app file.")
 			}
 			outWriter := bytes.NewBufferString("")
-			cmd := getValidateCmdWithFileName(outWriter, tempFile.Name(), "yaml")
+			cmd := getValidateCmdWithFileName(outWriter, tempFile.Name(), "text")
 			err := cmd.Execute()
-			if err != nil {
+			if c.invalid {
+				assert.ErrorIs(t, err, ErrInvalidDeploymentFile)
+			} else if err != nil {
 				t.Fatalf("TestDeployValidateSuccess failed with: %s", err)
 			}
 			assert.Equal(t, c.output, outWriter.String())
@@ -169,6 +212,56 @@ providerOptions:
       runtime: nodejs18.x
 `
 
+const validLambdaDeployYamlStr = `
+version: v1
+kind: lambda
+application: first-lambda-app
+targets:
+  staging:
+    account: staging
+    deployAsIamRole: "<some-deployment-role-arn>"
+    region: us-west-2
+    strategy: canary
+  prod:
+    account: prod
+    deployAsIamRole: "<some-deployment-role-arn>"
+    region: us-east-1
+    strategy: canary
+    constraints:
+      dependsOn: ["staging"]
+      beforeDeployment:
+        - pause:
+            untilApproved: true
+artifacts:
+  - path: "s3://<fill-me-in>/node/v0.0.1.zip"
+    functionName: hello-lambda
+    type: zipFile
+providerOptions:
+  lambda:
+    - name: hello-lambda
+      target: staging
+      runAsIamRole: "<some-lambda-role-arn>"
+      handler: index.handler
+      runtime: nodejs18.x
+    - name: hello-lambda
+      target: prod
+      runAsIamRole: "<some-lambda-role-arn>"
+      handler: index.handler
+      runtime: nodejs18.x
+      memorySize: 256
+strategies:
+  canary:
+    canary:
+      steps:
+        - setWeight:
+            weight: 50
+        - pause:
+            duration: 1
+            unit: MINUTES
+        - setWeight:
+            weight: 100
+`
+
 const rnaWebhookWithImplicitAgent = `
 version: apps/v1
 kind: kubernetes
@@ -197,3 +290,78 @@ webhooks:
       networkMode: remoteNetworkAgent
       disableCallback: true
 `
+
+func TestDeployValidateStructuredOutput(t *testing.T) {
+	t.Setenv("GITHUB_ACTIONS", "")
+	tempFile := util.TempAppFile("", "deploy.yaml", invalidLambdaDeployYamlStr)
+	if tempFile == nil {
+		t.Fatal("TestDeployValidateStructuredOutput failed with: Could not create temp app file.")
+	}
+
+	outWriter := bytes.NewBufferString("")
+	err := getValidateCmdWithFileName(outWriter, tempFile.Name(), "json").Execute()
+	assert.ErrorIs(t, err, ErrInvalidDeploymentFile)
+	var found []findings.Finding
+	assert.NoError(t, json.Unmarshal(outWriter.Bytes(), &found))
+	assert.Equal(t, []findings.Finding{{
+		File:     tempFile.Name(),
+		Path:     "#LambdaPipelineRequest.targets.firstTarget",
+		Line:     12,
+		Column:   5,
+		Message:  "field not allowed: oops",
+		Severity: findings.SeverityError,
+		Rule:     ruleSchema,
+	}}, found)
+
+	outWriter = bytes.NewBufferString("")
+	err = getValidateCmdWithFileName(outWriter, tempFile.Name(), "sarif").Execute()
+	assert.ErrorIs(t, err, ErrInvalidDeploymentFile)
+	var sarif findings.SarifLog
+	assert.NoError(t, json.Unmarshal(outWriter.Bytes(), &sarif))
+	assert.Equal(t, "2.1.0", sarif.Version)
+	assert.Contains(t, outWriter.String(), `"startLine": 12`)
+	assert.Contains(t, outWriter.String(), `"ruleId": "schema"`)
+
+	t.Setenv("GITHUB_ACTIONS", "true")
+	outWriter = bytes.NewBufferString("")
+	err = getValidateCmdWithFileName(outWriter, tempFile.Name(), "text").Execute()
+	assert.ErrorIs(t, err, ErrInvalidDeploymentFile)
+	assert.Equal(t, fmt.Sprintf("::error file=%s,line=12,col=5,title=#LambdaPipelineRequest.targets.firstTarget::field not allowed: oops\n", tempFile.Name()), outWriter.String())
+}
+
+func TestDeployValidateWithValues(t *testing.T) {
+	t.Setenv("GITHUB_ACTIONS", "")
+	workspace := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(workspace, "manifests"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(workspace, "manifests", "app.yaml"), []byte("kind: Deployment\nmetadata:\n  name: app\n---\nkind: Service\nmetadata:\n  name: ${service}\n"), 0o644))
+	t.Setenv("GITHUB_WORKSPACE", workspace)
+	tempFile := util.TempAppFile("", "deploy.yaml", strings.Replace(templatedManifestYamlStr, "application: potato-facts", "application: ${application}", 1))
+	if tempFile == nil {
+		t.Fatal("TestDeployValidateWithValues failed with: Could not create temp app file.")
+	}
+
+	outWriter := bytes.NewBufferString("")
+	err := getValidateCmdWithFileName(outWriter, tempFile.Name(), "text", "--set", "service=potato-facts-preview", "--set", "application=potato-facts").Execute()
+	assert.NoError(t, err)
+	assert.Equal(t, "YAML is valid.\n", outWriter.String())
+
+	err = getValidateCmdWithFileName(io.Discard, tempFile.Name(), "text", "--set", "service=potato-facts-preview", "--strict").Execute()
+	assert.ErrorIs(t, err, deployment.ErrUnresolvedValues)
+}
+
+func TestValidateKindSelectsSchema(t *testing.T) {
+	t.Setenv("GITHUB_ACTIONS", "")
+	tempFile := util.TempAppFile("", "deploy.yaml", validLambdaDeployYamlStr)
+	if tempFile == nil {
+		t.Fatal("TestValidateKindSelectsSchema failed with: Could not create temp app file.")
+	}
+
+	outWriter := bytes.NewBufferString("")
+	assert.NoError(t, getValidateCmdWithFileName(outWriter, tempFile.Name(), "text", "--kind", "lambda").Execute())
+	assert.Equal(t, "YAML is valid.\n", outWriter.String())
+
+	outWriter = bytes.NewBufferString("")
+	err := getValidateCmdWithFileName(outWriter, tempFile.Name(), "text", "--kind", "kubernetes").Execute()
+	assert.ErrorIs(t, err, ErrInvalidDeploymentFile)
+	assert.Contains(t, outWriter.String(), "YAML is NOT valid.")
+}
@@ -11,6 +11,8 @@ const (
 	Error ExitCode = 1
 	// Conflict exit code for when a command fails due to a conflict, ex: a deployment is already in progress
 	Conflict ExitCode = 3
+	// PolicyViolation exit code for when a deployment is not started because it violates the policies it was checked against
+	PolicyViolation ExitCode = 4
 
 	// The following exit codes are reserved and not to be used by the CLI
 	_ ExitCode = 2
@@ -6,6 +6,7 @@ import (
 	"github.com/armory/armory-cli/internal/clierr"
 	"github.com/armory/armory-cli/internal/clierr/exitcodes"
 	"github.com/machinebox/graphql"
+	"time"
 )
 
 type (
@@ -15,6 +16,20 @@ type (
 		BlockedByPipeline  *Pipeline   `json:"blockedByPipeline"`
 		ReplacedByPipeline *Pipeline   `json:"replacedByPipeline"`
 		Application        Application `json:"application"`
+		StartedAt          *time.Time  `json:"startedAt,omitempty"`
+		CompletedAt        *time.Time  `json:"completedAt,omitempty"`
+		InitiatedBy        string      `json:"initiatedBy,omitempty"`
+	}
+
+	// ListPipelinesFilter narrows down the pipelines returned by ListPipelines. Zero values are not used to filter.
+	ListPipelinesFilter struct {
+		Application   string
+		Statuses      []string
+		StartedAfter  *time.Time
+		StartedBefore *time.Time
+		InitiatedBy   string
+		Limit         int
+		Offset        int
 	}
 
 	Application struct {
@@ -66,3 +81,70 @@ func (c *Client) GetPipeline(ctx context.Context, pipelineID string) (*Pipeline,
 
 	return pipelineByIDResponse.PipelineByID, nil
 }
+
+const listPipelinesQuery = `
+  query ($where: PipelinesBoolExp!, $limit: Int, $offset: Int) {
+    pipelines(where: $where, orderBy: {startedAt: DESC}, limit: $limit, offset: $offset) {
+      id
+      status
+      startedAt
+      completedAt
+      initiatedBy
+      application {
+        name
+      }
+    }
+  }
+`
+
+// ListPipelines returns the pipelines matching the filter, most recently started first.
+func (c *Client) ListPipelines(ctx context.Context, filter ListPipelinesFilter) ([]Pipeline, error) {
+	request := graphql.NewRequest(listPipelinesQuery)
+	request.Var("where", filter.where())
+	if filter.Limit > 0 {
+		request.Var("limit", filter.Limit)
+	}
+	if filter.Offset > 0 {
+		request.Var("offset", filter.Offset)
+	}
+
+	requestID := c.newRequestID()
+
+	var pipelinesResponse struct {
+		Pipelines []Pipeline `json:"pipelines"`
+	}
+	if err := c.doGraphQLRequest(ctx, requestID, request, &pipelinesResponse); err != nil {
+		return nil, errors.Join(clierr.NewError(
+			"Could not list deployments",
+			requestID,
+			err,
+			exitcodes.Error,
+		), err)
+	}
+
+	return pipelinesResponse.Pipelines, nil
+}
+
+func (f ListPipelinesFilter) where() map[string]any {
+	where := map[string]any{}
+	if f.Application != "" {
+		where["application"] = map[string]any{"name": map[string]any{"_eq": f.Application}}
+	}
+	if len(f.Statuses) > 0 {
+		where["status"] = map[string]any{"_in": f.Statuses}
+	}
+	startedAt := map[string]any{}
+	if f.StartedAfter != nil {
+		startedAt["_gte"] = f.StartedAfter.Format(time.RFC3339)
+	}
+	if f.StartedBefore != nil {
+		startedAt["_lte"] = f.StartedBefore.Format(time.RFC3339)
+	}
+	if len(startedAt) > 0 {
+		where["startedAt"] = startedAt
+	}
+	if f.InitiatedBy != "" {
+		where["initiatedBy"] = map[string]any{"_eq": f.InitiatedBy}
+	}
+	return where
+}
@@ -0,0 +1,67 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/armory/armory-cli/pkg/config"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestListPipelines(t *testing.T) {
+	ctx := context.Background()
+	startedAfter := time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		var body struct {
+			Variables map[string]any `json:"variables"`
+		}
+		assert.NoError(t, json.NewDecoder(request.Body).Decode(&body))
+		assert.Equal(t, map[string]any{
+			"application": map[string]any{"name": map[string]any{"_eq": "my-app"}},
+			"status":      map[string]any{"_in": []any{"RUNNING", "FAILED"}},
+			"startedAt":   map[string]any{"_gte": "2023-04-01T00:00:00Z"},
+		}, body.Variables["where"])
+		assert.Equal(t, float64(10), body.Variables["limit"])
+		assert.Equal(t, float64(20), body.Variables["offset"])
+
+		assert.NoError(t, json.NewEncoder(writer).Encode(map[string]any{
+			"data": map[string]any{
+				"pipelines": []map[string]any{{
+					"id":          "pipeline-id",
+					"status":      "RUNNING",
+					"startedAt":   "2023-04-02T10:00:00Z",
+					"initiatedBy": "someone@armory.io",
+					"application": map[string]any{"name": "my-app"},
+				}},
+			},
+		}))
+	}))
+
+	client := NewClient(config.New(&config.Input{
+		AccessToken:  lo.ToPtr("access-token"),
+		ApiAddr:      lo.ToPtr(server.URL),
+		ClientId:     lo.ToPtr(""),
+		ClientSecret: lo.ToPtr(""),
+	}))
+
+	pipelines, err := client.ListPipelines(ctx, ListPipelinesFilter{
+		Application:  "my-app",
+		Statuses:     []string{"RUNNING", "FAILED"},
+		StartedAfter: &startedAfter,
+		Limit:        10,
+		Offset:       20,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []Pipeline{{
+		ID:          "pipeline-id",
+		Status:      "RUNNING",
+		StartedAt:   lo.ToPtr(time.Date(2023, 4, 2, 10, 0, 0, 0, time.UTC)),
+		InitiatedBy: "someone@armory.io",
+		Application: Application{Name: "my-app"},
+	}}, pipelines)
+}
@@ -184,8 +184,12 @@ func (c *Configuration) GetOutputType() output.Type {
 		oType = output.Yaml
 	case "json":
 		oType = output.Json
+	case "csv":
+		oType = output.Csv
+	case "sarif":
+		oType = output.Sarif
 	default:
-		log.Fatalf("the output type is invalid. Do not specify parameter to get plain text output. Available options: [json, yaml, text]")
+		log.Fatalf("the output type is invalid. Do not specify parameter to get plain text output. Available options: [json, yaml, text, csv, sarif]")
 	}
 	return oType
 }
@@ -235,6 +235,46 @@ func (c *ConfigClient) CreateEnvironment(ctx context.Context, request configClie
 	return &environment, resp, nil
 }
 
+func (c *ConfigClient) UpdateEnvironment(ctx context.Context, request configClient.UpdateEnvironmentRequest) (*http.Response, error) {
+	reqBytes, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	req, err := c.ArmoryCloudClient.SimpleRequest(ctx, http.MethodPut, fmt.Sprintf("/environments/%s", request.ID), bytes.NewReader(reqBytes))
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.ArmoryCloudClient.Http.Do(req)
+	if err != nil {
+		return resp, err
+	}
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return resp, &ConfigError{response: resp}
+	}
+
+	return resp, nil
+}
+
+func (c *ConfigClient) DeleteEnvironment(ctx context.Context, request configClient.DeleteEnvironmentRequest) (*http.Response, error) {
+	req, err := c.ArmoryCloudClient.SimpleRequest(ctx, http.MethodDelete, fmt.Sprintf("/environments/%s", request.ID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.ArmoryCloudClient.Http.Do(req)
+	if err != nil {
+		return resp, err
+	}
+
+	if resp.StatusCode != http.StatusNoContent {
+		return resp, &ConfigError{response: resp}
+	}
+
+	return resp, nil
+}
+
 func (c *ConfigClient) Agents() AgentClient {
 	return newAgents(c)
 }
@@ -35,3 +35,16 @@ func CreateEnvironmentRequest(environment string) configClient.CreateEnvironment
 		Name: environment,
 	}
 }
+
+func UpdateEnvironmentRequest(id, name string) configClient.UpdateEnvironmentRequest {
+	return configClient.UpdateEnvironmentRequest{
+		ID:   id,
+		Name: name,
+	}
+}
+
+func DeleteEnvironmentRequest(id string) configClient.DeleteEnvironmentRequest {
+	return configClient.DeleteEnvironmentRequest{
+		ID: id,
+	}
+}
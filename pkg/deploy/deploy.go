@@ -7,9 +7,9 @@ import (
 	"fmt"
 	"github.com/armory/armory-cli/internal/clierr"
 	"github.com/armory/armory-cli/internal/clierr/exitcodes"
-	"github.com/samber/lo"
 	"io"
 	"net/http"
+	"net/url"
 
 	"github.com/armory-io/deploy-engine/pkg/api"
 	"github.com/armory/armory-cli/pkg/armoryCloud"
@@ -20,11 +20,22 @@ type (
 	Client struct {
 		ArmoryCloudClient *armoryCloud.Client
 	}
+
+	rollbackRequest struct {
+		Targets []string `json:"targets,omitempty"`
+	}
+
+	// StepAction is an operator action that can be performed on a paused or awaiting approval pipeline step.
+	StepAction string
 )
 
 const (
 	mediaTypeKubernetesPipelineV2 = "application/vnd.start.kubernetes.pipeline.v2+json"
 	kubernetesKind                = "kubernetes"
+
+	StepActionApprove   StepAction = "approve"
+	StepActionReject    StepAction = "reject"
+	StepActionSkipPause StepAction = "skip"
 )
 
 func NewClient(configuration *config.Configuration) *Client {
@@ -59,6 +70,34 @@ func (c *Client) PipelineStatus(ctx context.Context, pipelineID string) (*api.Pi
 	return &pipeline, resp, nil
 }
 
+// PipelineConfig returns the configuration a pipeline was started with, including the contents of its manifests.
+func (c *Client) PipelineConfig(ctx context.Context, pipelineID string) (map[string]any, *http.Response, error) {
+	req, err := c.ArmoryCloudClient.SimpleRequest(ctx, http.MethodGet, fmt.Sprintf("/pipelines/%s/config", url.PathEscape(pipelineID)), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resp, err := c.ArmoryCloudClient.Http.Do(req)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, resp, clierr.NewAPIError("Failed to fetch deployment configuration", resp.StatusCode, bodyBytes, exitcodes.Error)
+	}
+
+	var pipelineConfig map[string]any
+	if err := json.Unmarshal(bodyBytes, &pipelineConfig); err != nil {
+		return nil, resp, err
+	}
+	return pipelineConfig, resp, nil
+}
+
 func (c *Client) DeploymentStatus(ctx context.Context, deploymentID string) (*api.DeploymentStatusResponse, *http.Response, error) {
 	req, err := c.ArmoryCloudClient.SimpleRequest(ctx, http.MethodGet, fmt.Sprintf("/deployments/%s", deploymentID), nil)
 	if err != nil {
@@ -87,26 +126,21 @@ func (c *Client) DeploymentStatus(ctx context.Context, deploymentID string) (*ap
 }
 
 func (c *Client) StartPipeline(ctx context.Context, options StartPipelineOptions) (*api.StartPipelineResponse, *http.Response, error) {
-	pipelinePath, headers, err := getPipelinePathAndHeaders(options)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	request, err := convertPipelineOptionsToAPIRequest(options)
+	request, err := NewPipelineRequest(options)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	reqBytes, err := json.Marshal(request)
+	reqBytes, err := json.Marshal(request.Body)
 
 	if err != nil {
 		return nil, nil, err
 	}
 	requestOptions := []armoryCloud.RequestOption{
-		armoryCloud.WithMethod(http.MethodPost),
-		armoryCloud.WithPath(pipelinePath),
+		armoryCloud.WithMethod(request.Method),
+		armoryCloud.WithPath(request.Path),
 	}
-	for key, val := range lo.Assign(headers, options.Headers) {
+	for key, val := range request.Headers {
 		requestOptions = append(requestOptions, armoryCloud.WithHeader(key, val))
 	}
 	requestOptions = append(requestOptions, armoryCloud.WithBody(bytes.NewReader(reqBytes)))
@@ -145,6 +179,71 @@ func (c *Client) StartPipeline(ctx context.Context, options StartPipelineOptions
 	return &startResponse, resp, nil
 }
 
+// Approve approves the manual judgment the given pipeline step is waiting on.
+func (c *Client) Approve(ctx context.Context, pipelineID, stepRef string) (*http.Response, error) {
+	return c.performStepAction(ctx, pipelineID, stepRef, StepActionApprove)
+}
+
+// Reject rejects the manual judgment the given pipeline step is waiting on, which fails the step.
+func (c *Client) Reject(ctx context.Context, pipelineID, stepRef string) (*http.Response, error) {
+	return c.performStepAction(ctx, pipelineID, stepRef, StepActionReject)
+}
+
+// SkipPause ends a timed pause early so the pipeline can continue.
+func (c *Client) SkipPause(ctx context.Context, pipelineID, stepRef string) (*http.Response, error) {
+	return c.performStepAction(ctx, pipelineID, stepRef, StepActionSkipPause)
+}
+
+func (c *Client) performStepAction(ctx context.Context, pipelineID, stepRef string, action StepAction) (*http.Response, error) {
+	path := fmt.Sprintf("/pipelines/%s/steps/%s/%s", url.PathEscape(pipelineID), url.PathEscape(stepRef), action)
+	return c.postPipelineAction(ctx, path, nil, fmt.Sprintf("Failed to %s deployment step", action))
+}
+
+// CancelPipeline stops a running pipeline. Deployments that are in progress are rolled back by the server.
+func (c *Client) CancelPipeline(ctx context.Context, pipelineID string) (*http.Response, error) {
+	path := fmt.Sprintf("/pipelines/%s/cancel", url.PathEscape(pipelineID))
+	return c.postPipelineAction(ctx, path, nil, "Failed to cancel deployment")
+}
+
+// RollbackPipeline rolls back the given targets of a pipeline. When no targets are provided every target is rolled back.
+func (c *Client) RollbackPipeline(ctx context.Context, pipelineID string, targets []string) (*http.Response, error) {
+	reqBytes, err := json.Marshal(rollbackRequest{Targets: targets})
+	if err != nil {
+		return nil, err
+	}
+	path := fmt.Sprintf("/pipelines/%s/rollback", url.PathEscape(pipelineID))
+	return c.postPipelineAction(ctx, path, bytes.NewReader(reqBytes), "Failed to roll back deployment")
+}
+
+func (c *Client) postPipelineAction(ctx context.Context, path string, body io.Reader, failureMsg string) (*http.Response, error) {
+	req, err := c.ArmoryCloudClient.SimpleRequest(ctx, http.MethodPost, path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.ArmoryCloudClient.Http.Do(req)
+	if err != nil {
+		if resp != nil {
+			return resp, err
+		}
+		return nil, &networkError{}
+	}
+
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp, err
+	}
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusNoContent {
+		exitCode := exitcodes.Error
+		if resp.StatusCode == http.StatusConflict {
+			exitCode = exitcodes.Conflict
+		}
+		return resp, clierr.NewAPIError(failureMsg, resp.StatusCode, bodyBytes, exitCode)
+	}
+	return resp, nil
+}
+
 func (c *Client) GetArmoryCloudClient() *armoryCloud.Client {
 	return c.ArmoryCloudClient
 }
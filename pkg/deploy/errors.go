@@ -8,4 +8,16 @@ var (
 	ErrManifestFileRead         = errors.New("error trying to read manifest file")
 	ErrManifestFileNameRead     = errors.New("error trying to read manifest file name")
 	ErrNoApplicationNameDefined = errors.New("application name must be defined in deployment file or by application opt")
+	ErrKustomizeBuild           = errors.New("error trying to build kustomization")
+	ErrHelmNotFound             = errors.New("helm manifests require the helm binary to be installed and on the PATH")
+	ErrHelmTemplate             = errors.New("error trying to render helm chart")
+	ErrHelmSetJSONUnsupported   = errors.New("helm set values require helm 3.10 or later, which supports --set-json")
+	ErrValuesFileRead           = errors.New("error trying to read values file")
+	ErrInvalidValueOverride     = errors.New("values must be set as key=value")
+	ErrUnresolvedValues         = errors.New("unresolved values")
+	ErrInvalidImageOverride     = errors.New("images must be set as container=image")
+	ErrImageOverride            = errors.New("error trying to override images in manifest file")
+	ErrUnusedImageOverride      = errors.New("no container found for image override")
+	ErrSimulationConfig         = errors.New("error trying to read the targets and strategies of the deployment")
+	ErrDependencyCycle          = errors.New("targets depend on each other")
 )
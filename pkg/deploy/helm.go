@@ -0,0 +1,107 @@
+package deploy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	errorUtils "github.com/armory/armory-cli/pkg/errors"
+	"github.com/samber/lo"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+type helmChart struct {
+	// Chart is the path to a local chart directory or packaged chart.
+	Chart string `yaml:"chart"`
+	// ReleaseName defaults to the application name.
+	ReleaseName string   `yaml:"releaseName"`
+	ValuesFiles []string `yaml:"valuesFiles"`
+	// Set values are passed with --set-json, which needs helm 3.10 or later.
+	Set map[string]any `yaml:"set"`
+}
+
+// runHelm runs the helm binary with the given arguments and returns its standard output. It is a variable so tests
+// can render charts without helm being installed.
+var runHelm = func(args ...string) ([]byte, error) {
+	if _, err := exec.LookPath("helm"); err != nil {
+		return nil, ErrHelmNotFound
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("helm", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// getHelmManifestFiles renders every helm manifest once per target it applies to, using the target's namespace. Charts
+// are rendered with `helm template`, so no cluster access is needed, and the values are substituted into the output.
+func getHelmManifestFiles(structured *structuredConfig, application string, values *Values) (map[string][]string, error) {
+	files := map[string][]string{}
+	for _, m := range structured.Manifests {
+		if m.Helm == nil {
+			continue
+		}
+		for _, target := range structured.manifestTargets(m) {
+			rendered, err := renderHelmChart(*m.Helm, lo.Ternary(m.Helm.ReleaseName != "", m.Helm.ReleaseName, application), structured.Targets[target].Namespace)
+			if err != nil {
+				return nil, err
+			}
+			key := helmManifestKey(m.Helm.Chart, target)
+			content, err := values.Render(key, []byte(rendered))
+			if err != nil {
+				return nil, err
+			}
+			files[key] = []string{string(content)}
+		}
+	}
+	return files, nil
+}
+
+func renderHelmChart(chart helmChart, releaseName, namespace string) (string, error) {
+	args := []string{"template", releaseName, WorkspacePath(chart.Chart)}
+	if namespace != "" {
+		args = append(args, "--namespace", namespace)
+	}
+	for _, valuesFile := range chart.ValuesFiles {
+		args = append(args, "--values", WorkspacePath(valuesFile))
+	}
+	// values are passed as JSON so that strings with commas, lists and maps reach the chart as they are in the file
+	keys := lo.Keys(chart.Set)
+	sort.Strings(keys)
+	for _, key := range keys {
+		value, err := json.Marshal(chart.Set[key])
+		if err != nil {
+			return "", errorUtils.NewWrappedErrorWithDynamicContext(ErrHelmTemplate, err, " "+chart.Chart)
+		}
+		args = append(args, "--set-json", fmt.Sprintf("%s=%s", key, value))
+	}
+
+	rendered, err := runHelm(args...)
+	if err != nil && len(keys) > 0 && strings.Contains(err.Error(), "unknown flag: --set-json") {
+		return "", errorUtils.NewWrappedErrorWithDynamicContext(ErrHelmSetJSONUnsupported, err, " "+chart.Chart)
+	}
+	if err != nil {
+		return "", errorUtils.NewWrappedErrorWithDynamicContext(ErrHelmTemplate, err, " "+chart.Chart)
+	}
+	return string(rendered), nil
+}
+
+// helmManifestKey is the key of the rendered chart in the files map. Charts are rendered per target, so the target is
+// part of the key.
+func helmManifestKey(chart, target string) string {
+	return fmt.Sprintf("%s#%s", chart, target)
+}
+
+// manifestTargets returns the targets a manifest applies to, which is every target when none are listed.
+func (s *structuredConfig) manifestTargets(m manifest) []string {
+	if len(m.Targets) > 0 {
+		return m.Targets
+	}
+	targets := lo.Keys(s.Targets)
+	sort.Strings(targets)
+	return targets
+}
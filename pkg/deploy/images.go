@@ -0,0 +1,232 @@
+package deploy
+
+import (
+	"errors"
+	"fmt"
+	errorUtils "github.com/armory/armory-cli/pkg/errors"
+	"github.com/armory/armory-cli/pkg/util"
+	"github.com/samber/lo"
+	"gopkg.in/yaml.v3"
+	"io"
+	"sort"
+	"strings"
+)
+
+type (
+	// ImageOverrides replaces the image of containers, matched by name, in the Kubernetes manifests of a deployment.
+	// Only the image values are rewritten, the rest of each manifest is sent exactly as it was read.
+	ImageOverrides struct {
+		images  map[string]string
+		changes []ImageChange
+	}
+
+	// ImageChange describes a container image that was replaced by an ImageOverrides.
+	ImageChange struct {
+		File      string `json:"file" yaml:"file"`
+		Kind      string `json:"kind" yaml:"kind"`
+		Name      string `json:"name" yaml:"name"`
+		Container string `json:"container" yaml:"container"`
+		From      string `json:"from" yaml:"from"`
+		To        string `json:"to" yaml:"to"`
+	}
+
+	imageEdit struct {
+		node   *yaml.Node
+		change ImageChange
+	}
+)
+
+var containerListKeys = []string{"containers", "initContainers"}
+
+// NewImageOverrides parses overrides in the form container=image.
+func NewImageOverrides(overrides []string) (*ImageOverrides, error) {
+	images := map[string]string{}
+	for _, override := range overrides {
+		container, image, found := strings.Cut(override, "=")
+		if !found || container == "" || image == "" {
+			return nil, errorUtils.NewErrorWithDynamicContext(ErrInvalidImageOverride, ": "+override)
+		}
+		images[container] = image
+	}
+	return &ImageOverrides{images: images}, nil
+}
+
+// Changes returns every image replaced so far.
+func (o *ImageOverrides) Changes() []ImageChange {
+	return o.changes
+}
+
+// Unused returns the containers that were not found in any manifest.
+func (o *ImageOverrides) Unused() []string {
+	used := lo.Map(o.changes, func(change ImageChange, _ int) string { return change.Container })
+	unused, _ := lo.Difference(lo.Keys(o.images), used)
+	sort.Strings(unused)
+	return unused
+}
+
+// Apply replaces the images of matching containers in a file of one or more Kubernetes manifests.
+func (o *ImageOverrides) Apply(file, content string) (string, error) {
+	if o == nil || len(o.images) == 0 {
+		return content, nil
+	}
+
+	var edits []imageEdit
+	decoder := yaml.NewDecoder(strings.NewReader(content))
+	for {
+		var document yaml.Node
+		if err := decoder.Decode(&document); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return "", errorUtils.NewWrappedErrorWithDynamicContext(ErrImageOverride, err, " "+file)
+		}
+		if len(document.Content) == 0 {
+			continue
+		}
+		root := document.Content[0]
+		resource := ImageChange{
+			File: file,
+			Kind: util.MappingValue(root, "kind").Value,
+			Name: util.MappingValue(util.MappingValue(root, "metadata"), "name").Value,
+		}
+		edits = append(edits, o.findImageEdits(root, resource)...)
+	}
+	if len(edits) == 0 {
+		return content, nil
+	}
+
+	// replace from the end of the file so that earlier positions stay valid
+	sort.Slice(edits, func(i, j int) bool {
+		if edits[i].node.Line != edits[j].node.Line {
+			return edits[i].node.Line > edits[j].node.Line
+		}
+		return edits[i].node.Column > edits[j].node.Column
+	})
+	lines := strings.SplitAfter(content, "\n")
+	for _, edit := range edits {
+		lines[edit.node.Line-1] = replaceScalar(lines[edit.node.Line-1], edit.node, edit.change.To)
+	}
+	for i := len(edits) - 1; i >= 0; i-- {
+		o.changes = append(o.changes, edits[i].change)
+	}
+	return strings.Join(lines, ""), nil
+}
+
+func (o *ImageOverrides) findImageEdits(node *yaml.Node, resource ImageChange) []imageEdit {
+	var edits []imageEdit
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if lo.Contains(containerListKeys, key.Value) && value.Kind == yaml.SequenceNode {
+				for _, container := range value.Content {
+					name := util.MappingValue(container, "name").Value
+					image := util.MappingValue(container, "image")
+					override, ok := o.images[name]
+					if !ok || image.Kind != yaml.ScalarNode {
+						continue
+					}
+					change := resource
+					change.Container, change.From, change.To = name, image.Value, override
+					edits = append(edits, imageEdit{node: image, change: change})
+				}
+				continue
+			}
+			edits = append(edits, o.findImageEdits(value, resource)...)
+		}
+	}
+	if node.Kind == yaml.SequenceNode {
+		for _, item := range node.Content {
+			edits = append(edits, o.findImageEdits(item, resource)...)
+		}
+	}
+	return edits
+}
+
+// replaceScalar replaces the scalar that starts at the node's column in line, keeping its quoting style.
+func replaceScalar(line string, node *yaml.Node, value string) string {
+	start := node.Column - 1
+	length := len(node.Value)
+	quote := ""
+	switch node.Style {
+	case yaml.DoubleQuotedStyle:
+		quote = `"`
+	case yaml.SingleQuotedStyle:
+		quote = `'`
+	}
+	if quote != "" {
+		end := strings.Index(line[start+1:], quote)
+		length = end + 2
+	}
+	return fmt.Sprintf("%s%s%s%s%s", line[:start], quote, value, quote, line[start+length:])
+}
+
+// applyToRequest overrides images in the manifest files and inline manifests of a pipeline request.
+func (o *ImageOverrides) applyToRequest(request map[string]any) error {
+	if o == nil {
+		return nil
+	}
+	switch files := request[filesKey].(type) {
+	case map[string][]string:
+		paths := lo.Keys(files)
+		sort.Strings(paths)
+		for _, path := range paths {
+			for i, content := range files[path] {
+				rendered, err := o.Apply(path, content)
+				if err != nil {
+					return err
+				}
+				files[path][i] = rendered
+			}
+		}
+	case map[string]any:
+		paths := lo.Keys(files)
+		sort.Strings(paths)
+		for _, path := range paths {
+			list, ok := files[path].([]any)
+			if !ok {
+				continue
+			}
+			for i, content := range list {
+				if text, ok := content.(string); ok {
+					rendered, err := o.Apply(path, text)
+					if err != nil {
+						return err
+					}
+					list[i] = rendered
+				}
+			}
+		}
+	}
+
+	applyToInline := func(m map[string]any) error {
+		inline, ok := m[manifestInlineKey].(string)
+		if !ok {
+			return nil
+		}
+		rendered, err := o.Apply(manifestInlineKey, inline)
+		m[manifestInlineKey] = rendered
+		return err
+	}
+	switch manifests := request[manifestsKey].(type) {
+	case []map[string]any:
+		for _, m := range manifests {
+			if err := applyToInline(m); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, m := range manifests {
+			if asMap, ok := m.(map[string]any); ok {
+				if err := applyToInline(asMap); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	// a misspelled container name would otherwise silently deploy the old image
+	if unused := o.Unused(); len(unused) > 0 {
+		return errorUtils.NewErrorWithDynamicContext(ErrUnusedImageOverride, ": "+strings.Join(unused, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package deploy
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+const imagesTestManifests = `# the potato facts service
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: potato-facts
+spec:
+  template:
+    spec:
+      initContainers:
+        - name: migrate
+          image: 'armory/potato-migrations:1.0.0' # pinned
+      containers:
+        - name: potato-facts
+          image: "armory/potato-facts:1.0.0"
+          ports:
+            - containerPort: 9001
+        - name: sidecar
+          image: envoy:1.0
+---
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: potato-report
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+            - name: potato-facts
+              image: armory/potato-facts:1.0.0
+`
+
+func TestImageOverridesApply(t *testing.T) {
+	overrides, err := NewImageOverrides([]string{"potato-facts=armory/potato-facts:2.0.0", "migrate=armory/potato-migrations:2.0.0"})
+	assert.NoError(t, err)
+
+	rendered, err := overrides.Apply("manifests/potato.yaml", imagesTestManifests)
+	assert.NoError(t, err)
+	assert.Equal(t, `# the potato facts service
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: potato-facts
+spec:
+  template:
+    spec:
+      initContainers:
+        - name: migrate
+          image: 'armory/potato-migrations:2.0.0' # pinned
+      containers:
+        - name: potato-facts
+          image: "armory/potato-facts:2.0.0"
+          ports:
+            - containerPort: 9001
+        - name: sidecar
+          image: envoy:1.0
+---
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: potato-report
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+            - name: potato-facts
+              image: armory/potato-facts:2.0.0
+`, rendered)
+	assert.Equal(t, []ImageChange{
+		{File: "manifests/potato.yaml", Kind: "Deployment", Name: "potato-facts", Container: "migrate", From: "armory/potato-migrations:1.0.0", To: "armory/potato-migrations:2.0.0"},
+		{File: "manifests/potato.yaml", Kind: "Deployment", Name: "potato-facts", Container: "potato-facts", From: "armory/potato-facts:1.0.0", To: "armory/potato-facts:2.0.0"},
+		{File: "manifests/potato.yaml", Kind: "CronJob", Name: "potato-report", Container: "potato-facts", From: "armory/potato-facts:1.0.0", To: "armory/potato-facts:2.0.0"},
+	}, overrides.Changes())
+	assert.Empty(t, overrides.Unused())
+}
+
+func TestImageOverridesApplyToRequest(t *testing.T) {
+	cases := []struct {
+		name              string
+		overrides         []string
+		request           map[string]any
+		expectedFiles     any
+		expectErrContains string
+	}{
+		{
+			name:      "files read from disk",
+			overrides: []string{"sidecar=envoy:2.0"},
+			request: map[string]any{
+				filesKey: map[string][]string{"potato.yaml": {imagesTestManifests}},
+			},
+			expectedFiles: "          image: envoy:2.0\n",
+		},
+		{
+			name:      "files of a fetched pipeline configuration",
+			overrides: []string{"sidecar=envoy:2.0"},
+			request: map[string]any{
+				filesKey: map[string]any{"potato.yaml": []any{imagesTestManifests}},
+			},
+			expectedFiles: "          image: envoy:2.0\n",
+		},
+		{
+			name:      "containers that are not in any manifest",
+			overrides: []string{"sidecar=envoy:2.0", "potatoes=armory/potatoes:1.0.0"},
+			request: map[string]any{
+				filesKey: map[string][]string{"potato.yaml": {imagesTestManifests}},
+			},
+			expectErrContains: "potatoes",
+		},
+		{
+			name:              "invalid manifests",
+			overrides:         []string{"sidecar=envoy:2.0"},
+			request:           map[string]any{manifestsKey: []any{map[string]any{manifestInlineKey: "kind: [Deployment"}}},
+			expectErrContains: "inline",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			overrides, err := NewImageOverrides(c.overrides)
+			assert.NoError(t, err)
+
+			err = overrides.applyToRequest(c.request)
+			if c.expectErrContains != "" {
+				assert.ErrorContains(t, err, c.expectErrContains)
+				return
+			}
+			assert.NoError(t, err)
+			switch files := c.request[filesKey].(type) {
+			case map[string][]string:
+				assert.Contains(t, files["potato.yaml"][0], c.expectedFiles)
+			case map[string]any:
+				assert.Contains(t, files["potato.yaml"].([]any)[0], c.expectedFiles)
+			}
+		})
+	}
+}
+
+func TestNewImageOverridesInvalid(t *testing.T) {
+	_, err := NewImageOverrides([]string{"potato-facts"})
+	assert.ErrorIs(t, err, ErrInvalidImageOverride)
+}
@@ -0,0 +1,57 @@
+package deploy
+
+import (
+	errorUtils "github.com/armory/armory-cli/pkg/errors"
+	"os"
+	"path/filepath"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// kustomizationFileNames are the file names kustomize recognizes as the root of a kustomization.
+var kustomizationFileNames = []string{"kustomization.yaml", "kustomization.yml", "Kustomization"}
+
+// kustomizationDir returns the directory of the kustomization to build for the manifest, either set explicitly with
+// kustomize or detected from a path that contains a kustomization file. It is empty for any other manifest.
+func (m manifest) kustomizationDir() string {
+	if m.Kustomize != "" {
+		return m.Kustomize
+	}
+	if m.Path != "" && !IsURL(m.Path) && isKustomization(WorkspacePath(m.Path)) {
+		return m.Path
+	}
+	return ""
+}
+
+// isKustomization reports whether the path is a directory that contains a kustomization file.
+func isKustomization(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || !info.IsDir() {
+		return false
+	}
+	for _, name := range kustomizationFileNames {
+		if _, err := os.Stat(filepath.Join(path, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// renderKustomization builds the kustomization in the given directory, the same as `kustomize build <dir>` would, and
+// substitutes the values into the output.
+func renderKustomization(dir string, values *Values) (string, error) {
+	kustomizer := krusty.MakeKustomizer(krusty.MakeDefaultOptions())
+	resources, err := kustomizer.Run(filesys.MakeFsOnDisk(), dir)
+	if err != nil {
+		return "", errorUtils.NewWrappedErrorWithDynamicContext(ErrKustomizeBuild, err, " "+dir)
+	}
+	rendered, err := resources.AsYaml()
+	if err != nil {
+		return "", errorUtils.NewWrappedErrorWithDynamicContext(ErrKustomizeBuild, err, " "+dir)
+	}
+	rendered, err = values.Render(dir, rendered)
+	if err != nil {
+		return "", err
+	}
+	return string(rendered), nil
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/mitchellh/mapstructure"
 	"github.com/samber/lo"
 	"io/fs"
+	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -20,11 +21,28 @@ type (
 		SCMC                    de.SCM
 		Headers                 map[string]string
 		IsURL                   bool
+		// Values are substituted into the manifest files. The deployment file is expected to be rendered already.
+		Values *Values
+		// ImageOverrides replaces container images in the manifests once they have been read.
+		ImageOverrides *ImageOverrides
+		// Request is the request built beforehand by NewPipelineRequest, e.g. to check it against policies. It is sent
+		// as it is, so that the manifests are not read and rendered again.
+		Request *PipelineRequest
+	}
+
+	// PipelineRequest is the request sent by StartPipeline, with the manifests, context and source control
+	// information already merged into the body.
+	PipelineRequest struct {
+		Method  string            `json:"method" yaml:"method"`
+		Path    string            `json:"path" yaml:"path"`
+		Headers map[string]string `json:"headers" yaml:"headers"`
+		Body    map[string]any    `json:"body" yaml:"body"`
 	}
 
 	structuredConfig struct {
 		Kind             string            `yaml:"kind"`
 		Application      string            `yaml:"application"`
+		Targets          map[string]target `yaml:"targets"`
 		Manifests        []manifest        `yaml:"manifests"`
 		DeploymentConfig *deploymentConfig `yaml:"deploymentConfig"`
 	}
@@ -33,6 +51,14 @@ type (
 		Path    string   `yaml:"path"`
 		Targets []string `yaml:"targets"`
 		Inline  string   `yaml:"inline"`
+		// Kustomize is a directory containing a kustomization that is built before it is sent.
+		Kustomize string `yaml:"kustomize"`
+		// Helm is a chart that is rendered for each target before it is sent.
+		Helm *helmChart `yaml:"helm"`
+	}
+
+	target struct {
+		Namespace string `yaml:"namespace"`
 	}
 
 	deploymentConfig struct {
@@ -51,6 +77,12 @@ const (
 	filesKey              = "files"
 	contextKey            = "context"
 	scmcKey               = "sourceControl"
+	manifestsKey          = "manifests"
+	manifestPathKey       = "path"
+	manifestKustomizeKey  = "kustomize"
+	manifestHelmKey       = "helm"
+	manifestTargetsKey    = "targets"
+	manifestInlineKey     = "inline"
 	envVarGithubWorkspace = "GITHUB_WORKSPACE"
 
 	enqueueOne strategy = "enqueueOne"
@@ -62,9 +94,32 @@ func (s *StartPipelineOptions) structuredConfig() (*structuredConfig, error) {
 	return &structured, mapstructure.Decode(s.UnstructuredDeployment, &structured)
 }
 
+// NewPipelineRequest builds the request that starts a pipeline without sending it.
+func NewPipelineRequest(options StartPipelineOptions) (*PipelineRequest, error) {
+	if options.Request != nil {
+		return options.Request, nil
+	}
+	pipelinePath, headers, err := getPipelinePathAndHeaders(options)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := convertPipelineOptionsToAPIRequest(options)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PipelineRequest{
+		Method:  http.MethodPost,
+		Path:    pipelinePath,
+		Headers: lo.Assign(headers, options.Headers),
+		Body:    body,
+	}, nil
+}
+
 func convertPipelineOptionsToAPIRequest(options StartPipelineOptions) (map[string]any, error) {
 	if options.IsURL {
-		return options.UnstructuredDeployment, nil
+		return options.UnstructuredDeployment, options.ImageOverrides.applyToRequest(options.UnstructuredDeployment)
 	}
 	deployment := options.UnstructuredDeployment
 
@@ -82,13 +137,20 @@ func convertPipelineOptionsToAPIRequest(options StartPipelineOptions) (map[strin
 		}
 	}
 
-	manifestFiles, err := getManifestFiles(structured.Manifests)
+	manifestFiles, err := getManifestFiles(structured.Manifests, options.Values)
+	if err != nil {
+		return nil, err
+	}
+	helmFiles, err := getHelmManifestFiles(structured, application, options.Values)
 	if err != nil {
 		return nil, err
 	}
 
 	deployment[applicationKey] = application
-	deployment[filesKey] = manifestFiles
+	deployment[filesKey] = lo.Assign(manifestFiles, helmFiles)
+	if manifests, ok := deployment[manifestsKey]; ok {
+		deployment[manifestsKey] = renderedManifestSources(manifests, structured)
+	}
 
 	context := map[string]any{}
 	if c, ok := deployment[contextKey].(map[string]any); ok {
@@ -101,12 +163,26 @@ func convertPipelineOptionsToAPIRequest(options StartPipelineOptions) (map[strin
 
 	deployment[scmcKey] = options.SCMC
 
+	if err := options.ImageOverrides.applyToRequest(deployment); err != nil {
+		return nil, err
+	}
 	return deployment, nil
 }
 
-func getManifestFiles(manifests []manifest) (map[string][]string, error) {
+func getManifestFiles(manifests []manifest, values *Values) (map[string][]string, error) {
 	allManifests := make(map[string][]string)
 	for _, m := range manifests {
+		if m.Helm != nil {
+			continue
+		}
+		if dir := m.kustomizationDir(); dir != "" {
+			rendered, err := renderKustomization(WorkspacePath(dir), values)
+			if err != nil {
+				return nil, err
+			}
+			allManifests[dir] = []string{rendered}
+			continue
+		}
 		if IsURL(m.Path) {
 			continue
 		}
@@ -114,7 +190,7 @@ func getManifestFiles(manifests []manifest) (map[string][]string, error) {
 		if err != nil {
 			return nil, err
 		}
-		files, err := getFiles(fileNames)
+		files, err := getFiles(fileNames, values)
 		if err != nil {
 			return nil, err
 		}
@@ -123,14 +199,71 @@ func getManifestFiles(manifests []manifest) (map[string][]string, error) {
 	return allManifests, nil
 }
 
+// ReadManifests returns the contents of the manifest files at a path of the deployment file, rendering it first when
+// it is a kustomization. The values are substituted into the manifest files or the rendered kustomization.
+func ReadManifests(path string, values *Values) ([]string, error) {
+	if dir := WorkspacePath(path); isKustomization(dir) {
+		rendered, err := renderKustomization(dir, values)
+		if err != nil {
+			return nil, err
+		}
+		return []string{rendered}, nil
+	}
+	fileNames, err := getFileNamesFromPath(path)
+	if err != nil {
+		return nil, err
+	}
+	return getFiles(fileNames, values)
+}
+
+// renderedManifestSources rewrites manifests that use a source the server does not know about, such as kustomize or
+// helm, into path manifests. The rendered content is sent in the files map under the same key. Helm manifests are
+// rendered per target, so they are split into one manifest per target.
+func renderedManifestSources(manifests any, structured *structuredConfig) any {
+	rewrite := func(m map[string]any, i int) []map[string]any {
+		if dir, ok := m[manifestKustomizeKey]; ok {
+			rendered := lo.OmitByKeys(m, []string{manifestKustomizeKey})
+			rendered[manifestPathKey] = dir
+			return []map[string]any{rendered}
+		}
+		if _, ok := m[manifestHelmKey]; ok && i < len(structured.Manifests) && structured.Manifests[i].Helm != nil {
+			helm := structured.Manifests[i]
+			return lo.Map(structured.manifestTargets(helm), func(target string, _ int) map[string]any {
+				rendered := lo.OmitByKeys(m, []string{manifestHelmKey})
+				rendered[manifestPathKey] = helmManifestKey(helm.Helm.Chart, target)
+				rendered[manifestTargetsKey] = []string{target}
+				return rendered
+			})
+		}
+		return []map[string]any{m}
+	}
+	switch typed := manifests.(type) {
+	case []map[string]any:
+		return lo.FlatMap(typed, rewrite)
+	case []any:
+		return lo.FlatMap(typed, func(m any, i int) []any {
+			if asMap, ok := m.(map[string]any); ok {
+				return lo.ToAnySlice(rewrite(asMap, i))
+			}
+			return []any{m}
+		})
+	}
+	return manifests
+}
+
+// WorkspacePath resolves a path from the deployment file against the GitHub workspace when running in GitHub Actions.
+func WorkspacePath(path string) string {
+	if gitWorkspace, present := os.LookupEnv(envVarGithubWorkspace); present {
+		return gitWorkspace + "/" + path
+	}
+	return path
+}
+
 func getFileNamesFromPath(path string) ([]string, error) {
 	var allFileNames []string
-	gitWorkspace, present := os.LookupEnv(envVarGithubWorkspace)
 
 	if path != "" {
-		if present {
-			path = gitWorkspace + "/" + path
-		}
+		path = WorkspacePath(path)
 		fileNames, err := getFileNames(path)
 		if err != nil {
 			return nil, errorUtils.NewWrappedError(ErrManifestFileNameRead, err)
@@ -140,13 +273,17 @@ func getFileNamesFromPath(path string) ([]string, error) {
 	return allFileNames, nil
 }
 
-func getFiles(dirFileNames []string) ([]string, error) {
+func getFiles(dirFileNames []string, values *Values) ([]string, error) {
 	var files []string
 	for _, fileName := range dirFileNames {
 		file, err := os.ReadFile(fileName)
 		if err != nil {
 			return nil, errorUtils.NewWrappedErrorWithDynamicContext(ErrManifestFileRead, err, fileName)
 		}
+		file, err = values.Render(fileName, file)
+		if err != nil {
+			return nil, err
+		}
 		files = append(files, string(file))
 	}
 	return files, nil
@@ -1,10 +1,12 @@
 package deploy
 
 import (
+	"errors"
 	"fmt"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -12,6 +14,7 @@ const (
 	pathToTestManifest1 = "testdata/testManifest1.yaml"
 	pathToTestManifest2 = "testdata/testManifest2.yaml"
 	pathToNestedDir     = "testdata/nested"
+	pathToKustomization = "testdata/kustomize/overlays/prod"
 	httpPath            = "http://my.hosted.yamls.armory.io/test.yaml"
 	httpsPath           = "https://my.hosted.yamls.armory.io/test.yaml"
 	s3Path              = "s3://th3morg-public/potato-facts-service.yaml"
@@ -61,7 +64,7 @@ func (s *ConvertRequestTestSuite) TestGetManifestsFromPaths() {
 			Path: s3Path,
 		},
 	}
-	files, err := getManifestFiles(manifests)
+	files, err := getManifestFiles(manifests, nil)
 	s.NoError(err)
 
 	s.Len(files[pathToTestManifest1], 1)
@@ -87,7 +90,7 @@ func (s *ConvertRequestTestSuite) TestGetManifestsFromGithubPath() {
 			Path: httpsPath,
 		},
 	}
-	files, err := getManifestFiles(manifests)
+	files, err := getManifestFiles(manifests, nil)
 	s.NoError(err)
 
 	s.Len(files["/"+pathToTestManifest1], 1)
@@ -95,6 +98,138 @@ func (s *ConvertRequestTestSuite) TestGetManifestsFromGithubPath() {
 	s.Len(files[pathToNestedDir], 2)
 }
 
+func (s *ConvertRequestTestSuite) TestGetManifestsFromKustomization() {
+	manifests := []manifest{
+		{
+			Kustomize: pathToKustomization,
+			Targets:   []string{"prod"},
+		},
+		{
+			// directories containing a kustomization file are built rather than walked
+			Path: "testdata/kustomize/base",
+		},
+	}
+	files, err := getManifestFiles(manifests, nil)
+	s.NoError(err)
+
+	s.Len(files[pathToKustomization], 1)
+	s.Contains(files[pathToKustomization][0], "name: prod-potato-facts")
+	s.Contains(files[pathToKustomization][0], "replicas: 3")
+	s.Len(files["testdata/kustomize/base"], 1)
+	s.Contains(files["testdata/kustomize/base"][0], "name: potato-facts")
+}
+
+func (s *ConvertRequestTestSuite) TestGetManifestsFromKustomizationWithValues() {
+	values, err := NewValues(nil, []string{"tag=2.0.0"}, true)
+	s.NoError(err)
+
+	files, err := getManifestFiles([]manifest{{Kustomize: "testdata/kustomize/templated"}}, values)
+	s.NoError(err)
+	s.Contains(files["testdata/kustomize/templated"][0], "image: armory/potato-facts:2.0.0")
+
+	read, err := ReadManifests("testdata/kustomize/templated", values)
+	s.NoError(err)
+	s.Contains(read[0], "image: armory/potato-facts:2.0.0")
+
+	strict, err := NewValues(nil, nil, true)
+	s.NoError(err)
+	_, err = getManifestFiles([]manifest{{Kustomize: "testdata/kustomize/templated"}}, strict)
+	s.ErrorIs(err, ErrUnresolvedValues)
+}
+
+func (s *ConvertRequestTestSuite) TestGetManifestsFromInvalidKustomization() {
+	_, err := getManifestFiles([]manifest{{Kustomize: pathToNestedDir}}, nil)
+	s.ErrorIs(err, ErrKustomizeBuild)
+}
+
+func (s *ConvertRequestTestSuite) TestConvertHelmManifests() {
+	originalRunHelm := runHelm
+	s.T().Cleanup(func() { runHelm = originalRunHelm })
+	var invocations [][]string
+	runHelm = func(args ...string) ([]byte, error) {
+		invocations = append(invocations, args)
+		return []byte("rendered: " + strings.Join(args, " ")), nil
+	}
+
+	dir, err := os.Getwd()
+	s.NoError(err)
+	request, err := convertPipelineOptionsToAPIRequest(StartPipelineOptions{
+		UnstructuredDeployment: map[string]any{
+			"application": "potato-facts",
+			"targets": map[string]any{
+				"staging": map[string]any{"account": "dev", "namespace": "potato-staging"},
+				"prod":    map[string]any{"account": "prod", "namespace": "potato-prod"},
+			},
+			"manifests": []any{
+				map[string]any{
+					"helm": map[string]any{
+						"chart":       "charts/potato-facts",
+						"valuesFiles": []any{"values.yaml"},
+						"set":         map[string]any{"image.tag": "1.2.3", "replicaCount": 2},
+					},
+				},
+			},
+		},
+	})
+	s.NoError(err)
+
+	s.Equal([]string{"template", "potato-facts", dir + "/charts/potato-facts", "--namespace", "potato-prod",
+		"--values", dir + "/values.yaml", "--set-json", `image.tag="1.2.3"`, "--set-json", "replicaCount=2"}, invocations[0])
+	s.Len(invocations, 2)
+	s.Equal([]any{
+		map[string]any{"path": "charts/potato-facts#prod", "targets": []string{"prod"}},
+		map[string]any{"path": "charts/potato-facts#staging", "targets": []string{"staging"}},
+	}, request[manifestsKey])
+	files := request[filesKey].(map[string][]string)
+	s.Len(files["charts/potato-facts#prod"], 1)
+	s.Contains(files["charts/potato-facts#staging"][0], "--namespace potato-staging")
+}
+
+func (s *ConvertRequestTestSuite) TestGetHelmManifestsWithValues() {
+	originalRunHelm := runHelm
+	s.T().Cleanup(func() { runHelm = originalRunHelm })
+	runHelm = func(args ...string) ([]byte, error) {
+		return []byte("image: armory/potato-facts:${tag}\n"), nil
+	}
+	values, err := NewValues(nil, []string{"tag=2.0.0"}, true)
+	s.NoError(err)
+
+	files, err := getHelmManifestFiles(&structuredConfig{
+		Targets:   map[string]target{"prod": {Namespace: "potato-prod"}},
+		Manifests: []manifest{{Helm: &helmChart{Chart: "charts/potato-facts"}}},
+	}, "potato-facts", values)
+	s.NoError(err)
+	s.Equal([]string{"image: armory/potato-facts:2.0.0\n"}, files["charts/potato-facts#prod"])
+}
+
+func (s *ConvertRequestTestSuite) TestRenderHelmChartSetsValuesAsJSON() {
+	originalRunHelm := runHelm
+	s.T().Cleanup(func() { runHelm = originalRunHelm })
+	var invocation []string
+	runHelm = func(args ...string) ([]byte, error) {
+		invocation = args
+		return []byte("rendered"), nil
+	}
+
+	_, err := renderHelmChart(helmChart{
+		Chart:       "charts/potato-facts",
+		ValuesFiles: []string{"values.yaml"},
+		Set: map[string]any{
+			"annotations.owners": "potato,tomato",
+			"hosts":              []any{"potato.example.com", "tomato.example.com"},
+			"resources":          map[string]any{"cpu": "100m"},
+		},
+	}, "potato-facts", "")
+	s.NoError(err)
+
+	dir, err := os.Getwd()
+	s.NoError(err)
+	s.Equal([]string{"template", "potato-facts", dir + "/charts/potato-facts", "--values", dir + "/values.yaml",
+		"--set-json", `annotations.owners="potato,tomato"`,
+		"--set-json", `hosts=["potato.example.com","tomato.example.com"]`,
+		"--set-json", `resources={"cpu":"100m"}`}, invocation)
+}
+
 func (s *ConvertRequestTestSuite) TestConvertPipelineOptionsToAPIRequest() {
 	cases := []struct {
 		options   StartPipelineOptions
@@ -182,6 +317,23 @@ func (s *ConvertRequestTestSuite) TestConvertPipelineOptionsToAPIRequest() {
 			},
 			expectErr: true,
 		},
+		{
+			options: StartPipelineOptions{
+				UnstructuredDeployment: map[string]any{
+					"application": "app",
+					"manifests": []any{
+						map[string]any{
+							"kustomize": pathToKustomization,
+							"targets":   []any{"prod"},
+						},
+					},
+				},
+			},
+			assertion: func(t *testing.T, request map[string]any) {
+				assert.Equal(t, []any{map[string]any{"path": pathToKustomization, "targets": []any{"prod"}}}, request["manifests"])
+				assert.Len(t, request[filesKey].(map[string][]string)[pathToKustomization], 1)
+			},
+		},
 	}
 
 	for i, c := range cases {
@@ -196,3 +348,14 @@ func (s *ConvertRequestTestSuite) TestConvertPipelineOptionsToAPIRequest() {
 		})
 	}
 }
+
+func (s *ConvertRequestTestSuite) TestRenderHelmChartRequiresSetJSON() {
+	originalRunHelm := runHelm
+	s.T().Cleanup(func() { runHelm = originalRunHelm })
+	runHelm = func(args ...string) ([]byte, error) {
+		return nil, errors.New("exit status 1: Error: unknown flag: --set-json")
+	}
+
+	_, err := renderHelmChart(helmChart{Chart: "charts/potato-facts", Set: map[string]any{"replicaCount": 2}}, "potato-facts", "")
+	s.ErrorIs(err, ErrHelmSetJSONUnsupported)
+}
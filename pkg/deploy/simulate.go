@@ -0,0 +1,256 @@
+package deploy
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	errorUtils "github.com/armory/armory-cli/pkg/errors"
+	"github.com/mitchellh/mapstructure"
+	"github.com/samber/lo"
+)
+
+type (
+	// Simulation is the timeline of a deployment as it would play out if every step succeeds, every webhook returns at
+	// once and every manual gate is passed as soon as it is reached.
+	Simulation struct {
+		Targets []TargetTimeline `json:"targets" yaml:"targets"`
+		// MinimumDuration is the time from the start of the deployment to the end of its last target.
+		MinimumDuration Duration     `json:"minimumDuration" yaml:"minimumDuration"`
+		ManualGates     []ManualGate `json:"manualGates" yaml:"manualGates"`
+	}
+
+	// TargetTimeline is the timeline of a target. Its offsets are counted from the start of the deployment.
+	TargetTimeline struct {
+		Target    string          `json:"target" yaml:"target"`
+		DependsOn []string        `json:"dependsOn,omitempty" yaml:"dependsOn,omitempty"`
+		Start     Duration        `json:"start" yaml:"start"`
+		End       Duration        `json:"end" yaml:"end"`
+		Events    []TimelineEvent `json:"events" yaml:"events"`
+	}
+
+	TimelineEvent struct {
+		Offset      Duration `json:"offset" yaml:"offset"`
+		Phase       string   `json:"phase" yaml:"phase"`
+		Description string   `json:"description" yaml:"description"`
+		Duration    Duration `json:"duration,omitempty" yaml:"duration,omitempty"`
+		// Weight is the share of traffic sent to the new version once the step is done, for steps of the strategy.
+		Weight *int `json:"weight,omitempty" yaml:"weight,omitempty"`
+		Manual bool `json:"manual,omitempty" yaml:"manual,omitempty"`
+	}
+
+	// Duration is a time.Duration written as text, such as 5m30s, in JSON and YAML.
+	Duration time.Duration
+
+	ManualGate struct {
+		Target      string `json:"target" yaml:"target"`
+		Phase       string `json:"phase" yaml:"phase"`
+		Description string `json:"description" yaml:"description"`
+	}
+
+	simulationConfig struct {
+		Targets    map[string]simulatedTarget   `yaml:"targets"`
+		Strategies map[string]simulatedStrategy `yaml:"strategies"`
+	}
+
+	simulatedTarget struct {
+		Strategy    string `yaml:"strategy"`
+		Constraints struct {
+			DependsOn        []string        `yaml:"dependsOn"`
+			BeforeDeployment []simulatedStep `yaml:"beforeDeployment"`
+			AfterDeployment  []simulatedStep `yaml:"afterDeployment"`
+		} `yaml:"constraints"`
+	}
+
+	simulatedStrategy struct {
+		Canary *struct {
+			Steps []simulatedStep `yaml:"steps"`
+		} `yaml:"canary"`
+		BlueGreen *struct {
+			RedirectTrafficAfter    []simulatedStep `yaml:"redirectTrafficAfter"`
+			ShutDownOldVersionAfter []simulatedStep `yaml:"shutDownOldVersionAfter"`
+		} `yaml:"blueGreen"`
+	}
+
+	simulatedStep struct {
+		SetWeight *struct {
+			Weight int `yaml:"weight"`
+		} `yaml:"setWeight"`
+		Pause *struct {
+			Duration      int      `yaml:"duration"`
+			Unit          string   `yaml:"unit"`
+			UntilApproved bool     `yaml:"untilApproved"`
+			RequiresRoles []string `yaml:"requiresRoles"`
+		} `yaml:"pause"`
+		Analysis *struct {
+			Interval             int      `yaml:"interval"`
+			Units                string   `yaml:"units"`
+			NumberOfJudgmentRuns int      `yaml:"numberOfJudgmentRuns"`
+			Queries              []string `yaml:"queries"`
+			RollForwardMode      string   `yaml:"rollForwardMode"`
+		} `yaml:"analysis"`
+		RunWebhook *struct {
+			Name string `yaml:"name"`
+		} `yaml:"runWebhook"`
+		ExposeServices *struct {
+			Services []string `yaml:"services"`
+		} `yaml:"exposeServices"`
+	}
+)
+
+const (
+	PhaseBeforeDeployment = "before deployment"
+	PhaseStrategy         = "strategy"
+	PhaseAfterDeployment  = "after deployment"
+)
+
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// timeUnits are the durations of the units of #TimeUnit, which the schema matches regardless of case.
+var timeUnits = map[string]time.Duration{
+	"none":    0,
+	"seconds": time.Second,
+	"minutes": time.Minute,
+	"hours":   time.Hour,
+}
+
+// Simulate expands the strategies of the targets of a deployment into a timeline, walking the targets in dependency
+// order. A target starts when the targets it depends on, including the steps after their deployment, are done.
+func Simulate(unstructuredDeployment map[string]any) (*Simulation, error) {
+	var config simulationConfig
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{Result: &config, WeaklyTypedInput: true})
+	if err != nil {
+		return nil, err
+	}
+	if err := decoder.Decode(unstructuredDeployment); err != nil {
+		return nil, errorUtils.NewWrappedError(ErrSimulationConfig, err)
+	}
+	order, err := dependencyOrder(config.Targets)
+	if err != nil {
+		return nil, err
+	}
+
+	simulation := &Simulation{Targets: []TargetTimeline{}, ManualGates: []ManualGate{}}
+	ends := map[string]Duration{}
+	for _, name := range order {
+		target := config.Targets[name]
+		timeline := TargetTimeline{Target: name, DependsOn: target.Constraints.DependsOn, Events: []TimelineEvent{}}
+		timeline.Start = lo.Max(lo.Map(target.Constraints.DependsOn, func(dependency string, _ int) Duration { return ends[dependency] }))
+		s := &simulator{timeline: &timeline, offset: timeline.Start}
+		s.run(PhaseBeforeDeployment, target.Constraints.BeforeDeployment)
+		s.strategy(config.Strategies[target.Strategy])
+		s.run(PhaseAfterDeployment, target.Constraints.AfterDeployment)
+		timeline.End = s.offset
+		ends[name] = timeline.End
+
+		simulation.Targets = append(simulation.Targets, timeline)
+		simulation.MinimumDuration = lo.Max([]Duration{simulation.MinimumDuration, timeline.End})
+		for _, event := range timeline.Events {
+			if event.Manual {
+				simulation.ManualGates = append(simulation.ManualGates, ManualGate{Target: name, Phase: event.Phase, Description: event.Description})
+			}
+		}
+	}
+	return simulation, nil
+}
+
+// dependencyOrder sorts targets so that each one comes after the targets it depends on, and by name otherwise.
+func dependencyOrder(targets map[string]simulatedTarget) ([]string, error) {
+	remaining := lo.Keys(targets)
+	sort.Strings(remaining)
+	done := map[string]bool{}
+	var order []string
+	for len(remaining) > 0 {
+		ready, found := lo.Find(remaining, func(name string) bool {
+			return lo.EveryBy(targets[name].Constraints.DependsOn, func(dependency string) bool {
+				_, exists := targets[dependency]
+				return done[dependency] || !exists
+			})
+		})
+		if !found {
+			return nil, errorUtils.NewErrorWithDynamicContext(ErrDependencyCycle, ": "+strings.Join(remaining, ", "))
+		}
+		done[ready] = true
+		order = append(order, ready)
+		remaining = lo.Without(remaining, ready)
+	}
+	return order, nil
+}
+
+type simulator struct {
+	timeline *TargetTimeline
+	offset   Duration
+	weight   int
+}
+
+func (s *simulator) strategy(strategy simulatedStrategy) {
+	switch {
+	case strategy.Canary != nil:
+		s.run(PhaseStrategy, strategy.Canary.Steps)
+	case strategy.BlueGreen != nil:
+		s.add(PhaseStrategy, TimelineEvent{Description: "deploy preview"})
+		s.run(PhaseStrategy, strategy.BlueGreen.RedirectTrafficAfter)
+		s.weight = 100
+		s.add(PhaseStrategy, TimelineEvent{Description: "redirect traffic"})
+		s.run(PhaseStrategy, strategy.BlueGreen.ShutDownOldVersionAfter)
+		s.add(PhaseStrategy, TimelineEvent{Description: "shut down old version"})
+	}
+}
+
+func (s *simulator) run(phase string, steps []simulatedStep) {
+	for _, step := range steps {
+		s.add(phase, s.event(step))
+	}
+}
+
+func (s *simulator) add(phase string, event TimelineEvent) {
+	event.Offset = s.offset
+	event.Phase = phase
+	if phase == PhaseStrategy {
+		event.Weight = lo.ToPtr(s.weight)
+	}
+	s.timeline.Events = append(s.timeline.Events, event)
+	s.offset += event.Duration
+}
+
+func (s *simulator) event(step simulatedStep) TimelineEvent {
+	switch {
+	case step.SetWeight != nil:
+		s.weight = step.SetWeight.Weight
+		return TimelineEvent{Description: fmt.Sprintf("set weight %d%%", step.SetWeight.Weight)}
+	case step.Pause != nil && step.Pause.UntilApproved:
+		description := "wait for approval"
+		if len(step.Pause.RequiresRoles) > 0 {
+			description += " by " + strings.Join(step.Pause.RequiresRoles, ", ")
+		}
+		return TimelineEvent{Description: description, Manual: true}
+	case step.Pause != nil:
+		unit := strings.ToLower(step.Pause.Unit)
+		return TimelineEvent{
+			Description: fmt.Sprintf("pause %d %s", step.Pause.Duration, unit),
+			Duration:    Duration(time.Duration(step.Pause.Duration) * timeUnits[unit]),
+		}
+	case step.Analysis != nil:
+		analysis := step.Analysis
+		units := strings.ToLower(analysis.Units)
+		return TimelineEvent{
+			Description: fmt.Sprintf("analysis %s, %d run(s) every %d %s", strings.Join(analysis.Queries, ", "), analysis.NumberOfJudgmentRuns, analysis.Interval, units),
+			Duration:    Duration(time.Duration(analysis.Interval*analysis.NumberOfJudgmentRuns) * timeUnits[units]),
+			// the deployment waits for someone to roll forward once the analysis is done
+			Manual: strings.EqualFold(analysis.RollForwardMode, "manual"),
+		}
+	case step.RunWebhook != nil:
+		return TimelineEvent{Description: "run webhook " + step.RunWebhook.Name}
+	case step.ExposeServices != nil:
+		return TimelineEvent{Description: "expose " + strings.Join(step.ExposeServices.Services, ", ")}
+	default:
+		return TimelineEvent{Description: "unknown step"}
+	}
+}
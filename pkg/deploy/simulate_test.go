@@ -0,0 +1,102 @@
+package deploy
+
+import (
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+	"testing"
+	"time"
+)
+
+const simulationYamlStr = `
+targets:
+  prod:
+    strategy: canary
+    constraints:
+      dependsOn: [staging]
+      beforeDeployment:
+        - pause:
+            untilApproved: true
+            requiresRoles: [release]
+  staging:
+    strategy: bluegreen
+    constraints:
+      afterDeployment:
+        - pause:
+            duration: 2
+            unit: MINUTES
+strategies:
+  canary:
+    canary:
+      steps:
+        - setWeight:
+            weight: 25
+        - pause:
+            duration: 30
+            unit: seconds
+        - analysis:
+            interval: 1
+            units: minutes
+            numberOfJudgmentRuns: 3
+            queries: [errorRate]
+            rollForwardMode: manual
+        - setWeight:
+            weight: 100
+  bluegreen:
+    blueGreen:
+      redirectTrafficAfter:
+        - runWebhook:
+            name: smoke
+`
+
+func TestSimulate(t *testing.T) {
+	var payload map[string]any
+	assert.NoError(t, yaml.Unmarshal([]byte(simulationYamlStr), &payload))
+	simulation, err := Simulate(payload)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"staging", "prod"}, lo.Map(simulation.Targets, func(target TargetTimeline, _ int) string { return target.Target }))
+	staging, prod := simulation.Targets[0], simulation.Targets[1]
+	assert.Equal(t, Duration(2*time.Minute), staging.End)
+	assert.Equal(t, []TimelineEvent{
+		{Phase: PhaseStrategy, Description: "deploy preview", Weight: lo.ToPtr(0)},
+		{Phase: PhaseStrategy, Description: "run webhook smoke", Weight: lo.ToPtr(0)},
+		{Phase: PhaseStrategy, Description: "redirect traffic", Weight: lo.ToPtr(100)},
+		{Phase: PhaseStrategy, Description: "shut down old version", Weight: lo.ToPtr(100)},
+		{Phase: PhaseAfterDeployment, Description: "pause 2 minutes", Duration: Duration(2 * time.Minute)},
+	}, staging.Events)
+
+	// prod starts once staging and the steps after its deployment are done
+	assert.Equal(t, Duration(2*time.Minute), prod.Start)
+	assert.Equal(t, Duration(5*time.Minute+30*time.Second), prod.End)
+	assert.Equal(t, TimelineEvent{
+		Offset:      Duration(2*time.Minute + 30*time.Second),
+		Phase:       PhaseStrategy,
+		Description: "analysis errorRate, 3 run(s) every 1 minutes",
+		Duration:    Duration(3 * time.Minute),
+		Weight:      lo.ToPtr(25),
+		Manual:      true,
+	}, prod.Events[3])
+
+	assert.Equal(t, Duration(5*time.Minute+30*time.Second), simulation.MinimumDuration)
+	assert.Equal(t, []ManualGate{
+		{Target: "prod", Phase: PhaseBeforeDeployment, Description: "wait for approval by release"},
+		{Target: "prod", Phase: PhaseStrategy, Description: "analysis errorRate, 3 run(s) every 1 minutes"},
+	}, simulation.ManualGates)
+}
+
+func TestSimulateDependencyCycle(t *testing.T) {
+	_, err := Simulate(map[string]any{
+		"targets": map[string]any{
+			"a": map[string]any{"constraints": map[string]any{"dependsOn": []any{"b"}}},
+			"b": map[string]any{"constraints": map[string]any{"dependsOn": []any{"a"}}},
+		},
+	})
+	assert.ErrorIs(t, err, ErrDependencyCycle)
+}
+
+func TestDurationMarshalText(t *testing.T) {
+	text, err := Duration(90 * time.Second).MarshalText()
+	assert.NoError(t, err)
+	assert.Equal(t, "1m30s", string(text))
+}
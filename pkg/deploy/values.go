@@ -0,0 +1,118 @@
+package deploy
+
+import (
+	"fmt"
+	errorUtils "github.com/armory/armory-cli/pkg/errors"
+	"github.com/samber/lo"
+	"gopkg.in/yaml.v3"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Values are substituted into the deployment file and the manifests it references before they are validated and sent.
+// Two placeholder forms are supported, ${key} and {{ .key }}, where key is a dot separated path into the values. Other
+// template expressions, such as the {{armory.deploymentId}} context variables used in webhooks, are left untouched.
+type Values struct {
+	values map[string]any
+	// Strict makes rendering fail when a placeholder cannot be resolved. Otherwise, it is left as is.
+	Strict bool
+}
+
+var valuePlaceholder = regexp.MustCompile(`\$\{\s*([A-Za-z0-9_.-]+)\s*\}|\{\{\s*\.([A-Za-z0-9_.-]+)\s*\}\}`)
+
+// NewValues reads the values files in order, with later files taking precedence, then applies the key=value overrides.
+func NewValues(valuesFiles []string, overrides []string, strict bool) (*Values, error) {
+	values := map[string]any{}
+	for _, valuesFile := range valuesFiles {
+		content, err := os.ReadFile(valuesFile)
+		if err != nil {
+			return nil, errorUtils.NewWrappedErrorWithDynamicContext(ErrValuesFileRead, err, " "+valuesFile)
+		}
+		var fileValues map[string]any
+		if err := yaml.Unmarshal(content, &fileValues); err != nil {
+			return nil, errorUtils.NewWrappedErrorWithDynamicContext(ErrValuesFileRead, err, " "+valuesFile)
+		}
+		values = mergeValues(values, fileValues)
+	}
+	for _, override := range overrides {
+		key, value, found := strings.Cut(override, "=")
+		if !found || key == "" {
+			return nil, errorUtils.NewErrorWithDynamicContext(ErrInvalidValueOverride, ": "+override)
+		}
+		values = mergeValues(values, nestedValue(strings.Split(key, "."), value))
+	}
+	return &Values{values: values, Strict: strict}, nil
+}
+
+// Render substitutes the placeholders in content. The name identifies the content in errors.
+func (v *Values) Render(name string, content []byte) ([]byte, error) {
+	if v == nil {
+		return content, nil
+	}
+	var unresolved []string
+	rendered := valuePlaceholder.ReplaceAllStringFunc(string(content), func(placeholder string) string {
+		groups := valuePlaceholder.FindStringSubmatch(placeholder)
+		key := lo.Ternary(groups[1] != "", groups[1], groups[2])
+		value, ok := v.lookup(key)
+		if !ok {
+			unresolved = append(unresolved, key)
+			return placeholder
+		}
+		return value
+	})
+	if len(unresolved) > 0 && v.Strict {
+		unresolved = lo.Uniq(unresolved)
+		sort.Strings(unresolved)
+		return nil, errorUtils.NewErrorWithDynamicContext(ErrUnresolvedValues, fmt.Sprintf(" in %s: %s", name, strings.Join(unresolved, ", ")))
+	}
+	return []byte(rendered), nil
+}
+
+// HasPlaceholders reports whether content contains ${key} or {{ .key }} placeholders, such as the ones left when no
+// value is given for them.
+func HasPlaceholders(content string) bool {
+	return valuePlaceholder.MatchString(content)
+}
+
+func (v *Values) lookup(key string) (string, bool) {
+	var current any = v.values
+	for _, part := range strings.Split(key, ".") {
+		asMap, ok := current.(map[string]any)
+		if !ok {
+			return "", false
+		}
+		if current, ok = asMap[part]; !ok {
+			return "", false
+		}
+	}
+	switch current.(type) {
+	case map[string]any, []any:
+		// only scalars can be substituted into text
+		return "", false
+	}
+	return fmt.Sprint(current), true
+}
+
+func nestedValue(path []string, value string) map[string]any {
+	if len(path) == 1 {
+		return map[string]any{path[0]: value}
+	}
+	return map[string]any{path[0]: nestedValue(path[1:], value)}
+}
+
+// mergeValues deep merges override into base, with override taking precedence.
+func mergeValues(base, override map[string]any) map[string]any {
+	merged := lo.Assign(base)
+	for key, value := range override {
+		baseMap, baseIsMap := merged[key].(map[string]any)
+		overrideMap, overrideIsMap := value.(map[string]any)
+		if baseIsMap && overrideIsMap {
+			merged[key] = mergeValues(baseMap, overrideMap)
+			continue
+		}
+		merged[key] = value
+	}
+	return merged
+}
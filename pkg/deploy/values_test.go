@@ -0,0 +1,72 @@
+package deploy
+
+import (
+	"github.com/armory/armory-cli/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestValuesRender(t *testing.T) {
+	valuesFile := util.TempAppFile("", "values", `
+application: potato-facts
+image:
+  repository: armory/potato-facts
+  tag: 1.0.0
+replicas: 2
+`)
+	assert.NotNil(t, valuesFile)
+	t.Cleanup(func() { os.Remove(valuesFile.Name()) })
+
+	cases := []struct {
+		name              string
+		overrides         []string
+		strict            bool
+		content           string
+		expected          string
+		expectErrContains string
+	}{
+		{
+			name:     "substitutes both placeholder forms",
+			content:  "application: ${application}\nimage: {{ .image.repository }}:${image.tag}\nreplicas: {{.replicas}}",
+			expected: "application: potato-facts\nimage: armory/potato-facts:1.0.0\nreplicas: 2",
+		},
+		{
+			name:      "overrides take precedence over values files",
+			overrides: []string{"image.tag=2.0.0"},
+			content:   "image: ${image.repository}:${image.tag}",
+			expected:  "image: armory/potato-facts:2.0.0",
+		},
+		{
+			name:     "leaves context variables and unresolved placeholders untouched",
+			content:  "uriTemplate: https://example.com/{{armory.deploymentId}}?env=${environment}",
+			expected: "uriTemplate: https://example.com/{{armory.deploymentId}}?env=${environment}",
+		},
+		{
+			name:              "strict mode fails on unresolved placeholders",
+			strict:            true,
+			content:           "namespace: ${namespace}\naccount: {{ .account }}\nimage: ${image}",
+			expectErrContains: "unresolved values in deploy.yaml: account, image, namespace",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			values, err := NewValues([]string{valuesFile.Name()}, c.overrides, c.strict)
+			assert.NoError(t, err)
+
+			rendered, err := values.Render("deploy.yaml", []byte(c.content))
+			if c.expectErrContains != "" {
+				assert.ErrorContains(t, err, c.expectErrContains)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, c.expected, string(rendered))
+		})
+	}
+}
+
+func TestNewValuesInvalidOverride(t *testing.T) {
+	_, err := NewValues(nil, []string{"image.tag"}, false)
+	assert.ErrorIs(t, err, ErrInvalidValueOverride)
+}
@@ -0,0 +1,87 @@
+package findings
+
+import (
+	"fmt"
+	"github.com/samber/lo"
+	"os"
+	"strings"
+)
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityNote    Severity = "note"
+
+	envVarGithubActions = "GITHUB_ACTIONS"
+)
+
+// Finding is a problem found in a file, such as a deployment file that does not match its schema. Line and Column
+// locate the offending value when it is known, starting at 1.
+type Finding struct {
+	File     string   `json:"file,omitempty" yaml:"file,omitempty"`
+	Path     string   `json:"path" yaml:"path"`
+	Line     int      `json:"line,omitempty" yaml:"line,omitempty"`
+	Column   int      `json:"column,omitempty" yaml:"column,omitempty"`
+	Message  string   `json:"message" yaml:"message"`
+	Severity Severity `json:"severity" yaml:"severity"`
+	// Rule identifies the check that produced the finding, e.g. schema.
+	Rule string `json:"rule,omitempty" yaml:"rule,omitempty"`
+}
+
+func (f Finding) String() string {
+	if f.Path == "" {
+		// findings about the whole file, such as a custom policy message
+		return f.Message
+	}
+	if f.Line > 0 {
+		return fmt.Sprintf("%s: line %d, column %d: %s", f.Path, f.Line, f.Column, f.Message)
+	}
+	return fmt.Sprintf("%s: %s", f.Path, f.Message)
+}
+
+// GitHubAnnotation formats the finding as a GitHub Actions workflow command, so that it is shown inline on the file
+// in pull requests.
+func (f Finding) GitHubAnnotation() string {
+	command := "error"
+	switch f.Severity {
+	case SeverityWarning:
+		command = "warning"
+	case SeverityNote:
+		command = "notice"
+	}
+	var properties []string
+	if f.File != "" {
+		properties = append(properties, "file="+escapeProperty(f.File))
+	}
+	if f.Line > 0 {
+		properties = append(properties, fmt.Sprintf("line=%d", f.Line), fmt.Sprintf("col=%d", f.Column))
+	}
+	if f.Path != "" {
+		properties = append(properties, "title="+escapeProperty(f.Path))
+	}
+	if len(properties) == 0 {
+		return fmt.Sprintf("::%s::%s", command, escapeData(f.Message))
+	}
+	return fmt.Sprintf("::%s %s::%s", command, strings.Join(properties, ","), escapeData(f.Message))
+}
+
+// HasErrors reports whether any of the findings is an error, as opposed to a warning or a note.
+func HasErrors(findings []Finding) bool {
+	return lo.SomeBy(findings, func(f Finding) bool { return f.Severity == SeverityError })
+}
+
+// InGitHubActions reports whether the CLI is running in a GitHub Actions workflow, where findings are best reported
+// as annotations.
+func InGitHubActions() bool {
+	return os.Getenv(envVarGithubActions) == "true"
+}
+
+func escapeData(value string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(value)
+}
+
+func escapeProperty(value string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(value)
+}
@@ -0,0 +1,51 @@
+package findings
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGitHubAnnotation(t *testing.T) {
+	finding := Finding{
+		File:     "deploy.yml",
+		Path:     "targets.dev, prod",
+		Line:     3,
+		Column:   5,
+		Message:  "100% wrong\nsee docs",
+		Severity: SeverityWarning,
+	}
+	assert.Equal(t, "::warning file=deploy.yml,line=3,col=5,title=targets.dev%2C prod::100%25 wrong%0Asee docs", finding.GitHubAnnotation())
+
+	finding = Finding{Path: "kind", Message: "missing", Severity: SeverityError}
+	assert.Equal(t, "::error title=kind::missing", finding.GitHubAnnotation())
+
+	finding = Finding{Message: "target prod must depend on staging", Severity: SeverityError}
+	assert.Equal(t, "::error::target prod must depend on staging", finding.GitHubAnnotation())
+	assert.Equal(t, "target prod must depend on staging", finding.String())
+}
+
+func TestHasErrors(t *testing.T) {
+	assert.False(t, HasErrors([]Finding{{Severity: SeverityWarning}, {Severity: SeverityNote}}))
+	assert.True(t, HasErrors([]Finding{{Severity: SeverityWarning}, {Severity: SeverityError}}))
+}
+
+func TestNewSarifLog(t *testing.T) {
+	log := NewSarifLog("armory", "1.0.0", []Finding{
+		{File: "deploy.yml", Path: "targets.dev", Line: 3, Column: 5, Message: "unknown field", Severity: SeverityError, Rule: "schema"},
+		{Path: "kind", Message: "missing", Severity: SeverityWarning, Rule: "schema"},
+	})
+
+	run := log.Runs[0]
+	assert.Equal(t, "2.1.0", log.Version)
+	assert.Equal(t, []sarifRule{{ID: "schema"}}, run.Tool.Driver.Rules)
+	assert.Equal(t, sarifResult{
+		RuleID:  "schema",
+		Level:   "error",
+		Message: sarifMessage{Text: "targets.dev: unknown field"},
+		Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: "deploy.yml"},
+			Region:           &sarifRegion{StartLine: 3, StartColumn: 5},
+		}}},
+	}, run.Results[0])
+	assert.Empty(t, run.Results[1].Locations)
+}
@@ -0,0 +1,105 @@
+package findings
+
+import (
+	"github.com/samber/lo"
+)
+
+const (
+	sarifSchema         = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion        = "2.1.0"
+	sarifInformationURI = "https://docs.armory.io/cd-as-a-service/"
+)
+
+type (
+	// SarifLog is a SARIF 2.1.0 log with a single run, the format accepted by code scanning tools such as GitHub's.
+	SarifLog struct {
+		Schema  string     `json:"$schema"`
+		Version string     `json:"version"`
+		Runs    []sarifRun `json:"runs"`
+	}
+
+	sarifRun struct {
+		Tool    sarifTool     `json:"tool"`
+		Results []sarifResult `json:"results"`
+	}
+
+	sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+
+	sarifDriver struct {
+		Name           string      `json:"name"`
+		Version        string      `json:"version,omitempty"`
+		InformationURI string      `json:"informationUri"`
+		Rules          []sarifRule `json:"rules"`
+	}
+
+	sarifRule struct {
+		ID string `json:"id"`
+	}
+
+	sarifResult struct {
+		RuleID    string          `json:"ruleId"`
+		Level     string          `json:"level"`
+		Message   sarifMessage    `json:"message"`
+		Locations []sarifLocation `json:"locations,omitempty"`
+	}
+
+	sarifMessage struct {
+		Text string `json:"text"`
+	}
+
+	sarifLocation struct {
+		PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	}
+
+	sarifPhysicalLocation struct {
+		ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+		Region           *sarifRegion          `json:"region,omitempty"`
+	}
+
+	sarifArtifactLocation struct {
+		URI string `json:"uri"`
+	}
+
+	sarifRegion struct {
+		StartLine   int `json:"startLine"`
+		StartColumn int `json:"startColumn,omitempty"`
+	}
+)
+
+// NewSarifLog reports the findings of a tool, e.g. armory validate, as a SARIF log.
+func NewSarifLog(tool, version string, findings []Finding) SarifLog {
+	results := lo.Map(findings, func(f Finding, _ int) sarifResult {
+		result := sarifResult{
+			RuleID:  f.Rule,
+			Level:   string(f.Severity),
+			Message: sarifMessage{Text: lo.Ternary(f.Path == "", f.Message, f.Path+": "+f.Message)},
+		}
+		if f.File != "" {
+			location := sarifLocation{PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: f.File}}}
+			if f.Line > 0 {
+				location.PhysicalLocation.Region = &sarifRegion{StartLine: f.Line, StartColumn: f.Column}
+			}
+			result.Locations = []sarifLocation{location}
+		}
+		return result
+	})
+	rules := lo.Map(lo.Uniq(lo.Map(findings, func(f Finding, _ int) string { return f.Rule })), func(id string, _ int) sarifRule {
+		return sarifRule{ID: id}
+	})
+
+	return SarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           tool,
+				Version:        version,
+				InformationURI: sarifInformationURI,
+				Rules:          rules,
+			}},
+			Results: results,
+		}},
+	}
+}
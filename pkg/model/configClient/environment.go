@@ -15,3 +15,12 @@ type CreateEnvironmentResponse struct {
 	Name  string `json:"name"`
 	Roles []any  `json:"roles"`
 }
+
+type UpdateEnvironmentRequest struct {
+	ID   string `json:"-"`
+	Name string `json:"name"`
+}
+
+type DeleteEnvironmentRequest struct {
+	ID string `json:"-"`
+}
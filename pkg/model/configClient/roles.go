@@ -9,6 +9,7 @@ type CreateRoleRequest struct {
 }
 
 type CreateRoleResponse struct {
+	ID     string              `yaml:"id,omitempty"`
 	Name   string              `yaml:"name,omitempty"`
 	Tenant string              `yaml:"tenant,omitempty"`
 	Grants []model.GrantConfig `yaml:"grants,omitempty"`
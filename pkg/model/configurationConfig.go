@@ -1,9 +1,46 @@
 package model
 
+import (
+	"encoding/json"
+
+	"gopkg.in/yaml.v3"
+)
+
 type ConfigurationConfig struct {
-	AllowAutoDelete bool         `yaml:"allowAutoDelete"`
-	Environments    []string     `yaml:"tenants,omitempty"`
-	Roles           []RoleConfig `yaml:"roles,omitempty"`
+	AllowAutoDelete bool           `yaml:"allowAutoDelete"`
+	Environments    []TenantConfig `yaml:"tenants,omitempty"`
+	Roles           []RoleConfig   `yaml:"roles,omitempty"`
+}
+
+// TenantConfig is a tenant of an RBAC configuration file, written as its name or, to rename a tenant, as its name and
+// previousName.
+type TenantConfig struct {
+	Name         string `json:"name" yaml:"name"`
+	PreviousName string `json:"previousName,omitempty" yaml:"previousName,omitempty"`
+}
+
+func (t *TenantConfig) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		return node.Decode(&t.Name)
+	}
+	type tenantConfig TenantConfig
+	return node.Decode((*tenantConfig)(t))
+}
+
+func (t TenantConfig) MarshalYAML() (interface{}, error) {
+	if t.PreviousName == "" {
+		return t.Name, nil
+	}
+	type tenantConfig TenantConfig
+	return tenantConfig(t), nil
+}
+
+func (t *TenantConfig) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &t.Name); err == nil {
+		return nil
+	}
+	type tenantConfig TenantConfig
+	return json.Unmarshal(data, (*tenantConfig)(t))
 }
 
 type ConfigurationOutput struct {
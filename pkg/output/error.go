@@ -5,7 +5,11 @@ import (
 )
 
 var (
-	ErrJsonMarshal = errors.New("failed to marshal response to json")
-	ErrYamlMarshal = errors.New("failed to marshal response to yaml")
-	ErrHttpRequest = errors.New("request returned an error")
+	ErrJsonMarshal       = errors.New("failed to marshal response to json")
+	ErrYamlMarshal       = errors.New("failed to marshal response to yaml")
+	ErrCsvMarshal        = errors.New("failed to marshal response to csv")
+	ErrCsvNotTable       = errors.New("csv output is not supported by this command")
+	ErrSarifMarshal      = errors.New("failed to marshal response to sarif")
+	ErrSarifNotSupported = errors.New("sarif output is not supported by this command")
+	ErrHttpRequest       = errors.New("request returned an error")
 )
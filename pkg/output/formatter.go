@@ -1,11 +1,15 @@
 package output
 
 import (
+	"bytes"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	errorUtils "github.com/armory/armory-cli/pkg/errors"
 	"gopkg.in/yaml.v3"
 	_nethttp "net/http"
+	"strings"
+	"text/tabwriter"
 )
 
 type Formattable interface {
@@ -14,6 +18,17 @@ type Formattable interface {
 	Get() interface{}
 }
 
+// SarifReporter is implemented by a Formattable whose data are findings that can be uploaded for code scanning.
+type SarifReporter interface {
+	SarifLog() any
+}
+
+// Tabular is implemented by a Formattable whose data can be rendered as rows and columns, such as a list of resources.
+type Tabular interface {
+	Header() []string
+	Rows() [][]string
+}
+
 type Formatter func(Formattable) (string, error)
 
 type Output struct {
@@ -26,6 +41,10 @@ func GetFormatterForOutputType(outputFormat Type) Formatter {
 		return MarshalToJson
 	case outputFormat == Yaml:
 		return MarshalToYaml
+	case outputFormat == Csv:
+		return MarshalToCsv
+	case outputFormat == Sarif:
+		return MarshalToSarif
 	default:
 		return DefaultStructToString
 	}
@@ -86,3 +105,55 @@ func getRequestError(input Formattable) error {
 
 	return err
 }
+
+func MarshalToCsv(input Formattable) (string, error) {
+	err := getRequestError(input)
+	if err != nil {
+		return "", err
+	}
+
+	table, ok := input.(Tabular)
+	if !ok {
+		return "", ErrCsvNotTable
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.Write(table.Header()); err != nil {
+		return "", errorUtils.NewWrappedError(ErrCsvMarshal, err)
+	}
+	if err := writer.WriteAll(table.Rows()); err != nil {
+		return "", errorUtils.NewWrappedError(ErrCsvMarshal, err)
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+func MarshalToSarif(input Formattable) (string, error) {
+	err := getRequestError(input)
+	if err != nil {
+		return "", err
+	}
+
+	reporter, ok := input.(SarifReporter)
+	if !ok {
+		return "", ErrSarifNotSupported
+	}
+
+	pretty, err := json.MarshalIndent(reporter.SarifLog(), "", "  ")
+	if err != nil {
+		return "", errorUtils.NewWrappedError(ErrSarifMarshal, err)
+	}
+	return string(pretty), nil
+}
+
+// FormatTable renders tabular data as aligned columns for text output.
+func FormatTable(table Tabular) string {
+	var buf bytes.Buffer
+	writer := tabwriter.NewWriter(&buf, 0, 0, 3, ' ', 0)
+	_, _ = fmt.Fprintln(writer, strings.Join(table.Header(), "\t"))
+	for _, row := range table.Rows() {
+		_, _ = fmt.Fprintln(writer, strings.Join(row, "\t"))
+	}
+	_ = writer.Flush()
+	return strings.TrimSuffix(buf.String(), "\n")
+}
@@ -6,4 +6,6 @@ const (
 	Text Type = iota
 	Yaml
 	Json
+	Csv
+	Sarif
 )
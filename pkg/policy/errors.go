@@ -0,0 +1,14 @@
+package policy
+
+import (
+	"errors"
+)
+
+var (
+	ErrReadingPolicies   = errors.New("error trying to read the policies")
+	ErrNoPolicies        = errors.New("no CUE policies found in the policy directory")
+	ErrInvalidPolicy     = errors.New("invalid CUE policy")
+	ErrRegoNotSupported  = errors.New("Rego policies are not supported, write the policy in CUE")
+	ErrPolicyViolation   = errors.New("the deployment violates policies")
+	ErrInvalidViolations = errors.New("the violations of a policy must be a list of messages")
+)
@@ -0,0 +1,134 @@
+package policy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	cueerrors "cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
+	"github.com/armory/armory-cli/internal/clierr"
+	"github.com/armory/armory-cli/internal/clierr/exitcodes"
+	errorUtils "github.com/armory/armory-cli/pkg/errors"
+	"github.com/armory/armory-cli/pkg/findings"
+	"github.com/samber/lo"
+)
+
+// violationsField is the field of a policy that lists custom messages, computed from the deployment, for rules that
+// are easier to write as a condition than as a constraint.
+const violationsField = "violations"
+
+// Policy is an organization rule for deployments, written in CUE. Its constraints are unified with the deployment
+// request, e.g.
+//
+//	targets: [string]: account: "prod-east" | "prod-west" | "staging"
+//
+// and it can list messages in a violations field, e.g.
+//
+//	targets: _
+//	violations: [for name, t in targets if name =~ "^prod" && t.constraints == _|_ {"target \(name) must depend on staging"}]
+type Policy struct {
+	Name  string
+	file  string
+	value cue.Value
+}
+
+// Load reads the CUE policies of a directory, in file name order. Subdirectories are not read.
+func Load(dir string) ([]Policy, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, errorUtils.NewWrappedError(ErrReadingPolicies, err)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	ctx := cuecontext.New()
+	var policies []Policy
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		file := filepath.Join(dir, entry.Name())
+		switch filepath.Ext(entry.Name()) {
+		case ".rego":
+			return nil, errorUtils.NewErrorWithDynamicContext(ErrRegoNotSupported, ": "+file)
+		case ".cue":
+			contents, err := os.ReadFile(file)
+			if err != nil {
+				return nil, errorUtils.NewWrappedError(ErrReadingPolicies, err)
+			}
+			value := ctx.CompileBytes(contents, cue.Filename(file))
+			if value.Err() != nil {
+				return nil, errorUtils.NewWrappedError(ErrInvalidPolicy, value.Err())
+			}
+			policies = append(policies, Policy{Name: entry.Name(), file: file, value: value})
+		}
+	}
+	if len(policies) == 0 {
+		return nil, errorUtils.NewErrorWithDynamicContext(ErrNoPolicies, ": "+dir)
+	}
+	return policies, nil
+}
+
+// Evaluate checks a deployment request, as sent to start a pipeline, against the policies. Every finding is an error.
+func Evaluate(policies []Policy, request map[string]any) ([]findings.Finding, error) {
+	found := []findings.Finding{}
+	for _, policy := range policies {
+		unified := policy.value.Unify(policy.value.Context().Encode(request))
+		if err := unified.Validate(cue.Concrete(true)); err != nil {
+			found = append(found, policy.constraintFindings(err)...)
+			continue
+		}
+
+		violations := unified.LookupPath(cue.ParsePath(violationsField))
+		if !violations.Exists() {
+			continue
+		}
+		var messages []string
+		if err := violations.Decode(&messages); err != nil {
+			return nil, errorUtils.NewWrappedError(ErrInvalidViolations, fmt.Errorf("%s: %w", policy.Name, err))
+		}
+		for _, message := range messages {
+			found = append(found, findings.Finding{Message: message, Severity: findings.SeverityError, Rule: policy.Name})
+		}
+	}
+	return found, nil
+}
+
+// constraintFindings converts the errors of unifying a policy with a request, which CUE may report more than once.
+func (p Policy) constraintFindings(err error) []findings.Finding {
+	return lo.Uniq(lo.FilterMap(cueerrors.Errors(err), func(e cueerrors.Error, _ int) (findings.Finding, bool) {
+		format, args := e.Msg()
+		message := fmt.Sprintf(format, args...)
+		if strings.HasSuffix(message, "in empty disjunction:") {
+			// the conflicts with each alternative are reported on their own
+			return findings.Finding{}, false
+		}
+		if position, found := lo.Find(e.InputPositions(), func(pos token.Pos) bool { return pos.Filename() == p.file }); found {
+			message = fmt.Sprintf("%s (%s, line %d)", message, p.Name, position.Line())
+		}
+		return findings.Finding{
+			Path:     strings.Join(lo.Map(e.Path(), func(element string, _ int) string { return unquote(element) }), "."),
+			Message:  message,
+			Severity: findings.SeverityError,
+			Rule:     p.Name,
+		}, true
+	}))
+}
+
+func unquote(element string) string {
+	if unquoted, err := strconv.Unquote(element); err == nil {
+		return unquoted
+	}
+	return element
+}
+
+// NewViolationError returns the error of a deployment that violates policies, which exits with a dedicated code so
+// that pipelines can tell it apart from other failures.
+func NewViolationError(found []findings.Finding) error {
+	return clierr.NewError(fmt.Sprintf("The deployment violates %d policy rule(s)", len(found)), "", ErrPolicyViolation, exitcodes.PolicyViolation)
+}
@@ -0,0 +1,75 @@
+package policy
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/armory/armory-cli/internal/clierr"
+	"github.com/armory/armory-cli/internal/clierr/exitcodes"
+	"github.com/armory/armory-cli/pkg/findings"
+	"github.com/stretchr/testify/assert"
+)
+
+const accountsPolicy = `targets: [string]: account: "prod-east" | "staging"
+`
+
+const constraintsPolicy = `targets: _
+violations: [for name, t in targets if name =~ "^prod" && t.constraints == _|_ {"target \(name) must depend on staging"}]
+`
+
+func writePolicies(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, contents := range files {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(contents), 0o644))
+	}
+	return dir
+}
+
+func TestEvaluate(t *testing.T) {
+	dir := writePolicies(t, map[string]string{"accounts.cue": accountsPolicy, "constraints.cue": constraintsPolicy})
+	policies, err := Load(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"accounts.cue", "constraints.cue"}, []string{policies[0].Name, policies[1].Name})
+
+	found, err := Evaluate(policies, map[string]any{
+		"targets": map[string]any{
+			"staging": map[string]any{"account": "staging"},
+			"prod":    map[string]any{"account": "staging", "constraints": map[string]any{"dependsOn": []any{"staging"}}},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, found)
+
+	found, err = Evaluate(policies, map[string]any{
+		"targets": map[string]any{
+			"prod": map[string]any{"account": "dev"},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, found, 3)
+	assert.Equal(t, "targets.prod.account", found[0].Path)
+	assert.Contains(t, found[0].Message, "(accounts.cue, line 1)")
+	assert.Equal(t, "accounts.cue", found[0].Rule)
+	assert.Equal(t, findings.Finding{Message: "target prod must depend on staging", Severity: findings.SeverityError, Rule: "constraints.cue"}, found[2])
+}
+
+func TestLoadErrors(t *testing.T) {
+	_, err := Load(writePolicies(t, map[string]string{"README.md": "policies"}))
+	assert.ErrorIs(t, err, ErrNoPolicies)
+
+	_, err = Load(writePolicies(t, map[string]string{"deploy.rego": "package deploy"}))
+	assert.ErrorIs(t, err, ErrRegoNotSupported)
+
+	_, err = Load(writePolicies(t, map[string]string{"broken.cue": "targets: {"}))
+	assert.ErrorIs(t, err, ErrInvalidPolicy)
+}
+
+func TestNewViolationError(t *testing.T) {
+	err := NewViolationError([]findings.Finding{{Message: "not allowed"}})
+	assert.ErrorIs(t, err, ErrPolicyViolation)
+	var cliErr *clierr.Error
+	assert.True(t, errors.As(err, &cliErr))
+	assert.Equal(t, int(exitcodes.PolicyViolation), cliErr.ExitCode())
+}
@@ -0,0 +1,396 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"cuelang.org/go/cue/parser"
+	"github.com/samber/lo"
+	"gopkg.in/yaml.v3"
+)
+
+const jsonSchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// JSONSchema is the subset of JSON Schema (draft 7) needed to describe deployment files, which is what the YAML
+// language server used by editors understands.
+type JSONSchema struct {
+	Schema      string `json:"$schema,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	Type        string `json:"type,omitempty"`
+	Enum        []any  `json:"enum,omitempty"`
+	Default     any    `json:"default,omitempty"`
+	Pattern     string `json:"pattern,omitempty"`
+	Minimum     *int   `json:"minimum,omitempty"`
+	// Properties are listed in the order of the CUE definition, which editors use for completion.
+	Properties           *Properties   `json:"properties,omitempty"`
+	Required             []string      `json:"required,omitempty"`
+	AdditionalProperties any           `json:"additionalProperties,omitempty"`
+	MinProperties        *int          `json:"minProperties,omitempty"`
+	Items                *JSONSchema   `json:"items,omitempty"`
+	MinItems             *int          `json:"minItems,omitempty"`
+	AnyOf                []*JSONSchema `json:"anyOf,omitempty"`
+}
+
+// Properties are the properties of an object schema, which keep their order when marshalled.
+type Properties struct {
+	names   []string
+	schemas map[string]*JSONSchema
+}
+
+func (p *Properties) Get(name string) (*JSONSchema, bool) {
+	if p == nil {
+		return nil, false
+	}
+	schema, ok := p.schemas[name]
+	return schema, ok
+}
+
+func (p *Properties) Names() []string {
+	if p == nil {
+		return nil
+	}
+	return p.names
+}
+
+func (p *Properties) set(name string, schema *JSONSchema) {
+	if _, ok := p.schemas[name]; !ok {
+		p.names = append(p.names, name)
+	}
+	p.schemas[name] = schema
+}
+
+func (p *Properties) MarshalJSON() ([]byte, error) {
+	var b strings.Builder
+	b.WriteString("{")
+	for i, name := range p.names {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		b.WriteString(strconv.Quote(name) + ":")
+		value, err := json.Marshal(p.schemas[name])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(value)
+	}
+	b.WriteString("}")
+	return []byte(b.String()), nil
+}
+
+func newProperties() *Properties {
+	return &Properties{schemas: map[string]*JSONSchema{}}
+}
+
+// ToJSONSchema converts a definition of a CUE schema, such as #PipelineRequest, to JSON Schema. The schema is
+// generated by CUE's OpenAPI encoder, then references to other definitions are inlined so that every field can be
+// described by its own path. CUE constraints without a JSON Schema equivalent, such as references between fields, are
+// left out, so a file matching the JSON Schema may still fail validation.
+func ToJSONSchema(file []byte, definition string) (*JSONSchema, error) {
+	f, err := parser.ParseFile(definition, file)
+	if err != nil {
+		return nil, err
+	}
+	components, err := generateComponents(f)
+	if err != nil {
+		return nil, err
+	}
+	name := strings.TrimPrefix(definition, "#")
+	if _, ok := components[name]; !ok {
+		return nil, fmt.Errorf("definition %s not found", definition)
+	}
+
+	r := &resolver{components: components, resolving: map[string]bool{}}
+	schema, err := r.reference(name)
+	if err != nil {
+		return nil, err
+	}
+	requirePresentFields(schema)
+	schema.Schema = jsonSchemaDraft
+	schema.Title = name
+	return schema, nil
+}
+
+const componentsPrefix = "#/components/schemas/"
+
+// resolver converts generated OpenAPI components to JSON Schema that the YAML language server understands: references
+// are inlined, allOf is merged into the schema, oneOf becomes anyOf, and structs, which are closed in definitions, don't
+// allow additional properties.
+type resolver struct {
+	components map[string]*openAPISchema
+	// resolving guards against definitions that refer to themselves
+	resolving map[string]bool
+}
+
+func (r *resolver) reference(name string) (*JSONSchema, error) {
+	component, ok := r.components[name]
+	if !ok {
+		return nil, fmt.Errorf("definition #%s not found", name)
+	}
+	if r.resolving[name] {
+		return nil, fmt.Errorf("definition #%s refers to itself", name)
+	}
+	r.resolving[name] = true
+	defer delete(r.resolving, name)
+	return r.convert(component)
+}
+
+func (r *resolver) convert(s *openAPISchema) (*JSONSchema, error) {
+	if s.Ref != "" {
+		return r.reference(strings.TrimPrefix(s.Ref, componentsPrefix))
+	}
+	schema := &JSONSchema{
+		Description:   s.Description,
+		Type:          s.Type,
+		Enum:          s.Enum,
+		Default:       s.Default,
+		Pattern:       toECMAScriptPattern(s.Pattern),
+		Minimum:       s.Minimum,
+		Required:      s.Required,
+		MinProperties: s.MinProperties,
+		MinItems:      s.MinItems,
+	}
+	if s.Properties != nil {
+		schema.Properties = newProperties()
+		for _, name := range s.Properties.names {
+			property, err := r.convert(s.Properties.schemas[name])
+			if err != nil {
+				return nil, err
+			}
+			schema.Properties.set(name, property)
+		}
+	}
+	if s.AdditionalProperties != nil {
+		pattern, err := r.convert(s.AdditionalProperties)
+		if err != nil {
+			return nil, err
+		}
+		schema.AdditionalProperties = pattern
+	} else if (schema.Type == "object" || schema.Properties != nil) && !s.open {
+		schema.AdditionalProperties = false
+	}
+	if s.Items != nil {
+		items, err := r.convert(s.Items)
+		if err != nil {
+			return nil, err
+		}
+		schema.Items = items
+	}
+
+	// the encoder moves the type of the alternatives to the disjunction
+	for _, alternative := range append(append([]*openAPISchema(nil), s.OneOf...), s.AnyOf...) {
+		if alternative.Type == "" && alternative.Ref == "" {
+			alternative.Type = s.Type
+		}
+		converted, err := r.convert(alternative)
+		if err != nil {
+			return nil, err
+		}
+		schema.AnyOf = append(schema.AnyOf, converted)
+	}
+	if len(schema.AnyOf) > 0 {
+		schema = &JSONSchema{Description: schema.Description, Default: schema.Default, AnyOf: schema.AnyOf}
+	}
+
+	// the encoder moves the fields of a struct in a conjunction such as #Constraints & {...} to the schema, which
+	// comes after the conjunction in CUE
+	for i := len(s.AllOf) - 1; i >= 0; i-- {
+		converted, err := r.convert(s.AllOf[i])
+		if err != nil {
+			return nil, err
+		}
+		schema = merge(converted, schema)
+	}
+	return schema, nil
+}
+
+// requirePresentFields keeps the regular fields that must be in the data. CUE fills in a regular field that has a
+// default or whose value is complete without data, such as a struct of optional fields, so they are not required.
+func requirePresentFields(schema *JSONSchema) {
+	if schema == nil {
+		return
+	}
+	for _, name := range schema.Properties.Names() {
+		property, _ := schema.Properties.Get(name)
+		requirePresentFields(property)
+	}
+	if pattern, ok := schema.AdditionalProperties.(*JSONSchema); ok {
+		requirePresentFields(pattern)
+	}
+	requirePresentFields(schema.Items)
+	for _, alternative := range schema.AnyOf {
+		requirePresentFields(alternative)
+	}
+
+	schema.Required = lo.Filter(schema.Required, func(name string, _ int) bool {
+		property, _ := schema.Properties.Get(name)
+		return needsData(property)
+	})
+	if len(schema.Required) == 0 {
+		schema.Required = nil
+	}
+}
+
+func needsData(schema *JSONSchema) bool {
+	switch {
+	case schema.Default != nil:
+		return false
+	case len(schema.AnyOf) > 0:
+		return lo.EveryBy(schema.AnyOf, needsData)
+	case schema.Type == "object":
+		return len(schema.Required) > 0 || schema.MinProperties != nil
+	case schema.Type == "array":
+		return schema.MinItems != nil
+	}
+	return true
+}
+
+// merge combines the constraints of a & b. An alternative of either side is merged with the other side, since JSON
+// Schema can't combine closed objects with allOf.
+func merge(a, b *JSONSchema) *JSONSchema {
+	if len(a.AnyOf) > 0 || len(b.AnyOf) > 0 {
+		alternatives, other := a, b
+		if len(a.AnyOf) == 0 {
+			alternatives, other = b, a
+		}
+		merged := &JSONSchema{Default: lo.Ternary(alternatives.Default != nil, alternatives.Default, other.Default)}
+		for _, alternative := range alternatives.AnyOf {
+			merged.AnyOf = append(merged.AnyOf, merge(alternative, withoutDefault(other)))
+		}
+		return merged
+	}
+
+	merged := *a
+	merged.Type = lo.Ternary(a.Type != "", a.Type, b.Type)
+	merged.Description = lo.Ternary(a.Description != "", a.Description, b.Description)
+	merged.Pattern = lo.Ternary(a.Pattern != "", a.Pattern, b.Pattern)
+	merged.Default = lo.Ternary(a.Default != nil, a.Default, b.Default)
+	merged.Minimum = lo.Ternary(a.Minimum != nil, a.Minimum, b.Minimum)
+	merged.MinItems = lo.Ternary(a.MinItems != nil, a.MinItems, b.MinItems)
+	merged.MinProperties = lo.Ternary(a.MinProperties != nil, a.MinProperties, b.MinProperties)
+	if len(b.Enum) > 0 {
+		merged.Enum = lo.Ternary(len(a.Enum) > 0, lo.Intersect(a.Enum, b.Enum), b.Enum)
+	}
+	if a.Items != nil && b.Items != nil {
+		merged.Items = merge(a.Items, b.Items)
+	} else if b.Items != nil {
+		merged.Items = b.Items
+	}
+
+	if a.Properties != nil || b.Properties != nil {
+		merged.Properties = newProperties()
+		for _, p := range []*Properties{a.Properties, b.Properties} {
+			for _, name := range p.Names() {
+				property, _ := p.Get(name)
+				if previous, ok := merged.Properties.Get(name); ok {
+					property = merge(previous, property)
+				}
+				merged.Properties.set(name, property)
+			}
+		}
+	}
+	merged.Required = lo.Union(a.Required, b.Required)
+	if len(merged.Required) == 0 {
+		merged.Required = nil
+	}
+
+	// a struct is closed if either side is, unless other fields are constrained by a pattern
+	aPattern, aIsSchema := a.AdditionalProperties.(*JSONSchema)
+	bPattern, bIsSchema := b.AdditionalProperties.(*JSONSchema)
+	switch {
+	case aIsSchema && bIsSchema:
+		merged.AdditionalProperties = merge(aPattern, bPattern)
+	case aIsSchema || bIsSchema:
+		merged.AdditionalProperties = lo.Ternary(aIsSchema, aPattern, bPattern)
+	case a.AdditionalProperties == false || b.AdditionalProperties == false:
+		merged.AdditionalProperties = false
+	}
+	return &merged
+}
+
+func withoutDefault(schema *JSONSchema) *JSONSchema {
+	copied := *schema
+	copied.Default = nil
+	return &copied
+}
+
+// toECMAScriptPattern rewrites the case-insensitive flag of a Go regular expression, which JSON Schema patterns don't
+// support, as character classes, e.g. (?i)^none becomes ^[nN][oO][nN][eE].
+func toECMAScriptPattern(pattern string) string {
+	if !strings.HasPrefix(pattern, "(?i)") {
+		return pattern
+	}
+	var b strings.Builder
+	for _, r := range strings.TrimPrefix(pattern, "(?i)") {
+		if unicode.IsLetter(r) && unicode.ToLower(r) != unicode.ToUpper(r) {
+			b.WriteString("[" + string(unicode.ToLower(r)) + string(unicode.ToUpper(r)) + "]")
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Describe sets the description of the properties of a schema from the comments of an example document, such as the
+// ones generated by armory template. Existing descriptions are kept.
+func Describe(schema *JSONSchema, node *yaml.Node) {
+	if schema == nil || node == nil {
+		return
+	}
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, content := range node.Content {
+			Describe(schema, content)
+		}
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			for _, items := range alternativesOf(schema, func(s *JSONSchema) *JSONSchema { return s.Items }) {
+				Describe(items, item)
+			}
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			for _, property := range alternativesOf(schema, func(s *JSONSchema) *JSONSchema { return propertyOf(s, key.Value) }) {
+				if comment := commentOf(key, value); comment != "" && property.Description == "" {
+					property.Description = comment
+				}
+				Describe(property, value)
+			}
+		}
+	}
+}
+
+// alternativesOf returns the subschemas selected from a schema or, for a disjunction, from each alternative.
+func alternativesOf(schema *JSONSchema, selector func(*JSONSchema) *JSONSchema) []*JSONSchema {
+	schemas := []*JSONSchema{schema}
+	if len(schema.AnyOf) > 0 {
+		schemas = schema.AnyOf
+	}
+	return lo.Filter(lo.Map(schemas, func(s *JSONSchema, _ int) *JSONSchema { return selector(s) }), func(s *JSONSchema, _ int) bool {
+		return s != nil
+	})
+}
+
+func propertyOf(schema *JSONSchema, name string) *JSONSchema {
+	if property, ok := schema.Properties.Get(name); ok {
+		return property
+	}
+	if pattern, ok := schema.AdditionalProperties.(*JSONSchema); ok {
+		return pattern
+	}
+	return nil
+}
+
+func commentOf(key, value *yaml.Node) string {
+	comments := lo.Filter([]string{key.HeadComment, key.LineComment, value.LineComment}, func(c string, _ int) bool { return c != "" })
+	if len(comments) == 0 {
+		return ""
+	}
+	lines := strings.Split(comments[0], "\n")
+	lines = lo.Map(lines, func(line string, _ int) string { return strings.TrimSpace(strings.TrimPrefix(line, "#")) })
+	return strings.TrimSpace(strings.Join(lo.Filter(lines, func(line string, _ int) bool { return line != "" }), " "))
+}
@@ -0,0 +1,174 @@
+package schema
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+	"testing"
+)
+
+const testSchema = `package test
+
+#Request: {
+  version: string | *"v1"
+  name: string
+  targets: [string]: #Target
+  targets: [string]: { strategy: string }
+  steps?: [... #Step]
+}
+
+#Target: {
+  account: string
+  strategy?: string
+  mode?: "direct" | "agent"
+  if mode == "agent" {
+    agentIdentifier?: string
+  }
+}
+
+#Step: {pause: {duration: >=1, unit: =~ "(?i)^seconds|minutes$"}} | {wait: bool}
+`
+
+func TestToJSONSchema(t *testing.T) {
+	schema, err := ToJSONSchema([]byte(testSchema), "#Request")
+	assert.NoError(t, err)
+
+	var doc yaml.Node
+	assert.NoError(t, yaml.Unmarshal([]byte(`
+# Optional name of the request.
+name: request
+targets:
+  <target>:
+    # Name of the account.
+    account: prod
+steps:
+  - wait: true # Wait for the previous step.
+`), &doc))
+	Describe(schema, &doc)
+
+	exported, err := json.Marshal(schema)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "title": "Request",
+  "type": "object",
+  "properties": {
+    "version": {"type": "string", "default": "v1"},
+    "name": {"description": "Optional name of the request.", "type": "string"},
+    "targets": {
+      "type": "object",
+      "additionalProperties": {
+        "type": "object",
+        "properties": {
+          "account": {"description": "Name of the account.", "type": "string"},
+          "strategy": {"type": "string"},
+          "mode": {"type": "string", "enum": ["direct", "agent"]},
+          "agentIdentifier": {"type": "string"}
+        },
+        "required": ["account", "strategy"],
+        "additionalProperties": false
+      }
+    },
+    "steps": {
+      "type": "array",
+      "items": {
+        "anyOf": [
+          {
+            "type": "object",
+            "properties": {
+              "pause": {
+                "type": "object",
+                "properties": {
+                  "duration": {"type": "number", "minimum": 1},
+                  "unit": {"type": "string", "pattern": "^[sS][eE][cC][oO][nN][dD][sS]|[mM][iI][nN][uU][tT][eE][sS]$"}
+                },
+                "required": ["duration", "unit"],
+                "additionalProperties": false
+              }
+            },
+            "required": ["pause"],
+            "additionalProperties": false
+          },
+          {
+            "type": "object",
+            "properties": {"wait": {"description": "Wait for the previous step.", "type": "boolean"}},
+            "required": ["wait"],
+            "additionalProperties": false
+          }
+        ]
+      }
+    }
+  },
+  "required": ["name"],
+  "additionalProperties": false
+}`, string(exported))
+}
+
+func TestToJSONSchemaUnknownDefinition(t *testing.T) {
+	_, err := ToJSONSchema([]byte(testSchema), "#Missing")
+	assert.Error(t, err)
+}
+
+func TestToJSONSchemaMinimums(t *testing.T) {
+	schema, err := ToJSONSchema([]byte(`package test
+
+import (
+  "list"
+  "struct"
+)
+
+#Request: {
+  targets: [string]: #Target
+  targets: struct.MinFields(1)
+  labels?: {...}
+}
+
+#Target: {
+  pause: {untilApproved: bool, roles?: [... string], roles?: list.MinItems(1)} | {duration: int}
+}
+`), "#Request")
+	assert.NoError(t, err)
+
+	exported, err := json.Marshal(schema)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "title": "Request",
+  "type": "object",
+  "properties": {
+    "targets": {
+      "type": "object",
+      "additionalProperties": {
+        "type": "object",
+        "properties": {
+          "pause": {
+            "anyOf": [
+              {
+                "type": "object",
+                "properties": {
+                  "untilApproved": {"type": "boolean"},
+                  "roles": {"type": "array", "items": {"type": "string"}, "minItems": 1}
+                },
+                "required": ["untilApproved"],
+                "additionalProperties": false
+              },
+              {
+                "type": "object",
+                "properties": {"duration": {"type": "integer"}},
+                "required": ["duration"],
+                "additionalProperties": false
+              }
+            ]
+          }
+        },
+        "required": ["pause"],
+        "additionalProperties": false
+      },
+      "minProperties": 1
+    },
+    "labels": {"type": "object"}
+  },
+  "required": ["targets"],
+  "additionalProperties": false
+}`, string(exported))
+}
@@ -0,0 +1,355 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/cue/token"
+	"cuelang.org/go/encoding/openapi"
+)
+
+// openAPISchema is a schema of the components generated by CUE's OpenAPI encoder, before references to other
+// components are inlined.
+type openAPISchema struct {
+	Ref                  string             `json:"$ref"`
+	Description          string             `json:"description"`
+	Type                 string             `json:"type"`
+	Enum                 []any              `json:"enum"`
+	Default              any                `json:"default"`
+	Pattern              string             `json:"pattern"`
+	Minimum              *int               `json:"minimum"`
+	Properties           *openAPIProperties `json:"properties"`
+	Required             []string           `json:"required"`
+	AdditionalProperties *openAPISchema     `json:"additionalProperties"`
+	MinProperties        *int               `json:"minProperties"`
+	Items                *openAPISchema     `json:"items"`
+	MinItems             *int               `json:"minItems"`
+	AllOf                []*openAPISchema   `json:"allOf"`
+	OneOf                []*openAPISchema   `json:"oneOf"`
+	AnyOf                []*openAPISchema   `json:"anyOf"`
+	// open is set for structs that end with ..., which the encoder doesn't mark
+	open bool
+}
+
+// openAPIProperties are the properties of an object schema in the order they were generated.
+type openAPIProperties struct {
+	names   []string
+	schemas map[string]*openAPISchema
+}
+
+func (p *openAPIProperties) UnmarshalJSON(data []byte) error {
+	p.schemas = map[string]*openAPISchema{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if _, err := decoder.Token(); err != nil {
+		return err
+	}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		name, ok := token.(string)
+		if !ok {
+			return fmt.Errorf("unexpected property name %v", token)
+		}
+		var schema openAPISchema
+		if err := decoder.Decode(&schema); err != nil {
+			return err
+		}
+		p.names = append(p.names, name)
+		p.schemas[name] = &schema
+	}
+	return nil
+}
+
+// lookup returns the subschemas at a path of property names, where * selects additional properties and [] the items
+// of an array. Alternatives and conjunctions are searched too, since a constraint may be added to any of them.
+func (s *openAPISchema) lookup(path []string) []*openAPISchema {
+	if s == nil {
+		return nil
+	}
+	if len(path) == 0 {
+		return []*openAPISchema{s}
+	}
+	var next *openAPISchema
+	switch path[0] {
+	case patternSelector:
+		next = s.AdditionalProperties
+	case itemsSelector:
+		next = s.Items
+	default:
+		if s.Properties != nil {
+			next = s.Properties.schemas[path[0]]
+		}
+	}
+	found := next.lookup(path[1:])
+	for _, sub := range append(append(append([]*openAPISchema(nil), s.AllOf...), s.OneOf...), s.AnyOf...) {
+		found = append(found, sub.lookup(path)...)
+	}
+	return found
+}
+
+const (
+	patternSelector = "*"
+	itemsSelector   = "[]"
+)
+
+// constraint is a constraint of a definition that the OpenAPI encoder can't generate, such as list.MinItems, which it
+// evaluates against the empty value of its field and fails. Constraints are taken out of the schema before it is
+// generated and added to the generated components afterwards.
+type constraint struct {
+	definition string
+	path       []string
+	apply      func(schema *openAPISchema)
+}
+
+// generateComponents generates the OpenAPI components of the definitions of a CUE schema. The schema is simplified
+// first to what the encoder supports; see simplifier.
+func generateComponents(f *ast.File) (map[string]*openAPISchema, error) {
+	s := &simplifier{}
+	s.file(f)
+
+	var runtime cue.Runtime
+	instance, err := runtime.CompileFile(f)
+	if err != nil {
+		return nil, err
+	}
+	generated, err := openapi.Generate(instance, &openapi.Config{})
+	if err != nil {
+		return nil, err
+	}
+	components, err := cuecontext.New().BuildFile(generated).LookupPath(cue.ParsePath("components.schemas")).MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	schemas := map[string]*openAPISchema{}
+	if err := json.Unmarshal(components, &schemas); err != nil {
+		return nil, err
+	}
+
+	for _, c := range s.constraints {
+		for _, schema := range schemas[c.definition].lookup(c.path) {
+			c.apply(schema)
+		}
+	}
+	return schemas, nil
+}
+
+// simplifier rewrites the parts of a CUE schema that the OpenAPI encoder can't convert:
+//   - fields added by comprehensions become optional fields, since their conditions are only known when validating
+//   - comparisons, != and calls such as or(_targetNames) depend on the data, so they are replaced by _
+//   - list.MinItems and struct.MinFields are recorded as constraints and replaced by _
+//   - hidden fields and definitions nested in structs aren't part of the data and are removed
+//   - fields with the same label are combined, since the encoder keeps only one of them
+//
+// Structs that end with ... are recorded as constraints too.
+type simplifier struct {
+	constraints []constraint
+}
+
+func (s *simplifier) file(f *ast.File) {
+	decls := f.Decls[:0]
+	for _, decl := range f.Decls {
+		switch d := decl.(type) {
+		case *ast.ImportDecl:
+			// the calls of imported packages are removed
+			continue
+		case *ast.Field:
+			if name, _, err := ast.LabelName(d.Label); err == nil && strings.HasPrefix(name, "#") {
+				d.Value = s.expr(strings.TrimPrefix(name, "#"), nil, d.Value)
+			}
+		}
+		decls = append(decls, decl)
+	}
+	f.Decls = decls
+	f.Imports = nil
+}
+
+func (s *simplifier) expr(definition string, path []string, expr ast.Expr) ast.Expr {
+	switch x := expr.(type) {
+	case *ast.ParenExpr:
+		x.X = s.expr(definition, path, x.X)
+	case *ast.Alias:
+		return s.expr(definition, path, x.Expr)
+	case *ast.StructLit:
+		s.structure(definition, path, x)
+	case *ast.ListLit:
+		for i, elt := range x.Elts {
+			if ellipsis, ok := elt.(*ast.Ellipsis); ok {
+				if ellipsis.Type != nil {
+					ellipsis.Type = s.expr(definition, childPath(path, itemsSelector), ellipsis.Type)
+				}
+				continue
+			}
+			x.Elts[i] = s.expr(definition, childPath(path, itemsSelector), elt)
+		}
+	case *ast.BinaryExpr:
+		if x.Op != token.AND && x.Op != token.OR {
+			return ast.NewIdent("_")
+		}
+		if x.Op == token.AND {
+			if combined, ok := combineStructs(x); ok {
+				return s.expr(definition, path, combined)
+			}
+		}
+		x.X = s.expr(definition, path, x.X)
+		x.Y = s.expr(definition, path, x.Y)
+	case *ast.UnaryExpr:
+		if x.Op == token.NEQ {
+			return ast.NewIdent("_")
+		}
+	case *ast.CallExpr:
+		s.call(definition, path, x)
+		return ast.NewIdent("_")
+	}
+	return expr
+}
+
+func (s *simplifier) call(definition string, path []string, x *ast.CallExpr) {
+	selector, ok := x.Fun.(*ast.SelectorExpr)
+	if !ok || len(x.Args) != 1 {
+		return
+	}
+	lit, ok := x.Args[0].(*ast.BasicLit)
+	if !ok {
+		return
+	}
+	value, err := strconv.Atoi(lit.Value)
+	if err != nil {
+		return
+	}
+	name, _, _ := ast.LabelName(selector.Sel)
+	switch name {
+	case "MinItems":
+		s.constrain(definition, path, func(schema *openAPISchema) { schema.MinItems = &value })
+	case "MinFields":
+		s.constrain(definition, path, func(schema *openAPISchema) { schema.MinProperties = &value })
+	}
+}
+
+func (s *simplifier) constrain(definition string, path []string, apply func(schema *openAPISchema)) {
+	s.constraints = append(s.constraints, constraint{definition: definition, path: path, apply: apply})
+}
+
+func (s *simplifier) structure(definition string, path []string, x *ast.StructLit) {
+	x.Elts = unifyFields(x.Elts)
+	for _, decl := range x.Elts {
+		switch d := decl.(type) {
+		case *ast.Field:
+			if _, ok := d.Label.(*ast.ListLit); ok {
+				d.Value = s.expr(definition, childPath(path, patternSelector), d.Value)
+				continue
+			}
+			name, _, _ := ast.LabelName(d.Label)
+			d.Value = s.expr(definition, childPath(path, name), d.Value)
+		case *ast.EmbedDecl:
+			d.Expr = s.expr(definition, path, d.Expr)
+		case *ast.Ellipsis:
+			s.constrain(definition, path, func(schema *openAPISchema) { schema.open = true })
+		}
+	}
+}
+
+// unifyFields flattens the fields added by comprehensions and combines the fields with the same label into one, since
+// the encoder only keeps one of them when they are patterns such as [string]: #DeploymentTarget.
+func unifyFields(decls []ast.Decl) []ast.Decl {
+	var elts []ast.Decl
+	fields := map[string]*ast.Field{}
+	for _, decl := range decls {
+		switch d := decl.(type) {
+		case *ast.Comprehension:
+			if body, ok := d.Value.(*ast.StructLit); ok {
+				for _, elt := range body.Elts {
+					if field, ok := elt.(*ast.Field); ok {
+						field.Optional = token.Blank.Pos()
+					}
+				}
+				elts = append(elts, unifyFields(body.Elts)...)
+			}
+			continue
+		}
+		field, ok := decl.(*ast.Field)
+		if !ok {
+			elts = append(elts, decl)
+			continue
+		}
+		key, ok := labelKey(field.Label)
+		if !ok {
+			continue
+		}
+		if previous, ok := fields[key]; ok {
+			previous.Value = &ast.BinaryExpr{Op: token.AND, X: previous.Value, Y: field.Value}
+			if !field.Optional.IsValid() {
+				previous.Optional = token.NoPos
+			}
+			continue
+		}
+		fields[key] = field
+		elts = append(elts, field)
+	}
+	return elts
+}
+
+// labelKey returns the key of the label of a field in its struct, or false for hidden fields and definitions, which
+// aren't part of the data.
+func labelKey(label ast.Label) (string, bool) {
+	if pattern, ok := label.(*ast.ListLit); ok {
+		formatted, err := format.Node(pattern)
+		return string(formatted), err == nil
+	}
+	name, isIdent, err := ast.LabelName(label)
+	if err != nil || (isIdent && (strings.HasPrefix(name, "_") || strings.HasPrefix(name, "#"))) {
+		return "", false
+	}
+	return name, true
+}
+
+func childPath(path []string, name string) []string {
+	return append(append([]string(nil), path...), name)
+}
+
+// combineStructs combines the struct literals of a conjunction into one, so that their fields are unified by
+// unifyFields. The other conjuncts, such as references to definitions, are kept.
+func combineStructs(x *ast.BinaryExpr) (ast.Expr, bool) {
+	var combined *ast.StructLit
+	var others []ast.Expr
+	structs := 0
+	for _, conjunct := range conjuncts(x) {
+		if lit, ok := conjunct.(*ast.StructLit); ok {
+			structs++
+			if combined == nil {
+				combined = &ast.StructLit{}
+				others = append(others, combined)
+			}
+			combined.Elts = append(combined.Elts, lit.Elts...)
+			continue
+		}
+		others = append(others, conjunct)
+	}
+	if structs < 2 {
+		return nil, false
+	}
+	return ast.NewBinExpr(token.AND, others...), true
+}
+
+func conjuncts(expr ast.Expr) []ast.Expr {
+	expr = unparen(expr)
+	if binary, ok := expr.(*ast.BinaryExpr); ok && binary.Op == token.AND {
+		return append(conjuncts(binary.X), conjuncts(binary.Y)...)
+	}
+	return []ast.Expr{expr}
+}
+
+func unparen(expr ast.Expr) ast.Expr {
+	if paren, ok := expr.(*ast.ParenExpr); ok {
+		return unparen(paren.X)
+	}
+	return expr
+}
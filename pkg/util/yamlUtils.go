@@ -79,3 +79,23 @@ func BuildSequenceNodeWithTailComment(key, comment string) (*yaml.Node, *yaml.No
 			LineComment: comment,
 		}
 }
+
+// MappingValue returns the value of a key of a mapping node, or an empty node when the key is missing.
+func MappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node != nil && node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				return node.Content[i+1]
+			}
+		}
+	}
+	return &yaml.Node{}
+}
+
+// SequenceItems returns the items of a sequence node, or nothing when the node is not a sequence.
+func SequenceItems(node *yaml.Node) []*yaml.Node {
+	if node.Kind != yaml.SequenceNode {
+		return nil
+	}
+	return node.Content
+}